@@ -41,7 +41,7 @@ func IsAppEnvTesting() bool {
 
 // isAppEnvTestingValue reports test mode while allowing the loader to evaluate a planned APP_ENV.
 func isAppEnvTestingValue(appEnv string) bool {
-	return appEnv == Testing ||
+	return canonicalAppEnv(appEnv) == Testing ||
 		flag.Lookup("test.v") != nil ||
 		isTestSuffixFromArguments()
 }
@@ -73,6 +73,9 @@ func GetAppEnv() string {
 // @group Application environment
 // @behavior readonly
 //
+// Aliases declared with RegisterAppEnv match their environment, so "prod" matches Production once
+// registered.
+//
 // Example: match any allowed environment
 //
 //	_ = os.Setenv("APP_ENV", "staging")
@@ -85,9 +88,9 @@ func GetAppEnv() string {
 //	env.Dump(env.IsAppEnv(env.Production, env.Staging))
 //	// #bool false
 func IsAppEnv(envs ...string) bool {
-	current := os.Getenv("APP_ENV")
+	current := canonicalAppEnv(os.Getenv("APP_ENV"))
 	for _, env := range envs {
-		if current == env {
+		if current == canonicalAppEnv(env) {
 			return true
 		}
 	}
@@ -159,11 +162,13 @@ func IsAppEnvTestingOrLocal() bool {
 	return IsAppEnv(Testing, Local)
 }
 
-// SetAppEnv sets APP_ENV to a supported value.
+// SetAppEnv sets APP_ENV to a built-in or registered environment.
 // @group Application environment
 // @behavior mutates-process-env
 //
-// Returns an error when the environment is unsupported.
+// Returns an error when the environment is unsupported. An alias registered with RegisterAppEnv
+// stores its canonical name. The write goes through Batch, so it synchronizes with the loader and
+// notifies OnChange subscribers.
 //
 // Example: set a supported environment
 //
@@ -171,10 +176,14 @@ func IsAppEnvTestingOrLocal() bool {
 //	env.Dump(env.GetAppEnv())
 //	// #string "staging"
 func SetAppEnv(appEnv string) error {
-	if !isValidAppEnv(appEnv) {
+	definition, ok := appEnvironments.lookup(appEnv)
+	if !ok {
 		return fmt.Errorf("invalid APP_ENV: %s", appEnv)
 	}
-	return os.Setenv("APP_ENV", appEnv)
+	return Batch(func(tx *Tx) error {
+		tx.Set("APP_ENV", definition.name)
+		return nil
+	})
 }
 
 // SetAppEnvLocal sets APP_ENV to "local".
@@ -228,13 +237,3 @@ func SetAppEnvProduction() error {
 func SetAppEnvTesting() error {
 	return SetAppEnv(Testing)
 }
-
-// isValidAppEnv reports whether appEnv is one of the supported application environments.
-func isValidAppEnv(appEnv string) bool {
-	switch appEnv {
-	case Testing, Local, Staging, Production:
-		return true
-	default:
-		return false
-	}
-}
@@ -0,0 +1,234 @@
+package env
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// AppEnvOptions declares how RegisterAppEnv layers and recognizes an application environment.
+type AppEnvOptions struct {
+	// File is the env file layered after .env when this environment is selected. Empty means
+	// ".env.<name>" for new environments and keeps the current file for existing ones.
+	File string
+	// Aliases are alternative APP_ENV values, such as "prod", that select this environment.
+	Aliases []string
+	// Traits are labels, such as "protected", reported by HasAppEnvTrait.
+	Traits []string
+}
+
+// appEnvDefinition is one registered application environment.
+type appEnvDefinition struct {
+	name   string
+	file   string
+	traits map[string]struct{}
+}
+
+// appEnvRegistry maps APP_ENV names and aliases to their definitions.
+type appEnvRegistry struct {
+	mu          sync.RWMutex
+	definitions map[string]appEnvDefinition
+	aliases     map[string]string
+}
+
+// appEnvironments starts with the four built-in environments; testing has no APP_ENV layer because
+// .env.testing is applied separately whenever the process is a test.
+var appEnvironments = &appEnvRegistry{
+	definitions: map[string]appEnvDefinition{
+		Testing:    {name: Testing},
+		Local:      {name: Local, file: envFileLocal},
+		Staging:    {name: Staging, file: envFileStaging},
+		Production: {name: Production, file: envFileProd},
+	},
+	aliases: map[string]string{},
+}
+
+// RegisterAppEnv declares an application environment beyond testing, local, staging, and production.
+// @group Application environment
+// @behavior mutates-loader
+//
+// A registered name is accepted by SetAppEnv, and Load layers its file after .env when APP_ENV
+// selects it. Aliases select the same environment everywhere: SetAppEnv stores the canonical
+// name, and IsAppEnv and the IsAppEnv helpers match either spelling. Registering an existing name,
+// including a built-in, adds aliases and traits and replaces the file when one is given. Names and
+// aliases use lowercase letters, digits, '-', and '_'. A name or file may not reuse a file that
+// another layer reads, such as .env.docker, .env.linux, or another environment's file, nor the
+// reserved defaults, override, testing, and key, and an alias that already names or aliases a
+// different environment is rejected. Register environments before calling Load.
+//
+// Example: declare a QA environment
+//
+//	_ = env.RegisterAppEnv("qa", env.AppEnvOptions{Aliases: []string{"quality"}, Traits: []string{"protected"}})
+//	_ = env.SetAppEnv("quality")
+//	env.Dump(env.GetAppEnv(), env.IsAppEnv("qa"), env.HasAppEnvTrait("protected"))
+//	// #string "qa"
+//	// #bool true
+//	// #bool true
+//
+// Example: alias a built-in environment
+//
+//	_ = env.RegisterAppEnv(env.Production, env.AppEnvOptions{Aliases: []string{"prod"}})
+//	_ = os.Setenv("APP_ENV", "prod")
+//	env.Dump(env.IsAppEnvProduction())
+//	// #bool true
+func RegisterAppEnv(name string, options AppEnvOptions) error {
+	if !isValidAppEnvName(name) {
+		return fmt.Errorf("register APP_ENV %q: invalid name", name)
+	}
+	for _, alias := range options.Aliases {
+		if !isValidAppEnvName(alias) {
+			return fmt.Errorf("register APP_ENV %s: invalid alias %q", name, alias)
+		}
+	}
+	if isReservedLayerName(name) {
+		return fmt.Errorf("register APP_ENV %s: name is reserved", name)
+	}
+	if options.File != "" && (filepath.Base(options.File) != options.File || options.File == fileEnv) {
+		return fmt.Errorf("register APP_ENV %s: file %q must be a filename other than %s", name, options.File, fileEnv)
+	}
+	if suffix, ok := strings.CutPrefix(options.File, fileEnv+"."); ok && (isReservedLayerName(suffix) || suffix == Testing) {
+		return fmt.Errorf("register APP_ENV %s: file %s is reserved", name, options.File)
+	}
+	for _, trait := range options.Traits {
+		if trait == "" {
+			return fmt.Errorf("register APP_ENV %s: empty trait", name)
+		}
+	}
+	// A runtime layer owns both .env.<name> and the [name] section, so the name may not match
+	// one even when File points elsewhere.
+	if layer, ok := runtimeLayerReading(fileEnv + "." + name); ok {
+		return fmt.Errorf("register APP_ENV %s: name is used by the %s runtime layer", name, layer)
+	}
+	if layer, ok := runtimeLayerReading(options.File); ok {
+		return fmt.Errorf("register APP_ENV %s: file %s is read by the %s runtime layer", name, options.File, layer)
+	}
+	return appEnvironments.register(name, options)
+}
+
+// isReservedLayerName reports whether .env.<name> is a file the loader reads for its own purpose:
+// the defaults and override layers, or the encryption key.
+func isReservedLayerName(name string) bool {
+	switch name {
+	case layerDefaults, layerOverride, strings.TrimPrefix(envFileKey, fileEnv+"."):
+		return true
+	}
+	return false
+}
+
+// register validates every conflict before changing the registry so a rejected call has no effect.
+func (r *appEnvRegistry) register(name string, options AppEnvOptions) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if canonical, aliased := r.aliases[name]; aliased {
+		return fmt.Errorf("register APP_ENV %s: already an alias of %s", name, canonical)
+	}
+	for _, alias := range options.Aliases {
+		if _, defined := r.definitions[alias]; defined || alias == name {
+			return fmt.Errorf("register APP_ENV %s: alias %s is already an environment name", name, alias)
+		}
+		if canonical, aliased := r.aliases[alias]; aliased && canonical != name {
+			return fmt.Errorf("register APP_ENV %s: alias %s already selects %s", name, alias, canonical)
+		}
+	}
+
+	definition, exists := r.definitions[name]
+	if !exists {
+		definition = appEnvDefinition{name: name, file: fileEnv + "." + name}
+	}
+	if options.File != "" {
+		definition.file = options.File
+	}
+	if other, ok := r.reading(definition.file); ok && other != name {
+		return fmt.Errorf("register APP_ENV %s: file %s is already read by APP_ENV %s", name, definition.file, other)
+	}
+	traits := make(map[string]struct{}, len(definition.traits)+len(options.Traits))
+	for trait := range definition.traits {
+		traits[trait] = struct{}{}
+	}
+	for _, trait := range options.Traits {
+		traits[trait] = struct{}{}
+	}
+	definition.traits = traits
+
+	r.definitions[name] = definition
+	for _, alias := range options.Aliases {
+		r.aliases[alias] = name
+	}
+	return nil
+}
+
+// reading returns the environment whose layer reads file; the caller must hold r.mu.
+func (r *appEnvRegistry) reading(file string) (string, bool) {
+	for name, definition := range r.definitions {
+		if file != "" && definition.file == file {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// readingFile is reading for callers that do not hold the lock.
+func (r *appEnvRegistry) readingFile(file string) (string, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.reading(file)
+}
+
+// lookup returns the definition selected by a name or alias.
+func (r *appEnvRegistry) lookup(appEnv string) (appEnvDefinition, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if canonical, aliased := r.aliases[appEnv]; aliased {
+		appEnv = canonical
+	}
+	definition, ok := r.definitions[appEnv]
+	return definition, ok
+}
+
+// canonicalAppEnv resolves an alias to its environment name and returns other values unchanged.
+func canonicalAppEnv(appEnv string) string {
+	if definition, ok := appEnvironments.lookup(appEnv); ok {
+		return definition.name
+	}
+	return appEnv
+}
+
+// HasAppEnvTrait reports whether the current APP_ENV was registered with trait.
+// @group Application environment
+// @behavior readonly
+//
+// Example: guard destructive commands
+//
+//	_ = env.RegisterAppEnv("sandbox", env.AppEnvOptions{Traits: []string{"disposable"}})
+//	_ = os.Setenv("APP_ENV", "sandbox")
+//	env.Dump(env.HasAppEnvTrait("disposable"), env.HasAppEnvTrait("protected"))
+//	// #bool true
+//	// #bool false
+func HasAppEnvTrait(trait string) bool {
+	definition, ok := appEnvironments.lookup(os.Getenv("APP_ENV"))
+	if !ok {
+		return false
+	}
+	_, has := definition.traits[trait]
+	return has
+}
+
+// isValidAppEnvName keeps names and aliases safe to embed in env filenames.
+func isValidAppEnvName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for index := 0; index < len(name); index++ {
+		c := name[index]
+		switch {
+		case 'a' <= c && c <= 'z', '0' <= c && c <= '9':
+		case (c == '-' || c == '_') && index > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
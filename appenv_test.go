@@ -0,0 +1,153 @@
+package env
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+// restoreAppEnvRegistry isolates registrations made by one test.
+func restoreAppEnvRegistry(t *testing.T) {
+	t.Helper()
+	appEnvironments.mu.Lock()
+	definitions := make(map[string]appEnvDefinition, len(appEnvironments.definitions))
+	for name, definition := range appEnvironments.definitions {
+		definitions[name] = definition
+	}
+	aliases := make(map[string]string, len(appEnvironments.aliases))
+	for alias, name := range appEnvironments.aliases {
+		aliases[alias] = name
+	}
+	appEnvironments.mu.Unlock()
+
+	t.Cleanup(func() {
+		appEnvironments.mu.Lock()
+		defer appEnvironments.mu.Unlock()
+		appEnvironments.definitions = definitions
+		appEnvironments.aliases = aliases
+	})
+}
+
+// TestRegisterAppEnvIsAcceptedEverywhere ensures custom names and aliases work with setters, predicates, and traits.
+func TestRegisterAppEnvIsAcceptedEverywhere(t *testing.T) {
+	restoreAppEnvRegistry(t)
+	t.Cleanup(func() { _ = os.Unsetenv("APP_ENV") })
+
+	if err := SetAppEnv("qa"); err == nil {
+		t.Fatal("expected unregistered environment to be rejected")
+	}
+	if err := RegisterAppEnv("qa", AppEnvOptions{Aliases: []string{"quality"}, Traits: []string{"protected"}}); err != nil {
+		t.Fatalf("RegisterAppEnv: %v", err)
+	}
+	if err := SetAppEnv("quality"); err != nil {
+		t.Fatalf("SetAppEnv: %v", err)
+	}
+	if got := GetAppEnv(); got != "qa" {
+		t.Fatalf("expected alias to store the canonical name, got %q", got)
+	}
+	if !IsAppEnv("quality") || !IsAppEnv("qa") || !HasAppEnvTrait("protected") || HasAppEnvTrait("disposable") {
+		t.Fatal("expected registered name, alias, and trait to match")
+	}
+	if file, ok := envFileForAppEnv("quality"); !ok || file != ".env.qa" {
+		t.Fatalf("expected default layer .env.qa, got %q %v", file, ok)
+	}
+}
+
+// TestRegisterAppEnvExtendsBuiltIns ensures built-ins can gain aliases and traits without losing their layer.
+func TestRegisterAppEnvExtendsBuiltIns(t *testing.T) {
+	restoreAppEnvRegistry(t)
+	t.Cleanup(func() { _ = os.Unsetenv("APP_ENV") })
+
+	if err := RegisterAppEnv(Production, AppEnvOptions{Aliases: []string{"prod"}, Traits: []string{"protected"}}); err != nil {
+		t.Fatalf("RegisterAppEnv: %v", err)
+	}
+	_ = os.Setenv("APP_ENV", "prod")
+	if !IsAppEnvProduction() || !HasAppEnvTrait("protected") {
+		t.Fatal("expected prod alias to select production")
+	}
+	if file, ok := envFileForAppEnv("prod"); !ok || file != envFileProd {
+		t.Fatalf("expected production layer, got %q %v", file, ok)
+	}
+
+	if err := RegisterAppEnv(Testing, AppEnvOptions{Aliases: []string{"test"}}); err != nil {
+		t.Fatalf("RegisterAppEnv: %v", err)
+	}
+	if !isAppEnvTestingValue("test") {
+		t.Fatal("expected testing alias to select test mode")
+	}
+	if _, ok := envFileForAppEnv("test"); ok {
+		t.Fatal("expected testing to keep no APP_ENV layer")
+	}
+}
+
+// TestRegisterAppEnvRejectsConflicts ensures invalid or ambiguous registrations leave the registry unchanged.
+func TestRegisterAppEnvRejectsConflicts(t *testing.T) {
+	restoreAppEnvRegistry(t)
+	if err := RegisterAppEnv("preview", AppEnvOptions{Aliases: []string{"pr"}}); err != nil {
+		t.Fatalf("RegisterAppEnv: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		appEnv  string
+		options AppEnvOptions
+		want    string
+	}{
+		{name: "empty name", appEnv: "", want: "invalid name"},
+		{name: "uppercase name", appEnv: "QA", want: "invalid name"},
+		{name: "path name", appEnv: "../qa", want: "invalid name"},
+		{name: "alias as name", appEnv: "pr", want: "already an alias of preview"},
+		{name: "alias of other environment", appEnv: "sandbox", options: AppEnvOptions{Aliases: []string{"pr"}}, want: "already selects preview"},
+		{name: "alias shadows name", appEnv: "sandbox", options: AppEnvOptions{Aliases: []string{Local}}, want: "already an environment name"},
+		{name: "invalid alias", appEnv: "sandbox", options: AppEnvOptions{Aliases: []string{"Sand Box"}}, want: "invalid alias"},
+		{name: "file with directory", appEnv: "sandbox", options: AppEnvOptions{File: "config/.env.sandbox"}, want: "must be a filename"},
+		{name: "defaults layer", appEnv: "defaults", want: "name is reserved"},
+		{name: "override layer", appEnv: "override", want: "name is reserved"},
+		{name: "key file", appEnv: "key", want: "name is reserved"},
+		{name: "reserved file", appEnv: "sandbox", options: AppEnvOptions{File: ".env.key"}, want: "file .env.key is reserved"},
+		{name: "testing file", appEnv: "sandbox", options: AppEnvOptions{File: ".env.testing"}, want: "file .env.testing is reserved"},
+		{name: "operating system name", appEnv: "linux", want: "name is used by the os runtime layer"},
+		{name: "runtime layer name", appEnv: "docker", want: "name is used by the docker runtime layer"},
+		{name: "runtime layer file", appEnv: "sandbox", options: AppEnvOptions{File: ".env.docker"}, want: "file .env.docker is read by the docker runtime layer"},
+		{name: "other environment file", appEnv: "sandbox", options: AppEnvOptions{File: ".env.preview"}, want: "file .env.preview is already read by APP_ENV preview"},
+		{name: "empty trait", appEnv: "sandbox", options: AppEnvOptions{Traits: []string{""}}, want: "empty trait"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := RegisterAppEnv(test.appEnv, test.options)
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Fatalf("expected error containing %q, got %v", test.want, err)
+			}
+		})
+	}
+	if _, ok := appEnvironments.lookup("sandbox"); ok {
+		t.Fatal("expected rejected registrations to leave no trace")
+	}
+	if err := RegisterAppEnv("os", AppEnvOptions{}); err != nil {
+		t.Fatalf("expected a name no layer reads to be accepted, got %v", err)
+	}
+	if err := RegisterAppEnv(Production, AppEnvOptions{File: envFileProd}); err != nil {
+		t.Fatalf("expected an environment to keep its own file, got %v", err)
+	}
+}
+
+// TestLoadLayersRegisteredAppEnv ensures the loader selects a custom environment's file, including through an alias.
+func TestLoadLayersRegisteredAppEnv(t *testing.T) {
+	restoreAppEnvRegistry(t)
+	prepareLoaderTest(t, "ENV_QPASS_LAYER")
+	_ = os.Unsetenv("ENV_QPASS_LAYER")
+	if err := RegisterAppEnv("qa", AppEnvOptions{File: ".env.quality", Aliases: []string{"quality"}}); err != nil {
+		t.Fatalf("RegisterAppEnv: %v", err)
+	}
+	directory := t.TempDir()
+	writeEnvFile(t, directory, fileEnv, "APP_ENV=quality\nENV_QPASS_LAYER=base\n")
+	writeEnvFile(t, directory, ".env.quality", "ENV_QPASS_LAYER=qa\n")
+	changeWorkingDirectory(t, directory)
+
+	if err := Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if got := os.Getenv("ENV_QPASS_LAYER"); got != "qa" {
+		t.Fatalf("expected registered layer to apply, got %q", got)
+	}
+}
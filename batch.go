@@ -0,0 +1,190 @@
+package env
+
+import (
+	"fmt"
+)
+
+// Tx stages process variable updates for Batch.
+//
+// A Tx is only valid inside the Batch callback that received it.
+type Tx struct {
+	staged map[string]environmentSnapshot
+}
+
+// Set stages key=value.
+// @group Process environment
+// @behavior readonly
+func (tx *Tx) Set(key, value string) {
+	tx.staged[key] = environmentSnapshot{value: value, present: true}
+}
+
+// Unset stages the removal of key.
+// @group Process environment
+// @behavior readonly
+func (tx *Tx) Unset(key string) {
+	tx.staged[key] = environmentSnapshot{}
+}
+
+// Lookup returns the staged value for key, or the current process value when key is not staged.
+// @group Process environment
+// @behavior readonly
+func (tx *Tx) Lookup(key string) (string, bool) {
+	if snapshot, ok := tx.staged[key]; ok {
+		return snapshot.value, snapshot.present
+	}
+	return envLookup(key)
+}
+
+// Batch applies every update staged by fn as one transaction.
+// @group Process environment
+// @behavior mutates-process-env
+//
+// Nothing is written while fn runs. When fn returns nil, the staged updates are applied under the
+// loader lock; if any write fails, every earlier write is rolled back and the error is returned.
+// When fn returns an error, nothing is applied and that error is returned unchanged. Keys written
+// by Batch belong to the process, so Reload leaves them alone, and changed keys reach OnChange
+// subscribers. Batch panics when fn is nil.
+//
+// Example: switch several settings together
+//
+//	err := env.Batch(func(tx *env.Tx) error {
+//		tx.Set("DB_HOST", "db-2")
+//		tx.Set("DB_PORT", "5433")
+//		tx.Unset("DB_REPLICA")
+//		return nil
+//	})
+//	env.Dump(err == nil, os.Getenv("DB_HOST"), os.Getenv("DB_PORT"))
+//	// #bool true
+//	// #string "db-2"
+//	// #string "5433"
+func Batch(fn func(tx *Tx) error) error {
+	if fn == nil {
+		panic("env: nil batch function")
+	}
+	tx := &Tx{staged: map[string]environmentSnapshot{}}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return updateProcessEnvironment(func() (ChangeSet, error) {
+		changes, err := writeEnvironmentTargets(tx.staged)
+		if err != nil {
+			return nil, fmt.Errorf("apply env batch: %w", err)
+		}
+		return changes, nil
+	})
+}
+
+// With applies overrides for the duration of fn and then restores the previous values.
+// @group Process environment
+// @behavior mutates-process-env
+//
+// Overrides are applied and restored transactionally, including the difference between unset and
+// explicitly empty. A key that was file-owned before With is file-owned again afterwards, so the
+// next Reload refreshes it even if a reload ran while the override was in place. The previous
+// values are restored even when fn panics. With returns an apply error without calling fn, or the
+// restore error after fn returns. With panics when fn is nil.
+//
+// Example: run code with temporary overrides
+//
+//	_ = os.Setenv("CACHE_DRIVER", "redis")
+//	_ = env.With(map[string]string{"CACHE_DRIVER": "memory"}, func() {
+//		env.Dump(os.Getenv("CACHE_DRIVER"))
+//	})
+//	env.Dump(os.Getenv("CACHE_DRIVER"))
+//	// #string "memory"
+//	// #string "redis"
+func With(overrides map[string]string, fn func()) (err error) {
+	if fn == nil {
+		panic("env: nil override function")
+	}
+
+	var previous map[string]environmentSnapshot
+	var owners environmentOwners
+	applyErr := updateProcessEnvironment(func() (ChangeSet, error) {
+		previous = make(map[string]environmentSnapshot, len(overrides))
+		targets := make(map[string]environmentSnapshot, len(overrides))
+		for key, value := range overrides {
+			current, present := envLookup(key)
+			previous[key] = environmentSnapshot{value: current, present: present}
+			targets[key] = environmentSnapshot{value: value, present: true}
+		}
+		owners = currentEnvironmentOwners(previous)
+
+		changes, err := writeEnvironmentTargets(targets)
+		if err != nil {
+			return nil, fmt.Errorf("apply env overrides: %w", err)
+		}
+		return changes, nil
+	})
+	if applyErr != nil {
+		return applyErr
+	}
+
+	defer func() {
+		restoreErr := updateProcessEnvironment(func() (ChangeSet, error) {
+			changes, err := writeEnvironmentTargets(previous)
+			if err != nil {
+				return nil, fmt.Errorf("restore env overrides: %w", err)
+			}
+			owners.reinstate(previous)
+			return changes, nil
+		})
+		if err == nil {
+			err = restoreErr
+		}
+	}()
+	fn()
+	return nil
+}
+
+// environmentOwners records which loader records owned keys before a temporary override.
+type environmentOwners struct {
+	values   map[string]loadedEnvironmentValue
+	defaults map[string]loadedEnvironmentValue
+}
+
+// currentEnvironmentOwners captures the unchanged loader records for snapshots; callers hold the loader lock.
+func currentEnvironmentOwners(snapshots map[string]environmentSnapshot) environmentOwners {
+	owners := environmentOwners{
+		values:   map[string]loadedEnvironmentValue{},
+		defaults: map[string]loadedEnvironmentValue{},
+	}
+	if !processEnvironmentLoader.loaded {
+		return owners
+	}
+	for key, snapshot := range snapshots {
+		if loaded, ok := processEnvironmentLoader.values[key]; ok && snapshotsEqual(loaded.applied, snapshot) {
+			owners.values[key] = loaded
+		}
+		if loaded, ok := processEnvironmentLoader.defaults[key]; ok && snapshotsEqual(loaded.applied, snapshot) {
+			owners.defaults[key] = loaded
+		}
+	}
+	return owners
+}
+
+// reinstate gives restored keys back to the loader when a reload dropped them during the override.
+//
+// Callers hold the loader lock. A record is only reinstated when the loader is still loaded, the key
+// has no newer record, and the restored value matches what the loader applied.
+func (o environmentOwners) reinstate(restored map[string]environmentSnapshot) {
+	if !processEnvironmentLoader.loaded {
+		return
+	}
+	values := cloneLoadedEnvironmentValues(processEnvironmentLoader.values)
+	for key, loaded := range o.values {
+		if _, owned := values[key]; owned || !snapshotsEqual(loaded.applied, restored[key]) {
+			continue
+		}
+		values[key] = loaded
+	}
+	defaults := cloneLoadedEnvironmentValues(processEnvironmentLoader.defaults)
+	for key, loaded := range o.defaults {
+		if _, owned := defaults[key]; owned || !snapshotsEqual(loaded.applied, restored[key]) {
+			continue
+		}
+		defaults[key] = loaded
+	}
+	processEnvironmentLoader.values = values
+	processEnvironmentLoader.defaults = defaults
+}
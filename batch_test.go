@@ -0,0 +1,221 @@
+package env
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+)
+
+// TestBatchAppliesStagedUpdates ensures staged sets and unsets land together and notify subscribers.
+func TestBatchAppliesStagedUpdates(t *testing.T) {
+	prepareLoaderTest(t, "ENV_QPASS_HOST", "ENV_QPASS_PORT", "ENV_QPASS_REPLICA")
+	_ = os.Setenv("ENV_QPASS_HOST", "db-1")
+	_ = os.Unsetenv("ENV_QPASS_PORT")
+	_ = os.Setenv("ENV_QPASS_REPLICA", "db-r")
+
+	var received []string
+	cancel := OnChange("ENV_QPASS_*", func(change Change) {
+		received = append(received, change.Key+":"+string(change.Kind))
+	})
+	defer cancel()
+
+	err := Batch(func(tx *Tx) error {
+		tx.Set("ENV_QPASS_HOST", "db-2")
+		tx.Set("ENV_QPASS_PORT", "")
+		tx.Unset("ENV_QPASS_REPLICA")
+		if value, present := tx.Lookup("ENV_QPASS_HOST"); !present || value != "db-2" {
+			t.Fatalf("expected staged lookup, got %q present=%v", value, present)
+		}
+		if os.Getenv("ENV_QPASS_HOST") != "db-1" {
+			t.Fatal("expected nothing written before fn returns")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Batch: %v", err)
+	}
+
+	if os.Getenv("ENV_QPASS_HOST") != "db-2" {
+		t.Fatalf("expected host update, got %q", os.Getenv("ENV_QPASS_HOST"))
+	}
+	if value, present := os.LookupEnv("ENV_QPASS_PORT"); !present || value != "" {
+		t.Fatalf("expected explicitly empty port, got %q present=%v", value, present)
+	}
+	if _, present := os.LookupEnv("ENV_QPASS_REPLICA"); present {
+		t.Fatal("expected replica to be unset")
+	}
+	want := []string{"ENV_QPASS_HOST:modified", "ENV_QPASS_PORT:added", "ENV_QPASS_REPLICA:removed"}
+	if !reflect.DeepEqual(received, want) {
+		t.Fatalf("expected changes %v, got %v", want, received)
+	}
+}
+
+// TestBatchCallbackErrorAppliesNothing ensures a failing callback discards its staged updates.
+func TestBatchCallbackErrorAppliesNothing(t *testing.T) {
+	prepareLoaderTest(t, "ENV_QPASS_HOST")
+	_ = os.Setenv("ENV_QPASS_HOST", "db-1")
+	callbackErr := errors.New("callback failure")
+
+	err := Batch(func(tx *Tx) error {
+		tx.Set("ENV_QPASS_HOST", "db-2")
+		return callbackErr
+	})
+	if err != callbackErr {
+		t.Fatalf("expected callback error unchanged, got %v", err)
+	}
+	if os.Getenv("ENV_QPASS_HOST") != "db-1" {
+		t.Fatal("expected staged update to be discarded")
+	}
+}
+
+// TestBatchRollsBackFailedWrites ensures a failed write restores every earlier write.
+func TestBatchRollsBackFailedWrites(t *testing.T) {
+	prepareLoaderTest(t, "ENV_QPASS_A", "ENV_QPASS_B")
+	_ = os.Setenv("ENV_QPASS_A", "old-a")
+	_ = os.Unsetenv("ENV_QPASS_B")
+	writeErr := errors.New("injected write failure")
+	envSet = func(key, value string) error {
+		if key == "ENV_QPASS_B" {
+			return writeErr
+		}
+		return os.Setenv(key, value)
+	}
+
+	err := Batch(func(tx *Tx) error {
+		tx.Set("ENV_QPASS_A", "new-a")
+		tx.Set("ENV_QPASS_B", "new-b")
+		return nil
+	})
+	if !errors.Is(err, writeErr) {
+		t.Fatalf("expected write error, got %v", err)
+	}
+	if os.Getenv("ENV_QPASS_A") != "old-a" {
+		t.Fatalf("expected rollback, got %q", os.Getenv("ENV_QPASS_A"))
+	}
+	if _, present := os.LookupEnv("ENV_QPASS_B"); present {
+		t.Fatal("expected failed key to stay unset")
+	}
+}
+
+// TestBatchTakesOwnershipFromFiles ensures batched writes survive a reload like direct process writes.
+func TestBatchTakesOwnershipFromFiles(t *testing.T) {
+	prepareLoaderTest(t, "ENV_QPASS_OWNED")
+	t.Setenv("APP_ENV", Local)
+	_ = os.Unsetenv("ENV_QPASS_OWNED")
+	directory := t.TempDir()
+	writeEnvFile(t, directory, fileEnv, "ENV_QPASS_OWNED=file\n")
+	changeWorkingDirectory(t, directory)
+	if err := Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	if err := Batch(func(tx *Tx) error {
+		tx.Set("ENV_QPASS_OWNED", "process")
+		return nil
+	}); err != nil {
+		t.Fatalf("Batch: %v", err)
+	}
+	writeEnvFile(t, directory, fileEnv, "ENV_QPASS_OWNED=file-2\n")
+	if err := Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	if got := os.Getenv("ENV_QPASS_OWNED"); got != "process" {
+		t.Fatalf("expected batched value to stay authoritative, got %q", got)
+	}
+}
+
+// TestWithRestoresPreviousValues ensures overrides are temporary, even when fn panics.
+func TestWithRestoresPreviousValues(t *testing.T) {
+	prepareLoaderTest(t, "ENV_QPASS_SET", "ENV_QPASS_UNSET")
+	_ = os.Setenv("ENV_QPASS_SET", "before")
+	_ = os.Unsetenv("ENV_QPASS_UNSET")
+	overrides := map[string]string{"ENV_QPASS_SET": "during", "ENV_QPASS_UNSET": "during"}
+
+	err := With(overrides, func() {
+		if os.Getenv("ENV_QPASS_SET") != "during" || os.Getenv("ENV_QPASS_UNSET") != "during" {
+			t.Fatal("expected overrides inside fn")
+		}
+	})
+	if err != nil {
+		t.Fatalf("With: %v", err)
+	}
+	if os.Getenv("ENV_QPASS_SET") != "before" {
+		t.Fatalf("expected restored value, got %q", os.Getenv("ENV_QPASS_SET"))
+	}
+	if _, present := os.LookupEnv("ENV_QPASS_UNSET"); present {
+		t.Fatal("expected unset key to be removed again")
+	}
+
+	expectPanic(t, "With", func() {
+		_ = With(overrides, func() { panic("boom") })
+	})
+	if os.Getenv("ENV_QPASS_SET") != "before" {
+		t.Fatal("expected restore after panic")
+	}
+}
+
+// TestWithApplyFailureSkipsCallback ensures fn never runs with a partially applied override set.
+func TestWithApplyFailureSkipsCallback(t *testing.T) {
+	prepareLoaderTest(t, "ENV_QPASS_A", "ENV_QPASS_B")
+	_ = os.Setenv("ENV_QPASS_A", "old-a")
+	_ = os.Setenv("ENV_QPASS_B", "old-b")
+	writeErr := errors.New("injected write failure")
+	envSet = func(key, value string) error {
+		if key == "ENV_QPASS_B" && value == "new-b" {
+			return writeErr
+		}
+		return os.Setenv(key, value)
+	}
+
+	called := false
+	err := With(map[string]string{"ENV_QPASS_A": "new-a", "ENV_QPASS_B": "new-b"}, func() { called = true })
+	if !errors.Is(err, writeErr) || called {
+		t.Fatalf("expected apply error without calling fn, got err=%v called=%v", err, called)
+	}
+	if os.Getenv("ENV_QPASS_A") != "old-a" {
+		t.Fatalf("expected rollback, got %q", os.Getenv("ENV_QPASS_A"))
+	}
+}
+
+// TestWithKeepsFileOwnershipAcrossReload ensures an overridden file key is refreshed by later reloads.
+func TestWithKeepsFileOwnershipAcrossReload(t *testing.T) {
+	prepareLoaderTest(t, "ENV_QPASS_FILE")
+	t.Setenv("APP_ENV", Local)
+	_ = os.Unsetenv("ENV_QPASS_FILE")
+	directory := t.TempDir()
+	writeEnvFile(t, directory, fileEnv, "ENV_QPASS_FILE=first\n")
+	changeWorkingDirectory(t, directory)
+	if err := Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	err := With(map[string]string{"ENV_QPASS_FILE": "override"}, func() {
+		writeEnvFile(t, directory, fileEnv, "ENV_QPASS_FILE=second\n")
+		if err := Reload(); err != nil {
+			t.Fatalf("Reload: %v", err)
+		}
+		if got := os.Getenv("ENV_QPASS_FILE"); got != "override" {
+			t.Fatalf("expected override to win during fn, got %q", got)
+		}
+	})
+	if err != nil {
+		t.Fatalf("With: %v", err)
+	}
+	if got := os.Getenv("ENV_QPASS_FILE"); got != "first" {
+		t.Fatalf("expected pre-override value restored, got %q", got)
+	}
+
+	if err := Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	if got := os.Getenv("ENV_QPASS_FILE"); got != "second" {
+		t.Fatalf("expected restored key to be file-owned again, got %q", got)
+	}
+	if err := Unload(); err != nil {
+		t.Fatalf("Unload: %v", err)
+	}
+	if _, present := os.LookupEnv("ENV_QPASS_FILE"); present {
+		t.Fatal("expected Unload to restore the original unset state")
+	}
+}
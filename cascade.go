@@ -0,0 +1,75 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// findCascadeRoot returns the nearest directory, within the bounded ancestor search, that holds a
+// root marker. An empty result means no marker was found and layers use nearest-file discovery.
+func findCascadeRoot(startDirectory string, markers []string) (string, error) {
+	directory := filepath.Clean(startDirectory)
+	for level := 0; level < MaxDirectorySeekLevels; level++ {
+		for _, marker := range markers {
+			candidate := filepath.Join(directory, marker)
+			_, err := envFileStat(candidate)
+			switch {
+			case err == nil:
+				return directory, nil
+			case errors.Is(err, os.ErrNotExist):
+			default:
+				return "", fmt.Errorf("stat env root marker %s: %w", candidate, err)
+			}
+		}
+
+		parent := filepath.Dir(directory)
+		if parent == directory {
+			break
+		}
+		directory = parent
+	}
+	return "", nil
+}
+
+// cascadeDirectories lists the directories from root down to startDirectory, outermost first.
+func cascadeDirectories(startDirectory, root string) []string {
+	var directories []string
+	directory := filepath.Clean(startDirectory)
+	for {
+		directories = append(directories, directory)
+		parent := filepath.Dir(directory)
+		if directory == root || parent == directory {
+			break
+		}
+		directory = parent
+	}
+	for left, right := 0, len(directories)-1; left < right; left, right = left+1, right-1 {
+		directories[left], directories[right] = directories[right], directories[left]
+	}
+	return directories
+}
+
+// loadCascadeEnvFiles parses every regular file named name between the cascade root and
+// startDirectory, outermost first, so nearer files override shared ones.
+func loadCascadeEnvFiles(plan *environmentLoadPlan, startDirectory, name string, sections bool) ([]environmentFile, error) {
+	var files []environmentFile
+	for _, directory := range cascadeDirectories(startDirectory, plan.cascadeRoot) {
+		candidate := filepath.Join(directory, name)
+		plan.watched = append(plan.watched, candidate)
+		found, err := statEnvFile(candidate)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			continue
+		}
+		file, err := parseEnvFile(candidate, sections)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
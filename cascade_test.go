@@ -0,0 +1,149 @@
+package env
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// makeCascadeTree creates a repository root marked by .git with a nested service directory.
+func makeCascadeTree(t *testing.T) (string, string) {
+	t.Helper()
+	root := t.TempDir()
+	service := filepath.Join(root, "services", "api")
+	if err := os.MkdirAll(service, 0o755); err != nil {
+		t.Fatalf("make service directory: %v", err)
+	}
+	if err := os.Mkdir(filepath.Join(root, envRootMarker), 0o755); err != nil {
+		t.Fatalf("make root marker: %v", err)
+	}
+	return root, service
+}
+
+// TestCascadeMergesEveryAncestorOutermostFirst ensures nearer files override shared ones for each layer.
+func TestCascadeMergesEveryAncestorOutermostFirst(t *testing.T) {
+	prepareLoaderTest(t, "ENV_QPASS_SHARED", "ENV_QPASS_MIDDLE", "ENV_QPASS_SERVICE", "ENV_QPASS_APP")
+	t.Setenv("APP_ENV", Local)
+	for _, key := range []string{"ENV_QPASS_SHARED", "ENV_QPASS_MIDDLE", "ENV_QPASS_SERVICE", "ENV_QPASS_APP"} {
+		_ = os.Unsetenv(key)
+	}
+	root, service := makeCascadeTree(t)
+	writeEnvFile(t, filepath.Dir(root), fileEnv, "ENV_QPASS_SHARED=outside-repository\n")
+	writeEnvFile(t, root, fileEnv, "ENV_QPASS_SHARED=root\nENV_QPASS_MIDDLE=root\nENV_QPASS_SERVICE=root\n")
+	writeEnvFile(t, filepath.Join(root, "services"), fileEnv, "ENV_QPASS_MIDDLE=services\nENV_QPASS_SERVICE=services\n")
+	writeEnvFile(t, service, fileEnv, "ENV_QPASS_SERVICE=api\n")
+	writeEnvFile(t, root, envFileLocal, "ENV_QPASS_APP=root-local\n")
+	changeWorkingDirectoryWithin(t, service, filepath.Dir(root))
+	if err := SetLayerOptions(LayerOptions{Cascade: true}); err != nil {
+		t.Fatalf("SetLayerOptions: %v", err)
+	}
+
+	if err := Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	want := map[string]string{
+		"ENV_QPASS_SHARED":  "root",
+		"ENV_QPASS_MIDDLE":  "services",
+		"ENV_QPASS_SERVICE": "api",
+		"ENV_QPASS_APP":     "root-local",
+	}
+	for key, value := range want {
+		if got := os.Getenv(key); got != value {
+			t.Fatalf("%s = %q, want %q", key, got, value)
+		}
+	}
+	processEnvironmentLoader.mu.Lock()
+	watched := append([]string(nil), processEnvironmentLoader.watched...)
+	processEnvironmentLoader.mu.Unlock()
+	if !slices.Contains(watched, filepath.Join(root, fileEnv)) || slices.Contains(watched, filepath.Join(filepath.Dir(root), fileEnv)) {
+		t.Fatalf("expected the cascade to watch up to the repository root only, got %v", watched)
+	}
+}
+
+// TestCascadeWithoutRootMarkerUsesNearestFile ensures an unmarked tree keeps nearest-file discovery.
+func TestCascadeWithoutRootMarkerUsesNearestFile(t *testing.T) {
+	prepareLoaderTest(t, "ENV_QPASS_SHARED", "ENV_QPASS_SERVICE")
+	t.Setenv("APP_ENV", Local)
+	_ = os.Unsetenv("ENV_QPASS_SHARED")
+	_ = os.Unsetenv("ENV_QPASS_SERVICE")
+	root := t.TempDir()
+	service := filepath.Join(root, "api")
+	if err := os.Mkdir(service, 0o755); err != nil {
+		t.Fatalf("make service directory: %v", err)
+	}
+	writeEnvFile(t, root, fileEnv, "ENV_QPASS_SHARED=root\n")
+	writeEnvFile(t, service, fileEnv, "ENV_QPASS_SERVICE=api\n")
+	changeWorkingDirectoryWithin(t, service, root)
+	if err := SetLayerOptions(LayerOptions{Cascade: true, RootMarkers: []string{"go.work"}}); err != nil {
+		t.Fatalf("SetLayerOptions: %v", err)
+	}
+
+	if err := Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if _, present := os.LookupEnv("ENV_QPASS_SHARED"); present || os.Getenv("ENV_QPASS_SERVICE") != "api" {
+		t.Fatal("expected nearest-file discovery without a root marker")
+	}
+}
+
+// TestCascadeRootMarkerStatError ensures an unreadable marker fails the load without mutation.
+func TestCascadeRootMarkerStatError(t *testing.T) {
+	prepareLoaderTest(t, "ENV_QPASS_UNCHANGED")
+	t.Setenv("APP_ENV", Local)
+	t.Setenv("ENV_QPASS_UNCHANGED", "ambient")
+	directory := t.TempDir()
+	changeWorkingDirectory(t, directory)
+	markerErr := errors.New("injected marker failure")
+	envFileStat = func(path string) (os.FileInfo, error) {
+		if filepath.Base(path) == envRootMarker {
+			return nil, markerErr
+		}
+		return nil, os.ErrNotExist
+	}
+	if err := SetLayerOptions(LayerOptions{Cascade: true}); err != nil {
+		t.Fatalf("SetLayerOptions: %v", err)
+	}
+
+	if err := Load(); !errors.Is(err, markerErr) {
+		t.Fatalf("expected marker error, got %v", err)
+	}
+	if os.Getenv("ENV_QPASS_UNCHANGED") != "ambient" || IsEnvLoaded() {
+		t.Fatal("expected failed marker lookup to leave environment and state unchanged")
+	}
+}
+
+// TestCascadeDebugOutputNamesKeyDirectories ensures ENV_DEBUG=4 reports key provenance without values.
+func TestCascadeDebugOutputNamesKeyDirectories(t *testing.T) {
+	prepareLoaderTest(t, "ENV_QPASS_SHARED", "ENV_QPASS_SECRET", "ENV_DEBUG")
+	t.Setenv("APP_ENV", Local)
+	_ = os.Unsetenv("ENV_QPASS_SHARED")
+	_ = os.Unsetenv("ENV_QPASS_SECRET")
+	_ = os.Unsetenv("ENV_DEBUG")
+	root, service := makeCascadeTree(t)
+	writeEnvFile(t, root, fileEnv, "ENV_DEBUG=4\nENV_QPASS_SHARED=shared-value\n")
+	writeEnvFile(t, service, fileEnv, "ENV_QPASS_SECRET=do-not-print-me\n")
+	changeWorkingDirectoryWithin(t, service, root)
+	if err := SetLayerOptions(LayerOptions{Cascade: true}); err != nil {
+		t.Fatalf("SetLayerOptions: %v", err)
+	}
+
+	output := captureStdout(t, func() {
+		if err := Load(); err != nil {
+			t.Fatalf("Load: %v", err)
+		}
+	})
+	if strings.Contains(output, "do-not-print-me") || strings.Contains(output, "shared-value") {
+		t.Fatalf("debug output exposed env values: %q", output)
+	}
+	for _, want := range []string{
+		"key [ENV_QPASS_SHARED] dir [" + root + "]",
+		"key [ENV_QPASS_SECRET] dir [" + service + "]",
+	} {
+		if !strings.Contains(output, want) {
+			t.Fatalf("expected %q in debug output, got %q", want, output)
+		}
+	}
+}
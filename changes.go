@@ -0,0 +1,321 @@
+package env
+
+import (
+	"fmt"
+	"path"
+	"sync"
+)
+
+// ChangeKind classifies how a loader operation changed one process variable.
+type ChangeKind string
+
+const (
+	// ChangeAdded reports a variable that was unset before the operation.
+	ChangeAdded ChangeKind = "added"
+	// ChangeModified reports a variable whose visible value changed.
+	ChangeModified ChangeKind = "modified"
+	// ChangeRemoved reports a variable that became unset.
+	ChangeRemoved ChangeKind = "removed"
+	// ChangeRestored reports a variable returned to its value from before the first successful Load.
+	ChangeRestored ChangeKind = "restored"
+)
+
+// redactedChangeValue replaces present values so change sets can be logged safely.
+const redactedChangeValue = "[redacted]"
+
+// Change describes one process variable changed by a successful loader operation.
+//
+// Previous and Current are redacted when the variable was present and empty when it was unset.
+// Use Unredacted to read the actual values.
+type Change struct {
+	Key      string
+	Kind     ChangeKind
+	Previous string
+	Current  string
+
+	previous environmentSnapshot
+	current  environmentSnapshot
+}
+
+// ChangeSet lists the changes made by one loader operation in key order.
+type ChangeSet []Change
+
+// newChange records both snapshots while exposing only redacted values by default.
+func newChange(key string, kind ChangeKind, previous, current environmentSnapshot) Change {
+	return Change{
+		Key:      key,
+		Kind:     kind,
+		Previous: redactedSnapshotValue(previous),
+		Current:  redactedSnapshotValue(current),
+		previous: previous,
+		current:  current,
+	}
+}
+
+// redactedSnapshotValue keeps presence visible without disclosing the value itself.
+func redactedSnapshotValue(snapshot environmentSnapshot) string {
+	if !snapshot.present {
+		return ""
+	}
+	return redactedChangeValue
+}
+
+// environmentChangeKind distinguishes a restored ambient value from an ordinary file-driven update.
+func environmentChangeKind(
+	key string,
+	before, after environmentSnapshot,
+	previous map[string]loadedEnvironmentValue,
+	plan environmentLoadPlan,
+) ChangeKind {
+	switch {
+	case !after.present:
+		return ChangeRemoved
+	case environmentPlanRestores(key, previous, plan):
+		return ChangeRestored
+	case !before.present:
+		return ChangeAdded
+	default:
+		return ChangeModified
+	}
+}
+
+// environmentPlanRestores reports whether a previously file-owned key falls back to its baseline.
+func environmentPlanRestores(key string, previous map[string]loadedEnvironmentValue, plan environmentLoadPlan) bool {
+	if _, owned := previous[key]; !owned {
+		return false
+	}
+	if _, ok := plan.fileValues[key]; ok {
+		return false
+	}
+	_, ok := plan.defaults[key]
+	return !ok
+}
+
+// Unredacted returns a copy of the change with actual values in Previous and Current.
+// @group Environment loading
+// @behavior readonly
+//
+// Example: inspect a changed value
+//
+//	tmp, _ := os.MkdirTemp("", "envdoc")
+//	defer os.RemoveAll(tmp)
+//	originalDirectory, _ := os.Getwd()
+//	defer os.Chdir(originalDirectory)
+//	_ = os.Chdir(tmp)
+//	_ = os.WriteFile(filepath.Join(tmp, ".env"), []byte("SERVICE=api"), 0o644)
+//	_ = env.Load()
+//	_ = os.WriteFile(filepath.Join(tmp, ".env"), []byte("SERVICE=worker"), 0o644)
+//	changes, _ := env.ReloadWithChanges()
+//	for _, change := range changes {
+//		if change.Key == "SERVICE" {
+//			env.Dump(change.Previous, change.Unredacted().Current)
+//		}
+//	}
+//	// #string "[redacted]"
+//	// #string "worker"
+func (c Change) Unredacted() Change {
+	c.Previous = c.previous.value
+	c.Current = c.current.value
+	return c
+}
+
+// Keys returns the changed keys of the given kinds, or every changed key when no kind is given.
+// @group Environment loading
+// @behavior readonly
+//
+// Example: keys added by a reload
+//
+//	changes := env.ChangeSet{
+//		{Key: "CACHE_TTL", Kind: env.ChangeAdded},
+//		{Key: "DB_HOST", Kind: env.ChangeModified},
+//	}
+//	env.Dump(changes.Keys(env.ChangeAdded))
+//	// #[]string [
+//	//  0 => "CACHE_TTL" #string
+//	// ]
+func (c ChangeSet) Keys(kinds ...ChangeKind) []string {
+	keys := []string{}
+	for _, change := range c {
+		if len(kinds) == 0 || containsChangeKind(kinds, change.Kind) {
+			keys = append(keys, change.Key)
+		}
+	}
+	return keys
+}
+
+// containsChangeKind reports whether kind is one of the requested kinds.
+func containsChangeKind(kinds []ChangeKind, kind ChangeKind) bool {
+	for _, candidate := range kinds {
+		if candidate == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// ReloadWithChanges behaves like Reload and returns the process variables it changed.
+// @group Environment loading
+// @behavior mutates-process-env
+//
+// The change set reports added, modified, removed, and restored keys in key order. Values are
+// redacted unless a change is explicitly converted with Unredacted. A failed reload returns no
+// changes and leaves the previous configuration active.
+//
+// Example: react to changed keys
+//
+//	tmp, _ := os.MkdirTemp("", "envdoc")
+//	defer os.RemoveAll(tmp)
+//	originalDirectory, _ := os.Getwd()
+//	defer os.Chdir(originalDirectory)
+//	_ = os.Chdir(tmp)
+//	_ = os.WriteFile(filepath.Join(tmp, ".env"), []byte("DB_HOST=db-1\nDB_POOL=5"), 0o644)
+//	_ = env.Load()
+//	_ = os.WriteFile(filepath.Join(tmp, ".env"), []byte("DB_HOST=db-2\nDB_POOL=5"), 0o644)
+//	changes, _ := env.ReloadWithChanges()
+//	env.Dump(changes.Keys(env.ChangeModified))
+//	// #[]string [
+//	//  0 => "DB_HOST" #string
+//	// ]
+func ReloadWithChanges() (ChangeSet, error) {
+	return load(true, nil)
+}
+
+// OnChange subscribes fn to changes of keys matching keyOrPattern and returns a cancel function.
+// @group Environment loading
+// @behavior mutates-loader
+//
+// keyOrPattern is an exact key or a path.Match pattern such as "DB_*". Subscribers run after a
+// successful transactional apply, outside the loader lock, once per matching change and in
+// registration order. Changes from concurrent or nested loader operations are delivered in the
+// order they were applied. OnChange panics when the pattern is malformed.
+//
+// Example: watch database settings
+//
+//	tmp, _ := os.MkdirTemp("", "envdoc")
+//	defer os.RemoveAll(tmp)
+//	originalDirectory, _ := os.Getwd()
+//	defer os.Chdir(originalDirectory)
+//	_ = os.Chdir(tmp)
+//	_ = os.WriteFile(filepath.Join(tmp, ".env"), []byte("DB_HOST=db-1"), 0o644)
+//	_ = env.Load()
+//
+//	cancel := env.OnChange("DB_*", func(change env.Change) {
+//		env.Dump(change.Key, string(change.Kind))
+//	})
+//	defer cancel()
+//
+//	_ = os.WriteFile(filepath.Join(tmp, ".env"), []byte("DB_HOST=db-2"), 0o644)
+//	_ = env.Reload()
+//	// #string "DB_HOST"
+//	// #string "modified"
+func OnChange(keyOrPattern string, fn func(Change)) func() {
+	if _, err := path.Match(keyOrPattern, ""); err != nil {
+		panic(fmt.Sprintf("env: invalid change pattern %q: %v", keyOrPattern, err))
+	}
+	if fn == nil {
+		panic("env: nil change subscriber")
+	}
+	return environmentChanges.subscribe(keyOrPattern, fn)
+}
+
+// changeSubscription pairs a key pattern with its callback.
+type changeSubscription struct {
+	pattern string
+	fn      func(Change)
+}
+
+// matches reports whether the subscription applies to key.
+func (s *changeSubscription) matches(key string) bool {
+	if s.pattern == key {
+		return true
+	}
+	matched, _ := path.Match(s.pattern, key)
+	return matched
+}
+
+// changeDispatcher queues applied change sets so they are delivered in order without holding the loader lock.
+type changeDispatcher struct {
+	mu          sync.Mutex
+	subscribers []*changeSubscription
+	pending     []ChangeSet
+	delivering  bool
+}
+
+var environmentChanges changeDispatcher
+
+// subscribe registers a callback and returns an idempotent cancel function.
+func (d *changeDispatcher) subscribe(pattern string, fn func(Change)) func() {
+	subscription := &changeSubscription{pattern: pattern, fn: fn}
+	d.mu.Lock()
+	d.subscribers = append(d.subscribers, subscription)
+	d.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			d.mu.Lock()
+			defer d.mu.Unlock()
+			for index, candidate := range d.subscribers {
+				if candidate == subscription {
+					d.subscribers = append(d.subscribers[:index:index], d.subscribers[index+1:]...)
+					return
+				}
+			}
+		})
+	}
+}
+
+// enqueue records an applied change set; callers hold the loader lock so queue order matches apply order.
+func (d *changeDispatcher) enqueue(changes ChangeSet) {
+	if len(changes) == 0 {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(d.subscribers) == 0 {
+		return
+	}
+	d.pending = append(d.pending, changes)
+}
+
+// deliver drains queued change sets; a nested call from a subscriber leaves draining to the outer call.
+func (d *changeDispatcher) deliver() {
+	d.mu.Lock()
+	if d.delivering {
+		d.mu.Unlock()
+		return
+	}
+	d.delivering = true
+	d.mu.Unlock()
+
+	finished := false
+	defer func() {
+		if !finished {
+			d.mu.Lock()
+			d.delivering = false
+			d.mu.Unlock()
+		}
+	}()
+
+	for {
+		d.mu.Lock()
+		if len(d.pending) == 0 {
+			d.delivering = false
+			finished = true
+			d.mu.Unlock()
+			return
+		}
+		changes := d.pending[0]
+		d.pending = d.pending[1:]
+		subscribers := append([]*changeSubscription(nil), d.subscribers...)
+		d.mu.Unlock()
+
+		for _, change := range changes {
+			for _, subscriber := range subscribers {
+				if subscriber.matches(change.Key) {
+					subscriber.fn(change)
+				}
+			}
+		}
+	}
+}
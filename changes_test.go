@@ -0,0 +1,188 @@
+package env
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+// TestReloadWithChangesReportsEveryKind ensures each transition is classified from the applied snapshots.
+func TestReloadWithChangesReportsEveryKind(t *testing.T) {
+	prepareLoaderTest(
+		t,
+		"ENV_QPASS_ADDED",
+		"ENV_QPASS_MODIFIED",
+		"ENV_QPASS_REMOVED",
+		"ENV_QPASS_RESTORED",
+		"ENV_QPASS_STABLE",
+	)
+	t.Setenv("APP_ENV", Local)
+	t.Setenv("ENV_QPASS_RESTORED", "ambient")
+	_ = os.Unsetenv("ENV_QPASS_ADDED")
+	_ = os.Unsetenv("ENV_QPASS_MODIFIED")
+	_ = os.Unsetenv("ENV_QPASS_REMOVED")
+	_ = os.Unsetenv("ENV_QPASS_STABLE")
+	directory := t.TempDir()
+	writeEnvFile(
+		t,
+		directory,
+		fileEnv,
+		"ENV_QPASS_MODIFIED=first\nENV_QPASS_REMOVED=file\nENV_QPASS_STABLE=same\n",
+	)
+	changeWorkingDirectory(t, directory)
+	if err := Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	_ = os.Unsetenv("ENV_QPASS_RESTORED")
+	writeEnvFile(
+		t,
+		directory,
+		fileEnv,
+		"ENV_QPASS_MODIFIED=first\nENV_QPASS_REMOVED=file\nENV_QPASS_RESTORED=file\nENV_QPASS_STABLE=same\n",
+	)
+	if err := Reload(); err != nil {
+		t.Fatalf("Reload claiming unset key: %v", err)
+	}
+	writeEnvFile(t, directory, fileEnv, "ENV_QPASS_ADDED=new\nENV_QPASS_MODIFIED=second\nENV_QPASS_STABLE=same\n")
+
+	changes, err := ReloadWithChanges()
+	if err != nil {
+		t.Fatalf("ReloadWithChanges: %v", err)
+	}
+	kinds := map[string]ChangeKind{}
+	for _, change := range changes {
+		kinds[change.Key] = change.Kind
+	}
+	want := map[string]ChangeKind{
+		"ENV_QPASS_ADDED":    ChangeAdded,
+		"ENV_QPASS_MODIFIED": ChangeModified,
+		"ENV_QPASS_REMOVED":  ChangeRemoved,
+		"ENV_QPASS_RESTORED": ChangeRestored,
+	}
+	if !reflect.DeepEqual(kinds, want) {
+		t.Fatalf("expected change kinds %v, got %v", want, kinds)
+	}
+	if got := changes.Keys(ChangeAdded, ChangeRemoved); !reflect.DeepEqual(got, []string{"ENV_QPASS_ADDED", "ENV_QPASS_REMOVED"}) {
+		t.Fatalf("unexpected filtered keys %v", got)
+	}
+}
+
+// TestChangeValuesAreRedactedByDefault ensures change sets can be logged without disclosing values.
+func TestChangeValuesAreRedactedByDefault(t *testing.T) {
+	change := newChange(
+		"ENV_QPASS_SECRET",
+		ChangeModified,
+		environmentSnapshot{value: "old-secret", present: true},
+		environmentSnapshot{value: "new-secret", present: true},
+	)
+	if change.Previous != redactedChangeValue || change.Current != redactedChangeValue {
+		t.Fatalf("expected redacted values, got %+v", change)
+	}
+	revealed := change.Unredacted()
+	if revealed.Previous != "old-secret" || revealed.Current != "new-secret" {
+		t.Fatalf("expected unredacted values, got %+v", revealed)
+	}
+
+	removed := newChange("ENV_QPASS_SECRET", ChangeRemoved, environmentSnapshot{value: "x", present: true}, environmentSnapshot{})
+	if removed.Current != "" {
+		t.Fatalf("expected unset value to stay empty, got %q", removed.Current)
+	}
+}
+
+// TestOnChangeDeliversMatchingKeysAfterApply ensures subscribers observe the applied environment.
+func TestOnChangeDeliversMatchingKeysAfterApply(t *testing.T) {
+	prepareLoaderTest(t, "ENV_QPASS_DB_HOST", "ENV_QPASS_CACHE")
+	t.Setenv("APP_ENV", Local)
+	_ = os.Unsetenv("ENV_QPASS_DB_HOST")
+	_ = os.Unsetenv("ENV_QPASS_CACHE")
+	directory := t.TempDir()
+	writeEnvFile(t, directory, fileEnv, "ENV_QPASS_DB_HOST=db-1\nENV_QPASS_CACHE=on\n")
+	changeWorkingDirectory(t, directory)
+
+	var observed []string
+	cancel := OnChange("ENV_QPASS_DB_*", func(change Change) {
+		observed = append(observed, change.Key+"="+os.Getenv(change.Key))
+	})
+	t.Cleanup(cancel)
+
+	if err := Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	writeEnvFile(t, directory, fileEnv, "ENV_QPASS_DB_HOST=db-2\nENV_QPASS_CACHE=off\n")
+	if err := Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	cancel()
+	writeEnvFile(t, directory, fileEnv, "ENV_QPASS_DB_HOST=db-3\n")
+	if err := Reload(); err != nil {
+		t.Fatalf("Reload after cancel: %v", err)
+	}
+
+	want := []string{"ENV_QPASS_DB_HOST=db-1", "ENV_QPASS_DB_HOST=db-2"}
+	if !reflect.DeepEqual(observed, want) {
+		t.Fatalf("expected %v, got %v", want, observed)
+	}
+}
+
+// TestOnChangeSkipsFailedReloads ensures subscribers never observe a rolled-back configuration.
+func TestOnChangeSkipsFailedReloads(t *testing.T) {
+	prepareLoaderTest(t, "ENV_QPASS_A")
+	t.Setenv("APP_ENV", Local)
+	directory := t.TempDir()
+	writeEnvFile(t, directory, fileEnv, "ENV_QPASS_A=old\n")
+	changeWorkingDirectory(t, directory)
+	if err := Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	calls := 0
+	cancel := OnChange("ENV_QPASS_A", func(Change) { calls++ })
+	t.Cleanup(cancel)
+	writeEnvFile(t, directory, fileEnv, "ENV_QPASS_A='unterminated\n")
+	if err := Reload(); err == nil {
+		t.Fatal("expected Reload parse error")
+	}
+	if calls != 0 {
+		t.Fatalf("expected no notifications for a failed reload, got %d", calls)
+	}
+}
+
+// TestOnChangeAllowsNestedReload ensures a subscriber may reload without deadlocking delivery.
+func TestOnChangeAllowsNestedReload(t *testing.T) {
+	prepareLoaderTest(t, "ENV_QPASS_NESTED")
+	t.Setenv("APP_ENV", Local)
+	directory := t.TempDir()
+	writeEnvFile(t, directory, fileEnv, "ENV_QPASS_NESTED=first\n")
+	changeWorkingDirectory(t, directory)
+	if err := Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	var observed []string
+	cancel := OnChange("ENV_QPASS_NESTED", func(change Change) {
+		observed = append(observed, change.Unredacted().Current)
+		if change.Unredacted().Current == "second" {
+			writeEnvFile(t, directory, fileEnv, "ENV_QPASS_NESTED=third\n")
+			if err := Reload(); err != nil {
+				t.Errorf("nested Reload: %v", err)
+			}
+		}
+	})
+	t.Cleanup(cancel)
+
+	writeEnvFile(t, directory, fileEnv, "ENV_QPASS_NESTED=second\n")
+	if err := Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	if want := []string{"second", "third"}; !reflect.DeepEqual(observed, want) {
+		t.Fatalf("expected ordered nested delivery %v, got %v", want, observed)
+	}
+}
+
+// TestOnChangeRejectsMalformedPattern ensures invalid subscriptions fail loudly at registration.
+func TestOnChangeRejectsMalformedPattern(t *testing.T) {
+	expectPanic(t, "OnChange", func() {
+		OnChange("[", func(Change) {})
+	})
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/goforj/env/v2"
+)
+
+// exportCommand implements env export: it prints the values env files supply for the selected
+// layers in --format, leaving out variables that only come from the current environment.
+func exportCommand(args, environ []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	var layers layerFlags
+	layers.register(flags)
+	format := flags.String("format", string(env.ExportDotenv), "output format: dotenv, shell, fish, powershell, json, docker, github, or kubernetes")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() > 0 {
+		fmt.Fprintf(stderr, "env export: unexpected argument %q\n", flags.Arg(0))
+		return 2
+	}
+
+	environment, err := layers.load(environ)
+	if err != nil {
+		fmt.Fprintf(stderr, "env export: %v\n", err)
+		return 1
+	}
+	if err := environment.Export(stdout, env.ExportFormat(*format)); err != nil {
+		fmt.Fprintf(stderr, "env export: %v\n", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// TestExportPrintsFileValues ensures export prints layered file values in the chosen format without ambient variables.
+func TestExportPrintsFileValues(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, ".env", "PORT=8080\nNAME=base\nDB_PASSWORD=hunter2\n")
+	writeFile(t, dir, ".env.production", "PORT=80\nGREETING=it's here\n")
+
+	var stdout, stderr bytes.Buffer
+	code := runCLI([]string{"export", "--dir", dir, "--app-env", "production", "--format", "shell"}, []string{"HOME=/root"}, nil, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("expected success, got %d: %s", code, stderr.String())
+	}
+	want := "export DB_PASSWORD='hunter2'\nexport GREETING='it'\\''s here'\nexport NAME='base'\nexport PORT='80'\n"
+	if stdout.String() != want {
+		t.Fatalf("expected:\n%s\ngot:\n%s", want, stdout.String())
+	}
+
+	stdout.Reset()
+	code = runCLI([]string{"export", "--dir", dir}, nil, nil, &stdout, &stderr)
+	if code != 0 || !strings.HasPrefix(stdout.String(), "DB_PASSWORD=hunter2\nNAME=base\nPORT=8080\n") {
+		t.Fatalf("expected dotenv by default, got %d %q", code, stdout.String())
+	}
+}
+
+// TestExportReportsErrors ensures usage, load, and format failures map to exit codes.
+func TestExportReportsErrors(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, ".env", "BROKEN LINE\n")
+	multiline := t.TempDir()
+	writeFile(t, multiline, ".env", "MOTD=\"a\\nb\"\n")
+	dotted := t.TempDir()
+	writeFile(t, dotted, ".env", "app.name=demo\n")
+
+	tests := []struct {
+		args   []string
+		code   int
+		stderr string
+	}{
+		{args: []string{"--bogus"}, code: 2, stderr: "flag provided but not defined"},
+		{args: []string{"extra"}, code: 2, stderr: `unexpected argument "extra"`},
+		{args: []string{"--dir", dir}, code: 1, stderr: "env export:"},
+		{args: []string{"--dir", multiline, "--format", "yaml"}, code: 1, stderr: `unsupported format "yaml"`},
+		{args: []string{"--dir", multiline, "--format", "docker"}, code: 1, stderr: "MOTD has a multi-line value"},
+		{args: []string{"--dir", dotted, "--format", "fish"}, code: 1, stderr: "app.name is not a valid fish variable name"},
+	}
+	for _, test := range tests {
+		var stdout, stderr bytes.Buffer
+		code := runCLI(append([]string{"export"}, test.args...), nil, nil, &stdout, &stderr)
+		if code != test.code || !strings.Contains(stderr.String(), test.stderr) || stdout.Len() != 0 {
+			t.Fatalf("%v: got code %d stdout %q stderr %q", test.args, code, stdout.String(), stderr.String())
+		}
+	}
+}
+
+// TestExportIncludesComposeService ensures --compose and --service layer a compose service for export and run.
+func TestExportIncludesComposeService(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, ".env", "PORT=8080\n")
+	writeFile(t, dir, "docker-compose.yml", "services:\n  api:\n    environment:\n      - PORT=9000\n      - DB_HOST=${DB_HOST:-db}\n")
+	t.Chdir(dir)
+
+	var stdout, stderr bytes.Buffer
+	code := runCLI([]string{"export", "--compose", "docker-compose.yml", "--service", "api"}, nil, nil, &stdout, &stderr)
+	if code != 0 || !strings.HasPrefix(stdout.String(), "DB_HOST=db\nPORT=9000\n") {
+		t.Fatalf("expected the compose service values, got %d %q %s", code, stdout.String(), stderr.String())
+	}
+
+	code, output, _ := runForTest(t, []string{"ENV_CLI_HELPER=print", "DB_HOST=localhost"},
+		append([]string{"--compose", "docker-compose.yml", "--service", "api"}, helperCommand("DB_HOST,PORT")...)...)
+	if code != 0 || !strings.Contains(output, "DB_HOST=localhost ") || !strings.Contains(output, "PORT=9000 ") {
+		t.Fatalf("expected run to layer the compose service, got %d %q", code, output)
+	}
+
+	stdout.Reset()
+	stderr.Reset()
+	code = runCLI([]string{"export", "--service", "api"}, nil, nil, &stdout, &stderr)
+	if code != 1 || !strings.Contains(stderr.String(), "--compose and --service must be used together") {
+		t.Fatalf("expected a paired flag error, got %d %q", code, stderr.String())
+	}
+}
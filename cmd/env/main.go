@@ -0,0 +1,67 @@
+// Command env runs programs with, or prints, the environment that env.Load would apply.
+//
+// Usage:
+//
+//	env run [layer flags] -- command [args...]
+//	env export [--format name] [layer flags]
+//
+// Layer flags are --app-env name, --file path (repeatable), --dir dir, and --compose file with
+// --service name to add a docker-compose service's environment.
+//
+// run resolves the same layers as Load, from .env through the APP_ENV, runtime, and testing
+// layers, over the current environment, then starts command with the result. SIGTERM and SIGHUP
+// are forwarded to the child, while Ctrl-C and Ctrl-\ reach it from the terminal alone. env exits
+// with the child's exit code, or 128 plus the signal number when the child is killed by a signal.
+//
+// export prints the values the env files supply for the same layers as dotenv, shell, fish,
+// powershell, json, docker, github, or kubernetes output, quoted for that target.
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// usage describes every subcommand.
+const usage = `usage: env <command> [flags]
+
+commands:
+  run [layer flags] -- command [args...]
+        run command with the layered environment
+  export [--format dotenv] [layer flags]
+        print the layered env file values as dotenv, shell, fish, powershell,
+        json, docker, github, or kubernetes
+
+layer flags:
+  --app-env name         APP_ENV to resolve layers for
+  --file path            env file to load instead of discovery; repeatable
+  --dir dir              directory to discover env files from
+  --compose file --service name
+                         layer a docker-compose service's environment on top
+`
+
+// main exits with the status of the selected subcommand.
+func main() {
+	os.Exit(runCLI(os.Args[1:], os.Environ(), os.Stdin, os.Stdout, os.Stderr))
+}
+
+// runCLI dispatches args to a subcommand and returns the process exit code.
+func runCLI(args, environ []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+	switch args[0] {
+	case "run":
+		return runCommand(args[1:], environ, stdin, stdout, stderr)
+	case "export":
+		return exportCommand(args[1:], environ, stdout, stderr)
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(stdout, usage)
+		return 0
+	default:
+		fmt.Fprintf(stderr, "env: unknown command %q\n\n%s", args[0], usage)
+		return 2
+	}
+}
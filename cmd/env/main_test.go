@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+// helperVariable switches the test binary into a child process for env run.
+const helperVariable = "ENV_CLI_HELPER"
+
+// TestMain lets the test binary stand in for the command that env run starts.
+func TestMain(m *testing.M) {
+	if mode := os.Getenv(helperVariable); mode != "" {
+		os.Exit(runHelper(mode, os.Args[len(os.Args)-1]))
+	}
+	os.Exit(m.Run())
+}
+
+// runHelper prints the requested variables, exits with a code, or waits for a forwarded SIGHUP and
+// adds the number of interrupts it received first to the exit code.
+func runHelper(mode, argument string) int {
+	switch mode {
+	case "print":
+		for _, key := range strings.Split(argument, ",") {
+			value, ok := os.LookupEnv(key)
+			fmt.Printf("%s=%s present=%t\n", key, value, ok)
+		}
+		return 0
+	case "exit":
+		code, _ := strconv.Atoi(argument)
+		return code
+	case "signal":
+		received := make(chan os.Signal, 1)
+		signal.Notify(received, syscall.SIGHUP, os.Interrupt)
+		fmt.Println("ready")
+		interrupts := 0
+		for sig := range received {
+			if sig == syscall.SIGHUP {
+				return 42 + interrupts
+			}
+			interrupts++
+		}
+	}
+	return 99
+}
+
+// helperCommand returns env run arguments that start this test binary as the child.
+func helperCommand(argument string) []string {
+	return []string{"--", os.Args[0], "-test.run=^$", argument}
+}
+
+// TestRunCLIDispatchesCommands ensures usage, help, and unknown commands report through the right streams.
+func TestRunCLIDispatchesCommands(t *testing.T) {
+	tests := []struct {
+		args   []string
+		code   int
+		stdout string
+		stderr string
+	}{
+		{args: nil, code: 2, stderr: "usage: env <command>"},
+		{args: []string{"help"}, code: 0, stdout: "commands:"},
+		{args: []string{"deploy"}, code: 2, stderr: `env: unknown command "deploy"`},
+	}
+	for _, test := range tests {
+		var stdout, stderr bytes.Buffer
+		code := runCLI(test.args, nil, nil, &stdout, &stderr)
+		if code != test.code || !strings.Contains(stdout.String(), test.stdout) || !strings.Contains(stderr.String(), test.stderr) {
+			t.Fatalf("%v: got code %d stdout %q stderr %q", test.args, code, stdout.String(), stderr.String())
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+
+	"github.com/goforj/env/v2"
+)
+
+// forwardedSignals are relayed to the child so it can shut down on its own terms.
+var forwardedSignals = []os.Signal{syscall.SIGTERM, syscall.SIGHUP}
+
+// terminalSignals are caught but not relayed: the child shares env's process group, so Ctrl-C and
+// Ctrl-\ already reach it from the terminal, and many programs treat a second SIGINT as force quit.
+var terminalSignals = []os.Signal{os.Interrupt, syscall.SIGQUIT}
+
+// layerFlags selects the layers a subcommand resolves.
+type layerFlags struct {
+	appEnv  string
+	files   []string
+	dir     string
+	compose string
+	service string
+}
+
+// register adds the shared --app-env, --file, and --dir flags to flags.
+func (l *layerFlags) register(flags *flag.FlagSet) {
+	flags.StringVar(&l.appEnv, "app-env", "", "APP_ENV to resolve layers for, overriding the environment")
+	flags.Func("file", "env file to load instead of discovery; repeat to layer several, later files win", func(path string) error {
+		l.files = append(l.files, path)
+		return nil
+	})
+	flags.StringVar(&l.dir, "dir", "", "directory to discover env files from (default: the working directory)")
+	flags.StringVar(&l.compose, "compose", "", "docker-compose file whose --service environment is layered above the env files")
+	flags.StringVar(&l.service, "service", "", "service in the --compose file")
+}
+
+// load resolves the selected layers over environ without changing the process.
+//
+// --file paths resolve against the working directory and are passed to the loader through ENV_FILE.
+func (l *layerFlags) load(environ []string) (*env.Environment, error) {
+	ambient := append([]string(nil), environ...)
+	if l.appEnv != "" {
+		ambient = append(ambient, "APP_ENV="+l.appEnv)
+	}
+	if len(l.files) > 0 {
+		paths := make([]string, 0, len(l.files))
+		for _, path := range l.files {
+			absolute, err := filepath.Abs(path)
+			if err != nil {
+				return nil, fmt.Errorf("resolve env file %s: %w", path, err)
+			}
+			paths = append(paths, absolute)
+		}
+		ambient = append(ambient, "ENV_FILE="+strings.Join(paths, ","))
+	}
+	options := env.LoadIntoOptions{Environ: ambient}
+	if l.compose != "" || l.service != "" {
+		if l.compose == "" || l.service == "" {
+			return nil, errors.New("--compose and --service must be used together")
+		}
+		compose, err := filepath.Abs(l.compose)
+		if err != nil {
+			return nil, fmt.Errorf("resolve compose file %s: %w", l.compose, err)
+		}
+		options.Compose = []env.ComposeService{{File: compose, Service: l.service}}
+	}
+	return env.LoadInto(l.dir, options)
+}
+
+// resolve returns the KEY=value entries Load would leave in the environment, without changing it.
+// An ENV_FILE set for --file is restored to its value in environ.
+func (l *layerFlags) resolve(environ []string) ([]string, error) {
+	environment, err := l.load(environ)
+	if err != nil {
+		return nil, err
+	}
+	if len(l.files) == 0 {
+		return environment.Environ(), nil
+	}
+	values := environment.Values()
+	delete(values, "ENV_FILE")
+	for _, entry := range environ {
+		if value, ok := strings.CutPrefix(entry, "ENV_FILE="); ok {
+			values["ENV_FILE"] = value
+		}
+	}
+	return environEntries(values), nil
+}
+
+// environEntries formats values as sorted KEY=value entries.
+func environEntries(values map[string]string) []string {
+	entries := make([]string, 0, len(values))
+	for key, value := range values {
+		entries = append(entries, key+"="+value)
+	}
+	sort.Strings(entries)
+	return entries
+}
+
+// runCommand implements env run: it starts the command after -- with the layered environment,
+// forwards termination signals to it, and returns its exit code.
+func runCommand(args, environ []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("run", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	var layers layerFlags
+	layers.register(flags)
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	command := flags.Args()
+	if len(command) == 0 {
+		fmt.Fprintln(stderr, "env run: missing command after --")
+		return 2
+	}
+
+	resolved, err := layers.resolve(environ)
+	if err != nil {
+		fmt.Fprintf(stderr, "env run: %v\n", err)
+		return 1
+	}
+
+	child := exec.Command(command[0], command[1:]...)
+	child.Env = resolved
+	child.Stdin, child.Stdout, child.Stderr = stdin, stdout, stderr
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, forwardedSignals...)
+	defer signal.Stop(signals)
+	// Nothing reads caught; it only keeps env alive until the child reports how it exited.
+	caught := make(chan os.Signal, 1)
+	signal.Notify(caught, terminalSignals...)
+	defer signal.Stop(caught)
+	if err := child.Start(); err != nil {
+		fmt.Fprintf(stderr, "env run: %v\n", err)
+		if errors.Is(err, exec.ErrNotFound) {
+			return 127
+		}
+		return 126
+	}
+
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case sig := <-signals:
+				_ = child.Process.Signal(sig)
+			case <-done:
+				return
+			}
+		}
+	}()
+	err = child.Wait()
+	close(done)
+	return exitCode(err)
+}
+
+// exitCode maps the result of Wait to a shell-style exit status.
+func exitCode(err error) int {
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		if err != nil {
+			return 1
+		}
+		return 0
+	}
+	if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		return 128 + int(status.Signal())
+	}
+	return exitErr.ExitCode()
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// writeFile writes contents to name in dir or fails the test.
+func writeFile(t *testing.T, dir, name, contents string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(contents), 0o644); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+}
+
+// runForTest runs env run with args in a fresh environment and returns its exit code and output.
+func runForTest(t *testing.T, environ []string, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := runCLI(append([]string{"run"}, args...), environ, strings.NewReader(""), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+// TestRunAppliesLayers ensures the child sees discovered layers, --app-env, and process precedence.
+func TestRunAppliesLayers(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, ".env", "PORT=8080\nNAME=base\nSHELL_VALUE=file\n")
+	writeFile(t, dir, ".env.production", "PORT=80\n")
+	environ := []string{"ENV_CLI_HELPER=print", "SHELL_VALUE=process", "APP_ENV=local"}
+
+	code, stdout, stderr := runForTest(t, environ, append([]string{"--dir", dir, "--app-env", "production"}, helperCommand("APP_ENV,PORT,NAME,SHELL_VALUE")...)...)
+	if code != 0 {
+		t.Fatalf("expected success, got %d: %s", code, stderr)
+	}
+	for _, want := range []string{"APP_ENV=production present=true", "PORT=80 ", "NAME=base ", "SHELL_VALUE=process "} {
+		if !strings.Contains(stdout, want) {
+			t.Fatalf("expected %q in %q", want, stdout)
+		}
+	}
+}
+
+// TestRunLoadsExplicitFiles ensures --file replaces discovery, resolves against the working directory, and does not leak ENV_FILE.
+func TestRunLoadsExplicitFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, ".env", "DISCOVERED=yes\n")
+	writeFile(t, dir, "prod.env", "LEVEL=warn\nPORT=80\n")
+	writeFile(t, dir, "secrets.env", "LEVEL=error\n")
+	t.Chdir(dir)
+
+	code, stdout, stderr := runForTest(t, []string{"ENV_CLI_HELPER=print"},
+		append([]string{"--file", "prod.env", "--file", "secrets.env"}, helperCommand("LEVEL,PORT,DISCOVERED,ENV_FILE")...)...)
+	if code != 0 {
+		t.Fatalf("expected success, got %d: %s", code, stderr)
+	}
+	for _, want := range []string{"LEVEL=error ", "PORT=80 ", "DISCOVERED= present=false", "ENV_FILE= present=false"} {
+		if !strings.Contains(stdout, want) {
+			t.Fatalf("expected %q in %q", want, stdout)
+		}
+	}
+
+	code, stdout, _ = runForTest(t, []string{"ENV_CLI_HELPER=print", "ENV_FILE=other.env"},
+		append([]string{"--file", "prod.env"}, helperCommand("ENV_FILE")...)...)
+	if code != 0 || !strings.Contains(stdout, "ENV_FILE=other.env present=true") {
+		t.Fatalf("expected the inherited ENV_FILE to be kept, got %d %q", code, stdout)
+	}
+}
+
+// TestRunReportsErrors ensures usage, load, and start failures map to conventional exit codes.
+func TestRunReportsErrors(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, ".env", "BROKEN LINE\n")
+
+	tests := []struct {
+		name   string
+		args   []string
+		code   int
+		stderr string
+	}{
+		{name: "no command", args: []string{"--"}, code: 2, stderr: "missing command"},
+		{name: "bad flag", args: []string{"--unknown"}, code: 2, stderr: "flag provided but not defined"},
+		{name: "load error", args: []string{"--dir", dir, "--", "true"}, code: 1, stderr: "expected '=' after key BROKEN"},
+		{name: "missing file", args: []string{"--file", filepath.Join(dir, "missing.env"), "--", "true"}, code: 1, stderr: "missing.env"},
+		{name: "not found", args: []string{"--dir", t.TempDir(), "--", "env-cli-command-that-does-not-exist"}, code: 127, stderr: "executable file not found"},
+		{name: "not executable", args: []string{"--dir", t.TempDir(), "--", filepath.Join(dir, ".env")}, code: 126, stderr: "permission denied"},
+	}
+	for _, test := range tests {
+		code, _, stderr := runForTest(t, nil, test.args...)
+		if code != test.code || !strings.Contains(stderr, test.stderr) {
+			t.Fatalf("%s: expected %d with %q, got %d %q", test.name, test.code, test.stderr, code, stderr)
+		}
+	}
+}
+
+// TestRunForwardsExitCodesAndSignals ensures the child's status becomes ours, SIGHUP reaches it, and SIGINT is not sent twice.
+func TestRunForwardsExitCodesAndSignals(t *testing.T) {
+	dir := t.TempDir()
+	code, _, stderr := runForTest(t, []string{"ENV_CLI_HELPER=exit"}, append([]string{"--dir", dir}, helperCommand("3")...)...)
+	if code != 3 {
+		t.Fatalf("expected exit code 3, got %d: %s", code, stderr)
+	}
+
+	reader, writer := io.Pipe()
+	result := make(chan int, 1)
+	go func() {
+		var stderr bytes.Buffer
+		result <- runCLI(append([]string{"run", "--dir", dir}, helperCommand("")...), []string{"ENV_CLI_HELPER=signal"}, strings.NewReader(""), writer, &stderr)
+		_ = writer.Close()
+	}()
+	ready := make([]byte, len("ready\n"))
+	if _, err := io.ReadFull(reader, ready); err != nil {
+		t.Fatalf("wait for child: %v", err)
+	}
+	self, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatalf("find process: %v", err)
+	}
+	// The terminal delivers Ctrl-C to the child itself, so env must survive it without relaying it.
+	if err := self.Signal(os.Interrupt); err != nil {
+		t.Fatalf("signal: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if err := self.Signal(syscall.SIGHUP); err != nil {
+		t.Fatalf("signal: %v", err)
+	}
+	go func() { _, _ = io.Copy(io.Discard, reader) }()
+	select {
+	case code := <-result:
+		if code != 42 {
+			t.Fatalf("expected the child to handle only the forwarded SIGHUP, got %d", code)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out waiting for the child")
+	}
+}
+
+// TestExitCodeReportsSignals ensures a child killed by a signal exits with 128 plus the signal number.
+func TestExitCodeReportsSignals(t *testing.T) {
+	code, _, stderr := runForTest(t, nil, "--dir", t.TempDir(), "--", "sh", "-c", "kill -TERM $$")
+	if code != 128+int(syscall.SIGTERM) {
+		t.Fatalf("expected %d, got %d: %s", 128+int(syscall.SIGTERM), code, stderr)
+	}
+}
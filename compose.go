@@ -0,0 +1,572 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// layerCompose labels a docker-compose service environment or env_file in ENV_DEBUG output.
+const layerCompose = "compose"
+
+// ComposeService names one service of a docker-compose file.
+type ComposeService struct {
+	// File is the compose file path, such as docker-compose.yml.
+	File string
+	// Service is the name under services: whose environment and env_file entries are read.
+	Service string
+}
+
+// LoadCompose transactionally applies the environment a docker-compose service would receive.
+// @group Environment loading
+// @behavior mutates-process-env
+//
+// The service's env_file entries are read in order, then its environment block, in either list or
+// map syntax, overrides them, as Compose does. A relative file resolves against the working
+// directory, and env_file paths resolve against the compose file's directory. An env_file entry
+// with required: false may be missing. A key listed without a value takes the value Compose would
+// pass through, if any. YAML anchors and << merge keys are resolved.
+//
+// Environment values and env_file paths use Compose interpolation: $VAR, ${VAR}, ${VAR:-default},
+// ${VAR-default}, ${VAR:?error}, ${VAR?error}, ${VAR:+replacement}, ${VAR+replacement}, and $$
+// for a literal $. Like Compose, references see the process environment, then the .env file next
+// to the compose file, and an unset variable expands to an empty string. env_file values use the
+// same rules, and also see keys set earlier in the service's env_files. The results are taken
+// literally.
+//
+// Compose layers rank above every env file but below LoadDir directories and the override layer,
+// and process values still beat them. Calling LoadCompose again for the same file and service
+// moves it to the top. Like LoadFiles, LoadCompose applies even after an earlier load, and Reload
+// and Watch keep reading the compose file until Unload.
+//
+// Example: run a compose service outside Docker
+//
+//	project, _ := os.MkdirTemp("", "envdoc")
+//	defer os.RemoveAll(project)
+//	compose := "services:\n  api:\n    environment:\n      DB_HOST: ${DB_HOST:-localhost}\n      DB_PORT: 5432\n"
+//	_ = os.WriteFile(filepath.Join(project, "docker-compose.yml"), []byte(compose), 0o644)
+//
+//	_ = env.LoadCompose(filepath.Join(project, "docker-compose.yml"), "api")
+//	env.Dump(os.Getenv("DB_HOST"), os.Getenv("DB_PORT"))
+//	// #string "localhost"
+//	// #string "5432"
+func LoadCompose(file, service string) error {
+	if file == "" || service == "" {
+		return errors.New("load compose env: empty file or service")
+	}
+	if !filepath.IsAbs(file) {
+		workingDirectory, err := envFileGetwd()
+		if err != nil {
+			return fmt.Errorf("get working directory for env loading: %w", err)
+		}
+		file = filepath.Join(workingDirectory, file)
+	}
+	source := ComposeService{File: filepath.Clean(file), Service: service}
+	_, err := load(true, func(sources *environmentSources) {
+		compose := make([]ComposeService, 0, len(sources.compose)+1)
+		for _, existing := range sources.compose {
+			if existing != source {
+				compose = append(compose, existing)
+			}
+		}
+		sources.compose = append(compose, source)
+	})
+	return err
+}
+
+// resolveComposeServices makes every compose file path absolute against directory.
+func resolveComposeServices(directory string, services []ComposeService) []ComposeService {
+	resolved := make([]ComposeService, 0, len(services))
+	for _, service := range services {
+		if !filepath.IsAbs(service.File) {
+			service.File = filepath.Join(directory, service.File)
+		}
+		service.File = filepath.Clean(service.File)
+		resolved = append(resolved, service)
+	}
+	return resolved
+}
+
+// mergeComposeLayers merges the env_file entries and then the environment of every registered
+// service, keeping process values authoritative.
+func mergeComposeLayers(
+	plan *environmentLoadPlan,
+	services []ComposeService,
+	previous map[string]loadedEnvironmentValue,
+) error {
+	for _, service := range services {
+		plan.watched = append(plan.watched, service.File)
+		definition, err := readComposeService(service)
+		if err != nil {
+			return err
+		}
+		lookup, err := composeInterpolationLookup(plan, filepath.Dir(service.File), previous)
+		if err != nil {
+			return err
+		}
+		fail := func(err error) error {
+			return fmt.Errorf("compose file %s service %s: %w", service.File, service.Service, err)
+		}
+
+		envFiles, err := composeEnvFiles(definition, filepath.Dir(service.File), lookup)
+		if err != nil {
+			return fail(err)
+		}
+		earlier := make(map[string]string)
+		for _, envFile := range envFiles {
+			plan.watched = append(plan.watched, envFile.path)
+			found, err := statEnvFile(envFile.path)
+			if err != nil {
+				return err
+			}
+			if !found {
+				if envFile.required {
+					return fail(fmt.Errorf("env_file %s not found", envFile.path))
+				}
+				continue
+			}
+			file, err := readComposeEnvFile(envFile.path, earlier, lookup)
+			if err != nil {
+				return err
+			}
+			mergeEnvironmentFile(plan, file, previous)
+			plan.layers = append(plan.layers, layerCompose)
+		}
+
+		values, err := composeEnvironment(definition, lookup)
+		if err != nil {
+			return fail(err)
+		}
+		if len(values) > 0 {
+			mergeEnvironmentFile(plan, environmentFile{path: service.File, values: values}, previous)
+			plan.layers = append(plan.layers, layerCompose)
+		}
+	}
+	return nil
+}
+
+// readComposeEnvFile reads an env_file and expands its values with Compose interpolation, in file
+// order, as Compose does: a reference sees keys set earlier in this or a previous env_file of the
+// service, recorded in earlier, and then lookup. The values it returns are literal.
+func readComposeEnvFile(path string, earlier map[string]string, lookup func(string) (string, bool)) (environmentFile, error) {
+	entries, file, err := readEnvFileEntries(path, false)
+	if err != nil {
+		return environmentFile{}, err
+	}
+	resolve := func(name string) (string, bool) {
+		if value, ok := earlier[name]; ok {
+			return value, true
+		}
+		return lookup(name)
+	}
+	file.templates = nil
+	for _, entry := range entries {
+		value := entry.value
+		if entry.expand {
+			if value, err = expandComposeTemplate(entry.template, resolve); err != nil {
+				return environmentFile{}, &interpolationError{file: path, key: entry.key, err: err}
+			}
+		}
+		file.values[entry.key] = value
+		earlier[entry.key] = value
+	}
+	return file, nil
+}
+
+// readComposeService returns the definition of one service, with aliases resolved.
+func readComposeService(service ComposeService) (*yaml.Node, error) {
+	data, err := os.ReadFile(service.File)
+	if err != nil {
+		return nil, fmt.Errorf("read compose file %s: %w", service.File, err)
+	}
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("decode compose file %s: %w", service.File, err)
+	}
+	var root *yaml.Node
+	if len(document.Content) > 0 {
+		root = document.Content[0]
+	}
+	services, err := composeMappingValue(root, "services")
+	if err != nil {
+		return nil, fmt.Errorf("decode compose file %s: %w", service.File, err)
+	}
+	definition, err := composeMappingValue(services, service.Service)
+	if err != nil {
+		return nil, fmt.Errorf("decode compose file %s: %w", service.File, err)
+	}
+	if definition == nil {
+		return nil, fmt.Errorf("compose file %s has no service %q", service.File, service.Service)
+	}
+	return definition, nil
+}
+
+// composeEntry is one key of a YAML mapping after merge keys are applied.
+type composeEntry struct {
+	key   string
+	value *yaml.Node
+}
+
+// composeMappingEntries returns the keys of a mapping in document order, resolving aliases and
+// << merge keys, which explicit keys beat.
+func composeMappingEntries(node *yaml.Node) ([]composeEntry, error) {
+	return composeMergedEntries(node, &yamlEnvExpansion{expanding: make(map[*yaml.Node]bool)})
+}
+
+// composeMergedEntries implements composeMappingEntries, rejecting a merge into a mapping that
+// contains it and counting merged sources against maxYAMLAliasExpansions.
+func composeMergedEntries(node *yaml.Node, expansion *yamlEnvExpansion) ([]composeEntry, error) {
+	node = composeResolveAlias(node)
+	if node == nil || node.Tag == "!!null" {
+		return nil, nil
+	}
+	if node.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("line %d: expected a mapping", node.Line)
+	}
+	if expansion.expanding[node] {
+		return nil, fmt.Errorf("line %d: merge key refers to a mapping that contains it", node.Line)
+	}
+	expansion.expanding[node] = true
+	defer delete(expansion.expanding, node)
+	var entries, merged []composeEntry
+	explicit := make(map[string]struct{}, len(node.Content)/2)
+	for index := 0; index+1 < len(node.Content); index += 2 {
+		name, value := node.Content[index], node.Content[index+1]
+		if name.Tag == "!!merge" {
+			sources := []*yaml.Node{value}
+			if resolved := composeResolveAlias(value); resolved.Kind == yaml.SequenceNode {
+				sources = resolved.Content
+			}
+			for _, source := range sources {
+				if resolved := composeResolveAlias(source); resolved.Kind != yaml.MappingNode {
+					return nil, fmt.Errorf("line %d: merge key needs a mapping", name.Line)
+				}
+				expansion.aliases++
+				if expansion.aliases > maxYAMLAliasExpansions {
+					return nil, fmt.Errorf("line %d: more than %d merged mappings", name.Line, maxYAMLAliasExpansions)
+				}
+				sourceEntries, err := composeMergedEntries(source, expansion)
+				if err != nil {
+					return nil, err
+				}
+				merged = append(merged, sourceEntries...)
+			}
+			continue
+		}
+		if name.Kind != yaml.ScalarNode {
+			return nil, fmt.Errorf("line %d: mapping keys must be scalars", name.Line)
+		}
+		entries = append(entries, composeEntry{key: name.Value, value: value})
+		explicit[name.Value] = struct{}{}
+	}
+	for _, entry := range merged {
+		if _, set := explicit[entry.key]; !set {
+			entries = append(entries, entry)
+			explicit[entry.key] = struct{}{}
+		}
+	}
+	return entries, nil
+}
+
+// composeMappingValue returns the value of key in a mapping, or nil when it is absent.
+func composeMappingValue(node *yaml.Node, key string) (*yaml.Node, error) {
+	entries, err := composeMappingEntries(node)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if entry.key == key {
+			return composeResolveAlias(entry.value), nil
+		}
+	}
+	return nil, nil
+}
+
+// composeResolveAlias follows alias nodes to the node they name.
+func composeResolveAlias(node *yaml.Node) *yaml.Node {
+	for node != nil && node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	return node
+}
+
+// composeEnvFile is one env_file entry of a service.
+type composeEnvFile struct {
+	path     string
+	required bool
+}
+
+// composeEnvFiles reads env_file as a path, a list of paths, or a list of {path, required} entries.
+func composeEnvFiles(definition *yaml.Node, directory string, lookup func(string) (string, bool)) ([]composeEnvFile, error) {
+	node, err := composeMappingValue(definition, "env_file")
+	if err != nil || node == nil {
+		return nil, err
+	}
+	items := []*yaml.Node{node}
+	if node.Kind == yaml.SequenceNode {
+		items = node.Content
+	}
+	envFiles := make([]composeEnvFile, 0, len(items))
+	for _, item := range items {
+		item = composeResolveAlias(item)
+		envFile := composeEnvFile{required: true}
+		path := item
+		if item.Kind == yaml.MappingNode {
+			if path, err = composeMappingValue(item, "path"); err != nil {
+				return nil, err
+			}
+			required, err := composeMappingValue(item, "required")
+			if err != nil {
+				return nil, err
+			}
+			if required != nil {
+				envFile.required = required.Kind != yaml.ScalarNode || required.Value != "false"
+			}
+			format, err := composeMappingValue(item, "format")
+			if err != nil {
+				return nil, err
+			}
+			if format != nil {
+				return nil, fmt.Errorf("line %d: env_file format is not supported", format.Line)
+			}
+		}
+		if path == nil || path.Kind != yaml.ScalarNode || path.Value == "" {
+			return nil, fmt.Errorf("line %d: env_file needs a path", item.Line)
+		}
+		expanded, err := expandComposeTemplate(path.Value, lookup)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: env_file: %w", path.Line, err)
+		}
+		if !filepath.IsAbs(expanded) {
+			expanded = filepath.Join(directory, expanded)
+		}
+		envFile.path = filepath.Clean(expanded)
+		envFiles = append(envFiles, envFile)
+	}
+	return envFiles, nil
+}
+
+// composeEnvironment reads the environment block in list or map syntax and interpolates each value.
+//
+// A key without a value takes its value from lookup and is left out when lookup has none.
+func composeEnvironment(definition *yaml.Node, lookup func(string) (string, bool)) (map[string]string, error) {
+	node, err := composeMappingValue(definition, "environment")
+	if err != nil || node == nil {
+		return nil, err
+	}
+	type variable struct {
+		key   string
+		value *string
+		line  int
+	}
+	var variables []variable
+	switch node.Kind {
+	case yaml.SequenceNode:
+		for _, item := range node.Content {
+			item = composeResolveAlias(item)
+			if item.Kind != yaml.ScalarNode {
+				return nil, fmt.Errorf("line %d: environment entries must be KEY=value strings", item.Line)
+			}
+			key, value, ok := strings.Cut(item.Value, "=")
+			entry := variable{key: key, line: item.Line}
+			if ok {
+				entry.value = &value
+			}
+			variables = append(variables, entry)
+		}
+	default:
+		entries, err := composeMappingEntries(node)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			value := composeResolveAlias(entry.value)
+			if value.Kind != yaml.ScalarNode {
+				return nil, fmt.Errorf("line %d: environment value for %s must be a scalar", value.Line, entry.key)
+			}
+			item := variable{key: entry.key, line: value.Line}
+			if value.Tag != "!!null" {
+				item.value = &value.Value
+			}
+			variables = append(variables, item)
+		}
+	}
+
+	values := make(map[string]string, len(variables))
+	for _, entry := range variables {
+		if !isValidEnvKey(entry.key) {
+			return nil, fmt.Errorf("line %d: invalid environment variable name %q", entry.line, entry.key)
+		}
+		if entry.value == nil {
+			if value, ok := lookup(entry.key); ok {
+				values[entry.key] = value
+			}
+			continue
+		}
+		value, err := expandComposeTemplate(*entry.value, lookup)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: interpolate %s: %w", entry.line, entry.key, err)
+		}
+		values[entry.key] = value
+	}
+	return values, nil
+}
+
+// composeInterpolationLookup resolves Compose references against the process environment, then the
+// .env file in the compose file's directory, as Compose does.
+func composeInterpolationLookup(
+	plan *environmentLoadPlan,
+	directory string,
+	previous map[string]loadedEnvironmentValue,
+) (func(string) (string, bool), error) {
+	process := func(name string) (string, bool) {
+		snapshot := originalEnvironmentSnapshot(name, previous, plan.lookup)
+		return snapshot.value, snapshot.present
+	}
+
+	path := filepath.Join(directory, fileEnv)
+	plan.watched = append(plan.watched, path)
+	found, err := statEnvFile(path)
+	if err != nil || !found {
+		return process, err
+	}
+	file, err := parseEnvFile(path, false)
+	if err != nil {
+		return nil, err
+	}
+	raw := func(name string) (string, bool) {
+		if value, ok := process(name); ok {
+			return value, true
+		}
+		value, ok := file.values[name]
+		return value, ok
+	}
+	project := make(map[string]string, len(file.values))
+	keys := make([]string, 0, len(file.values))
+	for key := range file.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := file.values[key]
+		if template, ok := file.templates[key]; ok {
+			if value, err = expandComposeTemplate(template, raw); err != nil {
+				return nil, &interpolationError{file: path, key: key, err: err}
+			}
+		}
+		project[key] = value
+	}
+	return func(name string) (string, bool) {
+		if value, ok := process(name); ok {
+			return value, true
+		}
+		value, ok := project[name]
+		return value, ok
+	}, nil
+}
+
+// expandComposeTemplate expands Compose interpolation: $VAR, ${VAR} with the -, :-, ?, :?, +, and
+// :+ modifiers, and $$. An unset variable expands to an empty string, and a $ that does not start
+// one of those forms is kept literally.
+func expandComposeTemplate(template string, lookup func(string) (string, bool)) (string, error) {
+	var expanded strings.Builder
+	for index := 0; index < len(template); {
+		rest := template[index:]
+		switch {
+		case rest[0] != '$':
+			expanded.WriteByte(rest[0])
+			index++
+		case strings.HasPrefix(rest, "$$"):
+			expanded.WriteByte('$')
+			index += 2
+		case strings.HasPrefix(rest, "${"):
+			value, width, err := expandComposeReference(rest, lookup)
+			if err != nil {
+				return "", err
+			}
+			expanded.WriteString(value)
+			index += width
+		case len(rest) > 1 && isEnvKeyStart(rest[1]):
+			width := 2
+			for width < len(rest) && isComposeNamePart(rest[width]) {
+				width++
+			}
+			value, _ := lookup(rest[1:width])
+			expanded.WriteString(value)
+			index += width
+		default:
+			expanded.WriteByte('$')
+			index++
+		}
+	}
+	return expanded.String(), nil
+}
+
+// isComposeNamePart reports whether c may continue a Compose variable name; unlike env file keys,
+// names stop at a dot, so $HOST.internal reads HOST.
+func isComposeNamePart(c byte) bool {
+	return c == '_' || 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9'
+}
+
+// expandComposeReference expands the ${...} reference at the start of text and returns its width.
+func expandComposeReference(text string, lookup func(string) (string, bool)) (string, int, error) {
+	nameEnd := len("${")
+	for nameEnd < len(text) && isComposeNamePart(text[nameEnd]) {
+		nameEnd++
+	}
+	name := text[len("${"):nameEnd]
+	if name == "" || !isEnvKeyStart(name[0]) {
+		return "", 0, errors.New("invalid variable name in reference")
+	}
+	end := matchingReferenceBrace(text)
+	if end < 0 {
+		return "", 0, fmt.Errorf("unterminated reference to %s", name)
+	}
+
+	modifier := text[nameEnd:end]
+	operator := ""
+	for _, candidate := range []string{":-", ":?", ":+", "-", "?", "+"} {
+		if strings.HasPrefix(modifier, candidate) {
+			operator = candidate
+			break
+		}
+	}
+	if operator == "" && modifier != "" {
+		return "", 0, fmt.Errorf("unsupported modifier in reference to %s", name)
+	}
+	argument := modifier[len(operator):]
+
+	value, present := lookup(name)
+	// The colon forms treat an empty value like an unset one.
+	set := present && (value != "" || !strings.HasPrefix(operator, ":"))
+	switch strings.TrimPrefix(operator, ":") {
+	case "":
+		return value, end + 1, nil
+	case "-":
+		if set {
+			return value, end + 1, nil
+		}
+	case "?":
+		if set {
+			return value, end + 1, nil
+		}
+		if argument == "" {
+			return "", 0, fmt.Errorf("%s is required", name)
+		}
+		return "", 0, fmt.Errorf("%s: %s", name, argument)
+	case "+":
+		if !set {
+			return "", end + 1, nil
+		}
+	}
+	expanded, err := expandComposeTemplate(argument, lookup)
+	if err != nil {
+		return "", 0, err
+	}
+	return expanded, end + 1, nil
+}
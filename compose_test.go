@@ -0,0 +1,236 @@
+package env
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestExpandComposeTemplateFollowsCompose ensures every Compose interpolation form expands like docker compose.
+func TestExpandComposeTemplateFollowsCompose(t *testing.T) {
+	values := map[string]string{"SET": "value", "EMPTY": "", "HOST": "db", "HOST.internal": "wrong"}
+	lookup := func(name string) (string, bool) {
+		value, ok := values[name]
+		return value, ok
+	}
+	tests := map[string]string{
+		"$SET/$EMPTY/$UNSET":                                  "value//",
+		"${SET}-${UNSET}":                                     "value-",
+		"${EMPTY:-fallback}|${EMPTY-fallback}":                "fallback|",
+		"${UNSET-${SET}}":                                     "value",
+		"${SET:+yes}|${EMPTY:+yes}|${EMPTY+yes}|${UNSET+yes}": "yes||yes|",
+		"$$SET costs $5 or $":                                 "$SET costs $5 or $",
+		"${EMPTY?set but empty}":                              "",
+		"http://$HOST.internal:5432":                          "http://db.internal:5432",
+		"$HOST-x":                                             "db-x",
+	}
+	for template, want := range tests {
+		got, err := expandComposeTemplate(template, lookup)
+		if err != nil || got != want {
+			t.Fatalf("expand %q: expected %q, got %q (%v)", template, want, got, err)
+		}
+	}
+
+	failures := map[string]string{
+		"${EMPTY:?must be set}": "EMPTY: must be set",
+		"${UNSET?}":             "UNSET is required",
+		"${SET":                 "unterminated reference to SET",
+		"${1X}":                 "invalid variable name",
+		"${SET/a/b}":            "unsupported modifier",
+		"${A.B}":                "unsupported modifier in reference to A",
+		"${HOST.x:-d}":          "unsupported modifier in reference to HOST",
+		"${UNSET:-${1}}":        "invalid variable name",
+	}
+	for template, message := range failures {
+		if _, err := expandComposeTemplate(template, lookup); err == nil || !strings.Contains(err.Error(), message) {
+			t.Fatalf("expand %q: expected an error containing %q, got %v", template, message, err)
+		}
+	}
+}
+
+// TestLoadComposeAppliesServiceEnvironment ensures env_file entries, list and map environments, anchors, and the project .env layer like Compose.
+func TestLoadComposeAppliesServiceEnvironment(t *testing.T) {
+	keys := []string{"ENV_QCOMPOSE_HOST", "ENV_QCOMPOSE_PORT", "ENV_QCOMPOSE_LEVEL", "ENV_QCOMPOSE_USER", "ENV_QCOMPOSE_TAG", "ENV_QCOMPOSE_PASS", "ENV_QCOMPOSE_MISSING", "ENV_QCOMPOSE_SHARED", "ENV_QCOMPOSE_WORKER"}
+	prepareLoaderTest(t, keys...)
+	for _, key := range keys {
+		_ = os.Unsetenv(key)
+	}
+	t.Setenv("APP_ENV", Local)
+	t.Setenv("ENV_QCOMPOSE_USER", "from shell")
+	directory := t.TempDir()
+	writeEnvFile(t, directory, fileEnv, "ENV_QCOMPOSE_TAG=v1\nENV_QCOMPOSE_PASS=from project\n")
+	writeEnvFile(t, directory, "api.env", "ENV_QCOMPOSE_LEVEL=debug\nENV_QCOMPOSE_HOST=file\nENV_QCOMPOSE_SHARED=${ENV_QCOMPOSE_TAG}-shared\n")
+	writeEnvFile(t, directory, "docker-compose.yml", `x-common: &common
+  ENV_QCOMPOSE_HOST: anchored
+  ENV_QCOMPOSE_PORT: 8080
+services:
+  api:
+    env_file:
+      - api.env
+      - path: ./missing-${ENV_QCOMPOSE_TAG}.env
+        required: false
+    environment:
+      <<: *common
+      ENV_QCOMPOSE_HOST: db-$${literal}
+      ENV_QCOMPOSE_USER: ignored
+      ENV_QCOMPOSE_PASS:
+      ENV_QCOMPOSE_MISSING:
+  worker:
+    env_file: api.env
+    environment:
+      - ENV_QCOMPOSE_WORKER=${ENV_QCOMPOSE_TAG:?tag required}
+      - ENV_QCOMPOSE_LEVEL
+`)
+	changeWorkingDirectory(t, directory)
+
+	if err := LoadCompose("docker-compose.yml", "api"); err != nil {
+		t.Fatalf("LoadCompose: %v", err)
+	}
+	want := map[string]string{
+		"ENV_QCOMPOSE_HOST":   "db-${literal}",
+		"ENV_QCOMPOSE_PORT":   "8080",
+		"ENV_QCOMPOSE_LEVEL":  "debug",
+		"ENV_QCOMPOSE_USER":   "from shell",
+		"ENV_QCOMPOSE_TAG":    "v1",
+		"ENV_QCOMPOSE_PASS":   "from project",
+		"ENV_QCOMPOSE_SHARED": "v1-shared",
+	}
+	for key, value := range want {
+		if got := os.Getenv(key); got != value {
+			t.Fatalf("expected %s=%q, got %q", key, value, got)
+		}
+	}
+	if _, present := os.LookupEnv("ENV_QCOMPOSE_MISSING"); present {
+		t.Fatal("expected a key without any value to stay unset")
+	}
+	processEnvironmentLoader.mu.Lock()
+	watched := append([]string(nil), processEnvironmentLoader.watched...)
+	processEnvironmentLoader.mu.Unlock()
+	for _, path := range []string{"docker-compose.yml", "api.env", "missing-v1.env"} {
+		if !strings.Contains(strings.Join(watched, "\n"), filepath.Join(directory, path)) {
+			t.Fatalf("expected %s to be watched, got %v", path, watched)
+		}
+	}
+
+	environment, err := LoadInto(directory, LoadIntoOptions{Compose: []ComposeService{{File: "docker-compose.yml", Service: "worker"}}})
+	if err != nil {
+		t.Fatalf("LoadInto: %v", err)
+	}
+	if got := environment.Get("ENV_QCOMPOSE_WORKER", ""); got != "v1" {
+		t.Fatalf("expected the worker list environment, got %q", got)
+	}
+	if source := environment.Source("ENV_QCOMPOSE_WORKER"); source != filepath.Join(directory, "docker-compose.yml") {
+		t.Fatalf("expected the compose file as the source, got %q", source)
+	}
+	if got := environment.Get("ENV_QCOMPOSE_LEVEL", ""); got != "debug" {
+		t.Fatalf("expected a bare list key to keep the env_file value, got %q", got)
+	}
+}
+
+// TestLoadComposeRejectsInvalidServices ensures malformed compose files and missing services fail the load.
+func TestLoadComposeRejectsInvalidServices(t *testing.T) {
+	tests := []struct {
+		name    string
+		compose string
+		service string
+		message string
+	}{
+		{name: "service", compose: "services:\n  api: {}\n", service: "web", message: `has no service "web"`},
+		{name: "syntax", compose: "services: [\n", service: "api", message: "decode compose file"},
+		{name: "services", compose: "services: [api]\n", service: "api", message: "expected a mapping"},
+		{name: "required", compose: "services:\n  api:\n    env_file: missing.env\n", service: "api", message: "missing.env not found"},
+		{name: "format", compose: "services:\n  api:\n    env_file:\n      - path: a.env\n        format: raw\n", service: "api", message: "format is not supported"},
+		{name: "path", compose: "services:\n  api:\n    env_file:\n      - required: true\n", service: "api", message: "env_file needs a path"},
+		{name: "list", compose: "services:\n  api:\n    environment:\n      - {A: b}\n", service: "api", message: "must be KEY=value strings"},
+		{name: "value", compose: "services:\n  api:\n    environment:\n      A: [b]\n", service: "api", message: "value for A must be a scalar"},
+		{name: "key", compose: "services:\n  api:\n    environment:\n      - 1A=b\n", service: "api", message: `invalid environment variable name "1A"`},
+		{name: "interpolate", compose: "services:\n  api:\n    environment:\n      A: ${B:?needed}\n", service: "api", message: "interpolate A: B: needed"},
+		{name: "merge", compose: "services:\n  api:\n    <<: [plain]\n", service: "api", message: "merge key needs a mapping"},
+		{name: "mapkey", compose: "services:\n  ? [a]\n  : b\n", service: "api", message: "mapping keys must be scalars"},
+		{name: "cycle", compose: "services:\n  api: &api\n    <<: *api\n", service: "api", message: "merge key refers to a mapping that contains it"},
+		{name: "laughs", compose: "x-a: &a {A: b}\nx-b: &b {<<: [*a, *a, *a, *a, *a, *a, *a, *a, *a, *a]}\nx-c: &c {<<: [*b, *b, *b, *b, *b, *b, *b, *b, *b, *b]}\nx-d: &d {<<: [*c, *c, *c, *c, *c, *c, *c, *c, *c, *c]}\nx-e: &e {<<: [*d, *d, *d, *d, *d, *d, *d, *d, *d, *d]}\nservices:\n  api: *e\n", service: "api", message: "more than 10000 merged mappings"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			prepareLoaderTest(t)
+			directory := t.TempDir()
+			writeEnvFile(t, directory, "compose.yaml", test.compose)
+			changeWorkingDirectory(t, directory)
+			_, err := LoadInto(directory, LoadIntoOptions{Compose: []ComposeService{{File: "compose.yaml", Service: test.service}}})
+			if err == nil || !strings.Contains(err.Error(), test.message) {
+				t.Fatalf("expected an error containing %q, got %v", test.message, err)
+			}
+		})
+	}
+
+	if err := LoadCompose("", "api"); err == nil {
+		t.Fatal("expected an empty file to be rejected")
+	}
+	prepareLoaderTest(t)
+	directory := t.TempDir()
+	changeWorkingDirectory(t, directory)
+	if err := LoadCompose("compose.yaml", "api"); err == nil || !strings.Contains(err.Error(), "read compose file") {
+		t.Fatalf("expected a missing compose file to fail, got %v", err)
+	}
+}
+
+// TestLoadComposeUsesProjectEnvironment ensures the project .env interpolates with process values first and its own templates expanded.
+func TestLoadComposeUsesProjectEnvironment(t *testing.T) {
+	directory := t.TempDir()
+	writeEnvFile(t, directory, fileEnv, "REGISTRY=registry.local\nIMAGE=${REGISTRY}/api\n")
+	writeEnvFile(t, directory, "compose.yaml", "services:\n  api:\n    environment:\n      IMAGE_REF: $IMAGE:latest\n")
+	environment, err := LoadInto(directory, LoadIntoOptions{
+		Environ: []string{"REGISTRY=ghcr.io"},
+		Compose: []ComposeService{{File: filepath.Join(directory, "compose.yaml"), Service: "api"}},
+	})
+	if err != nil {
+		t.Fatalf("LoadInto: %v", err)
+	}
+	got := map[string]string{"IMAGE_REF": environment.Get("IMAGE_REF", ""), "IMAGE": environment.Get("IMAGE", "")}
+	want := map[string]string{"IMAGE_REF": "ghcr.io/api:latest", "IMAGE": "ghcr.io/api"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+
+	writeEnvFile(t, directory, fileEnv, "IMAGE=${REGISTRY:?registry required}\n")
+	if _, err := LoadInto(directory, LoadIntoOptions{Compose: []ComposeService{{File: "compose.yaml", Service: "api"}}}); err == nil || !strings.Contains(err.Error(), "registry required") {
+		t.Fatalf("expected the project .env interpolation error, got %v", err)
+	}
+}
+
+// TestLoadComposeInterpolatesEnvFilesLikeCompose ensures env_file values use Compose modifiers, bare $VAR, and earlier env_file keys, and stay literal afterwards.
+func TestLoadComposeInterpolatesEnvFilesLikeCompose(t *testing.T) {
+	directory := t.TempDir()
+	writeEnvFile(t, directory, "api.env", "ROOT_DIR=$HOME/app\nCACHE_DIR=${CACHE_HOME-$ROOT_DIR/cache}\nPRICE='$HOME'\nTOTAL=\"$$5 for ${USER:-nobody}\"\n")
+	writeEnvFile(t, directory, "more.env", "CACHE_SET=${CACHE_DIR:+yes}\nHOME_COPY=$HOME\n")
+	writeEnvFile(t, directory, "compose.yaml", "services:\n  api:\n    env_file: [api.env, more.env]\n  strict:\n    env_file: strict.env\n")
+	writeEnvFile(t, directory, "strict.env", "TOKEN=${API_TOKEN:?token required}\n")
+
+	environment, err := LoadInto(directory, LoadIntoOptions{
+		Environ: []string{"HOME=/home/app", "HOME_COPY=ignored"},
+		Compose: []ComposeService{{File: "compose.yaml", Service: "api"}},
+	})
+	if err != nil {
+		t.Fatalf("LoadInto: %v", err)
+	}
+	want := map[string]string{
+		"ROOT_DIR":  "/home/app/app",
+		"CACHE_DIR": "/home/app/app/cache",
+		"PRICE":     "$HOME",
+		"TOTAL":     "$5 for nobody",
+		"CACHE_SET": "yes",
+		"HOME_COPY": "ignored",
+	}
+	for key, value := range want {
+		if got := environment.Get(key, ""); got != value {
+			t.Fatalf("expected %s=%q, got %q", key, value, got)
+		}
+	}
+
+	_, err = LoadInto(directory, LoadIntoOptions{Compose: []ComposeService{{File: "compose.yaml", Service: "strict"}}})
+	if err == nil || !strings.Contains(err.Error(), "token required") || !strings.Contains(err.Error(), "strict.env") {
+		t.Fatalf("expected the env_file interpolation error, got %v", err)
+	}
+}
@@ -185,6 +185,20 @@ type Example struct {
 var exampleHeader = regexp.MustCompile(`(?i)^\s*Example:\s*(.*)$`)
 var groupHeader = regexp.MustCompile(`(?i)^\s*@group\s+(.+)$`)
 
+// timePackageUse requires a word boundary so identifiers such as runtime.GOOS do not import time.
+var timePackageUse = regexp.MustCompile(`\btime\.`)
+
+// stripLineComments drops trailing output comments such as "// #time.Duration 5s" before import detection.
+func stripLineComments(code string) string {
+	lines := strings.Split(code, "\n")
+	for index, line := range lines {
+		if before, _, found := strings.Cut(line, "//"); found {
+			lines[index] = before
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
 type docLine struct {
 	text string
 	pos  token.Pos
@@ -462,6 +476,18 @@ func writeMain(base string, fd *FuncDoc, importPath string, nameCollides bool) e
 		if strings.Contains(ex.Code, "godump.") {
 			imports["github.com/goforj/godump"] = true
 		}
+		if strings.Contains(ex.Code, "context.") {
+			imports["context"] = true
+		}
+		if strings.Contains(ex.Code, "fstest.") {
+			imports["testing/fstest"] = true
+		}
+		if strings.Contains(ex.Code, "base64.") {
+			imports["encoding/base64"] = true
+		}
+		if timePackageUse.MatchString(stripLineComments(ex.Code)) {
+			imports["time"] = true
+		}
 	}
 
 	writeImports(&buf, imports)
@@ -88,6 +88,10 @@ type Example struct {
 // ------------------------------------------------------------
 //
 
+// behaviorHeader reads an API's side effects: readonly for APIs that change no shared state,
+// mutates-process-env for the process environment, mutates-loader for loader configuration and
+// registries, writes-files for APIs that rewrite files on disk, and panic for APIs that panic
+// instead of returning an error.
 var (
 	groupHeader    = regexp.MustCompile(`(?i)^\s*@group\s+(.+)$`)
 	behaviorHeader = regexp.MustCompile(`(?i)^\s*@behavior\s+(.+)$`)
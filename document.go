@@ -0,0 +1,342 @@
+package env
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Document is an env file held as written, so edits keep its comments, blank lines, quoting, and
+// key order.
+//
+// Get, Set, Unset, and Rename work on top-level assignments; keys under a [section] header are left
+// as they are. Values are read and written before ${...} references expand, as Parse returns them.
+type Document struct {
+	bom     []byte
+	body    []byte
+	name    string
+	entries []envEntry
+	// sections is the offset of the first [section] header, or -1 when there is none.
+	sections int
+}
+
+// ParseDocument reads an env file into an editable Document.
+// @group Editing
+// @behavior readonly
+//
+// The data must parse like an env file Load reads; #include lines and [section] blocks are kept
+// as they are. A malformed document returns a *SyntaxError.
+//
+// Example: update one value and keep the rest
+//
+//	document, _ := env.ParseDocument(strings.NewReader("# database\nDB_HOST=localhost # dev only\nDB_PASSWORD='old'\n"))
+//	_ = document.Set("DB_PASSWORD", "n3w")
+//	_ = document.Set("DB_PORT", "5432")
+//	fmt.Print(string(document.Bytes()))
+//	// # database
+//	// DB_HOST=localhost # dev only
+//	// DB_PASSWORD='n3w'
+//	// DB_PORT=5432
+func ParseDocument(r io.Reader) (*Document, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("read env data: %w", err)
+	}
+	return newDocument("", data)
+}
+
+// ReadDocument reads the env file at path into an editable Document.
+// @group Editing
+// @behavior readonly
+//
+// Like Load, ReadDocument follows a symlink to a regular file and refuses anything else, such as a
+// directory or FIFO.
+//
+// Example: rotate a value in place
+//
+//	tmp, _ := os.MkdirTemp("", "envdoc")
+//	defer os.RemoveAll(tmp)
+//	path := filepath.Join(tmp, ".env")
+//	_ = os.WriteFile(path, []byte("# rotated monthly\nAPI_TOKEN=\"abc\"\n"), 0o600)
+//
+//	envFile, _ := env.ReadDocument(path)
+//	_ = envFile.Set("API_TOKEN", "xyz")
+//	_ = envFile.WriteFile(path)
+//	rotated, _ := os.ReadFile(path)
+//	fmt.Print(string(rotated))
+//	// # rotated monthly
+//	// API_TOKEN="xyz"
+func ReadDocument(path string) (*Document, error) {
+	if err := checkDocumentFile(path); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read env file %s: %w", path, err)
+	}
+	return newDocument(path, data)
+}
+
+// newDocument splits off a byte order mark and parses data.
+func newDocument(name string, data []byte) (*Document, error) {
+	body := bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	document := &Document{bom: data[:len(data)-len(body)], name: name}
+	if err := document.reparse(append([]byte(nil), body...)); err != nil {
+		return nil, err
+	}
+	return document, nil
+}
+
+// reparse replaces the body and refreshes the entries from it.
+func (d *Document) reparse(body []byte) error {
+	entries, err := parseEnvEntries(body, d.name, true)
+	if err != nil {
+		return err
+	}
+	d.body = body
+	d.entries = d.entries[:0]
+	d.sections = -1
+	for _, entry := range entries {
+		switch {
+		case entry.section != "":
+			if d.sections < 0 {
+				d.sections = documentLineOffset(body, entry.sectionLine)
+			}
+		case entry.include == "":
+			d.entries = append(d.entries, entry)
+		}
+	}
+	return nil
+}
+
+// Get returns the value of key as written and whether the document sets it; a repeated key
+// returns its last value, as Load applies it.
+// @group Editing
+// @behavior readonly
+func (d *Document) Get(key string) (string, bool) {
+	for index := len(d.entries) - 1; index >= 0; index-- {
+		if d.entries[index].key == key {
+			return d.entries[index].value, true
+		}
+	}
+	return "", false
+}
+
+// Keys returns the document's keys in the order they first appear.
+// @group Editing
+// @behavior readonly
+func (d *Document) Keys() []string {
+	keys := make([]string, 0, len(d.entries))
+	seen := make(map[string]struct{}, len(d.entries))
+	for _, entry := range d.entries {
+		if _, duplicate := seen[entry.key]; !duplicate {
+			seen[entry.key] = struct{}{}
+			keys = append(keys, entry.key)
+		}
+	}
+	return keys
+}
+
+// Set changes the value of key, or adds KEY=value after the last top-level assignment when key is new.
+// @group Editing
+// @behavior readonly
+//
+// An existing value keeps its quoting style when the new value can be written in it: unquoted,
+// single-quoted, backtick-quoted, or double-quoted with escapes. Otherwise it is double-quoted,
+// with $ escaped when the old style was literal. When key is repeated, its last assignment changes.
+func (d *Document) Set(key, value string) error {
+	if !isValidEnvKey(key) {
+		return fmt.Errorf("set env key %q: invalid variable name", key)
+	}
+	body := d.body
+	for index := len(d.entries) - 1; index >= 0; index-- {
+		entry := d.entries[index]
+		if entry.key != key {
+			continue
+		}
+		edited := spliceDocument(body, entry.valueStart, entry.valueEnd, formatDocumentValue(value, entry.quote))
+		return d.reparse(edited)
+	}
+
+	offset := len(body)
+	switch {
+	case len(d.entries) > 0:
+		offset = d.entries[len(d.entries)-1].end
+	case d.sections >= 0:
+		offset = d.sections
+	}
+	lineBreak := "\n"
+	if bytes.Contains(body, []byte("\r\n")) {
+		lineBreak = "\r\n"
+	}
+	line := key + "=" + formatDocumentValue(value, 0) + lineBreak
+	if offset > 0 && body[offset-1] != '\n' {
+		line = lineBreak + line
+	}
+	return d.reparse(spliceDocument(body, offset, offset, line))
+}
+
+// Unset removes every assignment of key and reports whether there was one. Comments around the
+// assignments stay.
+// @group Editing
+// @behavior readonly
+func (d *Document) Unset(key string) bool {
+	body := d.body
+	removed := false
+	for index := len(d.entries) - 1; index >= 0; index-- {
+		if entry := d.entries[index]; entry.key == key {
+			body = spliceDocument(body, entry.start, entry.end, "")
+			removed = true
+		}
+	}
+	if removed {
+		// Removing whole assignments cannot make the rest of the document invalid.
+		_ = d.reparse(body)
+	}
+	return removed
+}
+
+// Rename changes every assignment of oldKey to newKey, keeping its value, position, and any export
+// prefix. It fails when oldKey is missing or newKey is invalid or already set.
+// @group Editing
+// @behavior readonly
+func (d *Document) Rename(oldKey, newKey string) error {
+	if !isValidEnvKey(newKey) {
+		return fmt.Errorf("rename env key %s: invalid variable name %q", oldKey, newKey)
+	}
+	if _, exists := d.Get(newKey); exists {
+		return fmt.Errorf("rename env key %s: %s is already set", oldKey, newKey)
+	}
+	if _, exists := d.Get(oldKey); !exists {
+		return fmt.Errorf("rename env key %s: key is not set", oldKey)
+	}
+	body := d.body
+	for index := len(d.entries) - 1; index >= 0; index-- {
+		entry := d.entries[index]
+		if entry.key != oldKey {
+			continue
+		}
+		// Only spaces and '=' follow the key, so its last occurrence before the value is the key itself.
+		keyStart := entry.start + bytes.LastIndex(body[entry.start:entry.valueStart], []byte(oldKey))
+		body = spliceDocument(body, keyStart, keyStart+len(oldKey), newKey)
+	}
+	return d.reparse(body)
+}
+
+// Bytes returns the document as it would be written.
+// @group Editing
+// @behavior readonly
+func (d *Document) Bytes() []byte {
+	return append(append([]byte(nil), d.bom...), d.body...)
+}
+
+// WriteTo writes the document to w.
+// @group Editing
+// @behavior readonly
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	written, err := w.Write(d.Bytes())
+	return int64(written), err
+}
+
+// WriteFile atomically writes the document to path through a temporary file and a rename.
+// @group Editing
+// @behavior writes-files
+//
+// An existing file keeps its permissions, and a symlink to a regular file is followed so the link
+// stays in place; any other existing non-regular file is refused. A new file is created with mode
+// 0600.
+func (d *Document) WriteFile(path string) error {
+	mode := os.FileMode(0o600)
+	target := path
+	if err := checkDocumentFile(path); err == nil {
+		if target, err = filepath.EvalSymlinks(path); err != nil {
+			return fmt.Errorf("write env file %s: %w", path, err)
+		}
+		info, err := os.Stat(target)
+		if err != nil {
+			return fmt.Errorf("write env file %s: %w", path, err)
+		}
+		mode = info.Mode().Perm()
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return replaceEnvFile(target, d.Bytes(), mode)
+}
+
+// checkDocumentFile applies statEnvFile's rule that only regular files, or symlinks to them, are
+// env files, returning an error wrapping os.ErrNotExist for a missing path.
+func checkDocumentFile(path string) error {
+	found, err := statEnvFile(path)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("env file %s: %w", path, os.ErrNotExist)
+	}
+	return nil
+}
+
+// documentLineOffset returns the offset of the first byte of the 1-based line in body.
+func documentLineOffset(body []byte, line int) int {
+	offset := 0
+	for ; line > 1; line-- {
+		offset += bytes.IndexByte(body[offset:], '\n') + 1
+	}
+	return offset
+}
+
+// spliceDocument returns body with body[start:end] replaced by text.
+func spliceDocument(body []byte, start, end int, text string) []byte {
+	edited := make([]byte, 0, len(body)-(end-start)+len(text))
+	edited = append(edited, body[:start]...)
+	edited = append(edited, text...)
+	return append(edited, body[end:]...)
+}
+
+// formatDocumentValue writes value in the quote style, falling back to double quotes.
+//
+// Literal styles fall back with $ escaped so the value stays literal; the double-quoted and
+// unquoted styles both interpolate, so $ is left for Load to expand.
+func formatDocumentValue(value string, quote byte) string {
+	switch quote {
+	case '\'', '`':
+		if !strings.ContainsRune(value, rune(quote)) {
+			return string(quote) + value + string(quote)
+		}
+		return doubleQuoteDocumentValue(value, true)
+	case '"':
+		return doubleQuoteDocumentValue(value, false)
+	default:
+		if isPlainDocumentValue(value) {
+			return value
+		}
+		return doubleQuoteDocumentValue(value, false)
+	}
+}
+
+// doubleQuoteDocumentValue escapes value for a double-quoted env value.
+func doubleQuoteDocumentValue(value string, literal bool) string {
+	replacements := []string{`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`}
+	if literal {
+		replacements = append(replacements, "$", `\$`)
+	}
+	return `"` + strings.NewReplacer(replacements...).Replace(value) + `"`
+}
+
+// isPlainDocumentValue reports whether value reads back unchanged without quotes.
+func isPlainDocumentValue(value string) bool {
+	if value == "" {
+		return true
+	}
+	if strings.ContainsAny(value, "\n\r\x00") || strings.ContainsRune("'\"`# \t", rune(value[0])) {
+		return false
+	}
+	if last := value[len(value)-1]; isInlineSpace(last) {
+		return false
+	}
+	return !strings.Contains(value, " #") && !strings.Contains(value, "\t#")
+}
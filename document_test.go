@@ -0,0 +1,211 @@
+package env
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestDocumentEditsKeepLayout ensures Set, Unset, and Rename touch only the assignments they change.
+func TestDocumentEditsKeepLayout(t *testing.T) {
+	source := "\xef\xbb\xbf# app\n\nexport APP_NAME=demo # shown in logs\nDB_PASSWORD='old'\nDB_PASSWORD=\"older\"\n  GREETING = `hi`\nREMOVED=1\n#include shared.env\n\n[production]\nAPP_NAME=prod\n"
+	document, err := ParseDocument(strings.NewReader(source))
+	if err != nil {
+		t.Fatalf("ParseDocument: %v", err)
+	}
+	if got := document.Keys(); !reflect.DeepEqual(got, []string{"APP_NAME", "DB_PASSWORD", "GREETING", "REMOVED"}) {
+		t.Fatalf("unexpected keys %v", got)
+	}
+	if value, ok := document.Get("DB_PASSWORD"); !ok || value != "older" {
+		t.Fatalf("expected the last assignment, got %q %v", value, ok)
+	}
+	if string(document.Bytes()) != source {
+		t.Fatal("expected an unedited document to round-trip byte for byte")
+	}
+
+	steps := []error{
+		document.Set("APP_NAME", "demo app"),
+		document.Set("DB_PASSWORD", "a\"b\nc"),
+		document.Set("GREETING", "it's `quoted` $HOME"),
+		document.Rename("GREETING", "WELCOME"),
+		document.Set("PORT", "8080"),
+	}
+	for index, err := range steps {
+		if err != nil {
+			t.Fatalf("step %d: %v", index, err)
+		}
+	}
+	if !document.Unset("REMOVED") || document.Unset("MISSING") {
+		t.Fatal("expected Unset to report whether a key was removed")
+	}
+	want := "\xef\xbb\xbf# app\n\nexport APP_NAME=demo app # shown in logs\nDB_PASSWORD='old'\nDB_PASSWORD=\"a\\\"b\\nc\"\n  WELCOME = \"it's `quoted` \\$HOME\"\nPORT=8080\n#include shared.env\n\n[production]\nAPP_NAME=prod\n"
+	if got := string(document.Bytes()); got != want {
+		t.Fatalf("expected:\n%q\ngot:\n%q", want, got)
+	}
+
+	entries, err := parseEnvEntries(document.Bytes()[len("\xef\xbb\xbf"):], ".env", true)
+	if err != nil {
+		t.Fatalf("reparse: %v", err)
+	}
+	values := map[string]string{}
+	for _, entry := range entries {
+		if entry.section == "" && entry.include == "" {
+			values[entry.key] = entry.value
+		}
+	}
+	wantValues := map[string]string{"APP_NAME": "demo app", "DB_PASSWORD": "a\"b\nc", "WELCOME": "it's `quoted` $HOME", "PORT": "8080"}
+	if !reflect.DeepEqual(values, wantValues) {
+		t.Fatalf("expected %v, got %v", wantValues, values)
+	}
+}
+
+// TestDocumentFormatsValuesForTheirStyle ensures new and changed values read back exactly.
+func TestDocumentFormatsValuesForTheirStyle(t *testing.T) {
+	tests := []struct {
+		source string
+		value  string
+		want   string
+	}{
+		{source: "A=x\n", value: "", want: "A=\n"},
+		{source: "A=x # note\n", value: "a #b", want: "A=\"a #b\" # note\n"},
+		{source: "A=x\n", value: " padded", want: "A=\" padded\"\n"},
+		{source: "A=x\n", value: "#hash", want: "A=\"#hash\"\n"},
+		{source: "A=x\n", value: "tab\tend", want: "A=tab\tend\n"},
+		{source: "A='x'\n", value: "${B}", want: "A='${B}'\n"},
+		{source: "A=`x`\n", value: "a'b", want: "A=`a'b`\n"},
+		{source: "A=\"x\"\n", value: "${B}\\", want: "A=\"${B}\\\\\"\n"},
+		{source: "A=x", value: "y", want: "A=y"},
+		{source: "A=x", value: "", want: "A="},
+	}
+	for _, test := range tests {
+		document, err := ParseDocument(strings.NewReader(test.source))
+		if err != nil {
+			t.Fatalf("parse %q: %v", test.source, err)
+		}
+		if err := document.Set("A", test.value); err != nil {
+			t.Fatalf("set %q: %v", test.value, err)
+		}
+		if got := string(document.Bytes()); got != test.want {
+			t.Fatalf("set %q in %q: expected %q, got %q", test.value, test.source, test.want, got)
+		}
+		if value, _ := document.Get("A"); value != test.value {
+			t.Fatalf("set %q in %q: read back %q", test.value, test.source, value)
+		}
+	}
+
+	appends := map[string]string{
+		"":                    "B=1\n",
+		"A=1":                 "A=1\nB=1\n",
+		"A=1\r\n# end\r\n":    "A=1\r\nB=1\r\n# end\r\n",
+		"# top\n[local]\nC=2": "# top\nB=1\n[local]\nC=2",
+	}
+	for source, want := range appends {
+		document, err := ParseDocument(strings.NewReader(source))
+		if err != nil {
+			t.Fatalf("parse %q: %v", source, err)
+		}
+		if err := document.Set("B", "1"); err != nil || string(document.Bytes()) != want {
+			t.Fatalf("append to %q: expected %q, got %q (%v)", source, want, document.Bytes(), err)
+		}
+	}
+}
+
+// TestDocumentRejectsInvalidEdits ensures invalid keys, renames, and documents fail without changes.
+func TestDocumentRejectsInvalidEdits(t *testing.T) {
+	if _, err := ParseDocument(strings.NewReader("A=1\nB 2\n")); err == nil {
+		t.Fatal("expected a malformed document to fail")
+	}
+	if _, err := ParseDocument(failingReader{}); err == nil {
+		t.Fatal("expected a read error")
+	}
+	document, err := ParseDocument(strings.NewReader("A=1\nB=2\n"))
+	if err != nil {
+		t.Fatalf("ParseDocument: %v", err)
+	}
+	failures := map[string]error{
+		"invalid variable name":       document.Set("1A", "x"),
+		`invalid variable name "B-2"`: document.Rename("A", "B-2"),
+		"B is already set":            document.Rename("A", "B"),
+		"key is not set":              document.Rename("C", "D"),
+	}
+	for message, err := range failures {
+		if err == nil || !strings.Contains(err.Error(), message) {
+			t.Fatalf("expected an error containing %q, got %v", message, err)
+		}
+	}
+	if got := string(document.Bytes()); got != "A=1\nB=2\n" {
+		t.Fatalf("expected failed edits to leave the document alone, got %q", got)
+	}
+
+	var written strings.Builder
+	if n, err := document.WriteTo(&written); err != nil || n != int64(len("A=1\nB=2\n")) || written.String() != "A=1\nB=2\n" {
+		t.Fatalf("WriteTo: %d %q %v", n, written.String(), err)
+	}
+}
+
+// failingReader fails every read.
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) { return 0, errors.New("unreadable") }
+
+// TestDocumentWriteFileReplacesAtomically ensures WriteFile keeps permissions, follows symlinks, and refuses non-regular files.
+func TestDocumentWriteFileReplacesAtomically(t *testing.T) {
+	directory := t.TempDir()
+	path := filepath.Join(directory, ".env")
+	writeEnvFile(t, directory, "target.env", "# keep\nTOKEN=old\n")
+	if err := os.Chmod(filepath.Join(directory, "target.env"), 0o640); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("target.env", path); err != nil {
+		t.Fatal(err)
+	}
+
+	document, err := ReadDocument(path)
+	if err != nil {
+		t.Fatalf("ReadDocument: %v", err)
+	}
+	if err := document.Set("TOKEN", "new"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if err := document.WriteFile(path); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	if target, err := os.Readlink(path); err != nil || target != "target.env" {
+		t.Fatalf("expected the symlink to stay, got %q %v", target, err)
+	}
+	info, err := os.Stat(filepath.Join(directory, "target.env"))
+	if err != nil || info.Mode().Perm() != 0o640 {
+		t.Fatalf("expected mode 0640 to be kept, got %v %v", info, err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "# keep\nTOKEN=new\n" {
+		t.Fatalf("unexpected contents %q", data)
+	}
+	entries, _ := os.ReadDir(directory)
+	if len(entries) != 2 {
+		t.Fatalf("expected no temporary files left behind, got %v", entries)
+	}
+
+	created := filepath.Join(directory, "created.env")
+	if err := document.WriteFile(created); err != nil {
+		t.Fatalf("WriteFile new: %v", err)
+	}
+	if info, err := os.Stat(created); err != nil || info.Mode().Perm() != 0o600 {
+		t.Fatalf("expected a new file with mode 0600, got %v %v", info, err)
+	}
+
+	if err := document.WriteFile(directory); err == nil || !strings.Contains(err.Error(), "is not a regular file") {
+		t.Fatalf("expected a directory to be refused, got %v", err)
+	}
+	if _, err := ReadDocument(directory); err == nil || !strings.Contains(err.Error(), "is not a regular file") {
+		t.Fatalf("expected a directory to be refused, got %v", err)
+	}
+	if _, err := ReadDocument(filepath.Join(directory, "missing.env")); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected a missing file error, got %v", err)
+	}
+	if err := document.WriteFile(filepath.Join(directory, "missing", ".env")); err == nil {
+		t.Fatal("expected a missing directory to fail")
+	}
+}
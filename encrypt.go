@@ -0,0 +1,360 @@
+package env
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	// envKeyVariable holds the base64 encryption key in the process environment.
+	envKeyVariable = "ENV_KEY"
+	// envKeyFileVariable names a file holding the key when ENV_KEY is unset.
+	envKeyFileVariable = "ENV_KEY_FILE"
+	// envFileKey is the key file searched like an env file when neither variable is set.
+	envFileKey = ".env.key"
+
+	// encryptedValuePrefix marks a value sealed by EncryptFile.
+	encryptedValuePrefix = "encrypted:"
+	// envKeySize is the AES-256 key length in bytes.
+	envKeySize = 32
+)
+
+// GenerateKey returns a new random key for EncryptFile, encoded as standard base64.
+// @group Encryption
+// @behavior readonly
+//
+// Store the key outside the repository, in ENV_KEY, a file named by ENV_KEY_FILE, or a
+// git-ignored .env.key next to your env files.
+//
+// Example: create a key
+//
+//	key := env.GenerateKey()
+//	decoded, _ := base64.StdEncoding.DecodeString(key)
+//	env.Dump(len(decoded))
+//	// #int 32
+func GenerateKey() string {
+	return base64.StdEncoding.EncodeToString(randomEnvBytes(envKeySize))
+}
+
+// EncryptFile seals every plain value in the dotenv file at path with key.
+// @group Encryption
+// @behavior writes-files
+//
+// Each non-empty value becomes encrypted:<base64>, sealed with AES-256-GCM and bound to its
+// variable name, so the file can be committed and values cannot be swapped between keys. Keys,
+// comments, blank lines, and values that are already encrypted are kept as written, and the file,
+// or the target of a symlink to it, is replaced atomically with its original permissions. Load decrypts winning values with the key
+// from ENV_KEY, the file named by ENV_KEY_FILE, or the nearest .env.key, and fails the whole load
+// when any value cannot be decrypted. Decrypted values are literal and never interpolated, so a
+// value that references other variables, such as ${DB_HOST} or $HOME, is left plain and reported
+// on standard error instead of being sealed.
+//
+// Example: commit an encrypted production file
+//
+//	tmp, _ := os.MkdirTemp("", "envdoc")
+//	defer os.RemoveAll(tmp)
+//	production := filepath.Join(tmp, ".env.production")
+//	_ = os.WriteFile(production, []byte("DB_PASSWORD=hunter2 # rotated monthly"), 0o600)
+//
+//	key := env.GenerateKey()
+//	_ = env.EncryptFile(production, key)
+//	sealed, _ := os.ReadFile(production)
+//	env.Dump(strings.HasPrefix(string(sealed), "DB_PASSWORD=encrypted:"), strings.Contains(string(sealed), "hunter2"))
+//	// #bool true
+//	// #bool false
+func EncryptFile(path, key string) error {
+	secret, err := decodeEnvKey(key)
+	if err != nil {
+		return fmt.Errorf("encrypt env file %s: %w", path, err)
+	}
+	return rewriteEnvFileValues(path, func(entry envEntry) (string, bool, error) {
+		if entry.value == "" || strings.HasPrefix(entry.value, encryptedValuePrefix) {
+			return "", false, nil
+		}
+		if hasEnvReferences(entry) {
+			fmt.Fprintf(envFileWarningWriter, " %s env file warning · %s:%d: %s references other variables and was left unencrypted\n", debugMark(), path, entry.line, entry.key)
+			return "", false, nil
+		}
+		return encryptEnvValue(secret, entry.key, entry.value), true, nil
+	})
+}
+
+// hasEnvReferences reports whether Load would interpolate entry's value. A malformed reference
+// counts too, so the value keeps failing the load rather than being sealed as literal text.
+func hasEnvReferences(entry envEntry) bool {
+	if !entry.expand {
+		return false
+	}
+	referenced := false
+	_, err := expandEnvironmentTemplate(entry.template, func(string) (string, bool, error) {
+		referenced = true
+		return "", true, nil
+	})
+	return referenced || err != nil
+}
+
+// RotateFile re-encrypts every encrypted value in the dotenv file at path from oldKey to newKey.
+// @group Encryption
+// @behavior writes-files
+//
+// Plain values are left alone. The file is only replaced once every value has been decrypted, so a
+// wrong oldKey leaves it unchanged.
+//
+// Example: rotate to a new key
+//
+//	tmp, _ := os.MkdirTemp("", "envdoc")
+//	defer os.RemoveAll(tmp)
+//	secrets := filepath.Join(tmp, ".env.production")
+//	_ = os.WriteFile(secrets, []byte("API_TOKEN=abc123"), 0o600)
+//	oldKey := env.GenerateKey()
+//	newKey := env.GenerateKey()
+//	_ = env.EncryptFile(secrets, oldKey)
+//
+//	env.Dump(env.RotateFile(secrets, oldKey, newKey) == nil, env.RotateFile(secrets, oldKey, newKey) == nil)
+//	// #bool true
+//	// #bool false
+func RotateFile(path, oldKey, newKey string) error {
+	oldSecret, err := decodeEnvKey(oldKey)
+	if err != nil {
+		return fmt.Errorf("rotate env file %s: old key: %w", path, err)
+	}
+	newSecret, err := decodeEnvKey(newKey)
+	if err != nil {
+		return fmt.Errorf("rotate env file %s: new key: %w", path, err)
+	}
+	return rewriteEnvFileValues(path, func(entry envEntry) (string, bool, error) {
+		if !strings.HasPrefix(entry.value, encryptedValuePrefix) {
+			return "", false, nil
+		}
+		plain, err := decryptEnvValue(oldSecret, entry.key, entry.value)
+		if err != nil {
+			return "", false, fmt.Errorf("line %d: %w", entry.line, err)
+		}
+		return encryptEnvValue(newSecret, entry.key, plain), true, nil
+	})
+}
+
+// rewriteEnvFileValues replaces the written value of every entry that rewrite changes, keeping the
+// rest of the file byte for byte, and atomically swaps in the result.
+func rewriteEnvFileValues(path string, rewrite func(envEntry) (string, bool, error)) error {
+	if _, structured := structuredEnvDecoder(path); structured {
+		return fmt.Errorf("rewrite env file %s: only dotenv files can be rewritten", path)
+	}
+	// Rewrite a symlink's target so the link stays in place, as Document.WriteFile does.
+	if err := checkDocumentFile(path); err != nil {
+		return err
+	}
+	target, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return fmt.Errorf("rewrite env file %s: %w", path, err)
+	}
+	info, err := os.Stat(target)
+	if err != nil {
+		return fmt.Errorf("rewrite env file %s: %w", path, err)
+	}
+	data, err := os.ReadFile(target)
+	if err != nil {
+		return fmt.Errorf("rewrite env file %s: %w", path, err)
+	}
+	body := bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	entries, err := parseEnvEntries(body, path, true)
+	if err != nil {
+		return err
+	}
+
+	var rewritten bytes.Buffer
+	rewritten.Write(data[:len(data)-len(body)])
+	written := 0
+	for _, entry := range entries {
+		if entry.include != "" {
+			continue
+		}
+		value, changed, err := rewrite(entry)
+		if err != nil {
+			return fmt.Errorf("rewrite env file %s: %s: %w", path, entry.key, err)
+		}
+		if !changed {
+			continue
+		}
+		rewritten.Write(body[written:entry.valueStart])
+		rewritten.WriteString(value)
+		written = entry.valueEnd
+	}
+	rewritten.Write(body[written:])
+	return replaceEnvFile(target, rewritten.Bytes(), info.Mode().Perm())
+}
+
+// replaceEnvFile writes data beside path and renames it into place so readers never see a partial file.
+func replaceEnvFile(path string, data []byte, mode os.FileMode) error {
+	temporary, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("write env file %s: %w", path, err)
+	}
+	defer os.Remove(temporary.Name())
+	if _, err := temporary.Write(data); err != nil {
+		_ = temporary.Close()
+		return fmt.Errorf("write env file %s: %w", path, err)
+	}
+	if err := temporary.Chmod(mode); err != nil {
+		_ = temporary.Close()
+		return fmt.Errorf("write env file %s: %w", path, err)
+	}
+	if err := temporary.Close(); err != nil {
+		return fmt.Errorf("write env file %s: %w", path, err)
+	}
+	if err := os.Rename(temporary.Name(), path); err != nil {
+		return fmt.Errorf("write env file %s: %w", path, err)
+	}
+	return nil
+}
+
+// decodeEnvKey decodes a standard base64 AES-256 key, ignoring surrounding whitespace.
+func decodeEnvKey(key string) ([]byte, error) {
+	secret, err := base64.StdEncoding.DecodeString(strings.TrimSpace(key))
+	if err != nil {
+		return nil, errors.New("key is not valid base64")
+	}
+	if len(secret) != envKeySize {
+		return nil, fmt.Errorf("key must decode to %d bytes, got %d", envKeySize, len(secret))
+	}
+	return secret, nil
+}
+
+// randomEnvBytes returns n random bytes; crypto/rand.Read never fails as of Go 1.24.
+func randomEnvBytes(n int) []byte {
+	random := make([]byte, n)
+	_, _ = rand.Read(random)
+	return random
+}
+
+// envCipher returns the AES-GCM cipher for a key decodeEnvKey accepted, which cannot fail.
+func envCipher(secret []byte) cipher.AEAD {
+	block, _ := aes.NewCipher(secret)
+	aead, _ := cipher.NewGCM(block)
+	return aead
+}
+
+// encryptEnvValue seals value with a random nonce, authenticating name as additional data.
+func encryptEnvValue(secret []byte, name, value string) string {
+	aead := envCipher(secret)
+	nonce := randomEnvBytes(aead.NonceSize())
+	sealed := aead.Seal(nonce, nonce, []byte(value), []byte(name))
+	return encryptedValuePrefix + base64.StdEncoding.EncodeToString(sealed)
+}
+
+// decryptEnvValue opens a value sealed by encryptEnvValue for name; errors never include the value.
+func decryptEnvValue(secret []byte, name, value string) (string, error) {
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, encryptedValuePrefix))
+	if err != nil {
+		return "", errors.New("encrypted value is not valid base64")
+	}
+	aead := envCipher(secret)
+	if len(sealed) < aead.NonceSize() {
+		return "", errors.New("encrypted value is truncated")
+	}
+	plain, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], []byte(name))
+	if err != nil {
+		return "", errors.New("encrypted value cannot be decrypted with this key")
+	}
+	return string(plain), nil
+}
+
+// decryptEnvironmentPlan decrypts every encrypted value the plan will apply, before interpolation.
+//
+// The key is only resolved when a value needs it: ENV_KEY from the ambient environment, then the
+// file named by ENV_KEY_FILE, then the nearest .env.key, which Watch tracks. Decrypted values lose
+// their interpolation template so a secret containing '$' stays literal.
+func decryptEnvironmentPlan(
+	plan *environmentLoadPlan,
+	startDirectory string,
+	previous map[string]loadedEnvironmentValue,
+) error {
+	var keys []string
+	for key, value := range plan.fileValues {
+		if strings.HasPrefix(value, encryptedValuePrefix) {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	sort.Strings(keys)
+
+	secret, err := resolveEnvKey(plan, startDirectory, previous)
+	if err != nil {
+		return fmt.Errorf("decrypt %s from %s: %w", keys[0], plan.sources[keys[0]], err)
+	}
+	for _, key := range keys {
+		plain, err := decryptEnvValue(secret, key, plan.fileValues[key])
+		if err != nil {
+			return fmt.Errorf("decrypt %s from %s: %w", key, plan.sources[key], err)
+		}
+		plan.fileValues[key] = plain
+		delete(plan.templates, key)
+	}
+	return nil
+}
+
+// resolveEnvKey finds and decodes the key for decryptEnvironmentPlan.
+func resolveEnvKey(
+	plan *environmentLoadPlan,
+	startDirectory string,
+	previous map[string]loadedEnvironmentValue,
+) ([]byte, error) {
+	if key := originalEnvironmentSnapshot(envKeyVariable, previous, plan.lookup); key.present {
+		secret, err := decodeEnvKey(key.value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", envKeyVariable, err)
+		}
+		return secret, nil
+	}
+
+	path, err := findEnvKeyFile(plan, startDirectory, previous)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read env key file: %w", err)
+	}
+	secret, err := decodeEnvKey(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("env key file %s: %w", path, err)
+	}
+	return secret, nil
+}
+
+// findEnvKeyFile returns the file named by ENV_KEY_FILE, relative to startDirectory, or the nearest
+// .env.key, and records it for Watch.
+func findEnvKeyFile(
+	plan *environmentLoadPlan,
+	startDirectory string,
+	previous map[string]loadedEnvironmentValue,
+) (string, error) {
+	if keyFile := originalEnvironmentSnapshot(envKeyFileVariable, previous, plan.lookup); keyFile.present {
+		path := keyFile.value
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(startDirectory, path)
+		}
+		plan.watched = append(plan.watched, path)
+		return path, nil
+	}
+	path, found, err := findEnvFile(startDirectory, envFileKey)
+	plan.watched = append(plan.watched, envFileCandidates(startDirectory, envFileKey, path)...)
+	if err != nil {
+		return "", err
+	}
+	if !found {
+		return "", fmt.Errorf("no key: set %s or %s, or add %s", envKeyVariable, envKeyFileVariable, envFileKey)
+	}
+	return path, nil
+}
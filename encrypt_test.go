@@ -0,0 +1,328 @@
+package env
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestEncryptFileKeepsLayout ensures only plain values change and each decrypts back under its own name.
+func TestEncryptFileKeepsLayout(t *testing.T) {
+	key := GenerateKey()
+	secret, err := decodeEnvKey(key)
+	if err != nil {
+		t.Fatalf("decode key: %v", err)
+	}
+	sealed := encryptEnvValue(secret, "KEPT", "already")
+	path := filepath.Join(t.TempDir(), ".env.production")
+	original := "\xef\xbb\xbf# credentials\nexport PASSWORD=hunter2 # rotate\nQUOTED=\"two\\nlines\"\nEMPTY=\nKEPT=" + sealed + "\n#include? shared.env\n"
+	if err := os.WriteFile(path, []byte(original), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	if err := EncryptFile(path, key); err != nil {
+		t.Fatalf("EncryptFile: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	text := string(data)
+	for _, want := range []string{"\xef\xbb\xbf# credentials\nexport PASSWORD=encrypted:", " # rotate\nQUOTED=encrypted:", "\nEMPTY=\nKEPT=" + sealed + "\n#include? shared.env\n"} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("expected %q in %q", want, text)
+		}
+	}
+	if strings.Contains(text, "hunter2") || strings.Contains(text, "lines") {
+		t.Fatalf("expected plain values to be sealed, got %q", text)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o600 {
+		t.Fatalf("expected permissions to be kept, got %v %v", info, err)
+	}
+
+	entries, err := parseEnvEntries(data[3:], path, true)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	want := map[string]string{"PASSWORD": "hunter2", "QUOTED": "two\nlines", "KEPT": "already"}
+	for _, entry := range entries {
+		if expected, ok := want[entry.key]; ok {
+			if plain, err := decryptEnvValue(secret, entry.key, entry.value); err != nil || plain != expected {
+				t.Fatalf("expected %s to decrypt to %q, got %q %v", entry.key, expected, plain, err)
+			}
+		}
+	}
+	if _, err := decryptEnvValue(secret, "OTHER", sealed); err == nil {
+		t.Fatal("expected a value moved to another key to fail authentication")
+	}
+}
+
+// TestEncryptFileSkipsReferences ensures values that Load would interpolate stay plain, with a warning, and still expand.
+func TestEncryptFileSkipsReferences(t *testing.T) {
+	prepareLoaderTest(t, "ENV_QPASS_DSN", "ENV_QPASS_USER", "ENV_QPASS_HOME", "ENV_QPASS_LITERAL", "ENV_QPASS_PRICE", envKeyVariable, envKeyFileVariable)
+	t.Setenv("APP_ENV", Local)
+	for _, key := range []string{"ENV_QPASS_DSN", "ENV_QPASS_USER", "ENV_QPASS_HOME", "ENV_QPASS_LITERAL", "ENV_QPASS_PRICE", envKeyFileVariable} {
+		_ = os.Unsetenv(key)
+	}
+	var warnings bytes.Buffer
+	envFileWarningWriter = &warnings
+	key := GenerateKey()
+	t.Setenv(envKeyVariable, key)
+	directory := t.TempDir()
+	path := filepath.Join(directory, fileEnv)
+	writeEnvFile(t, directory, fileEnv, "ENV_QPASS_USER=app\nENV_QPASS_DSN=\"postgres://${ENV_QPASS_USER}@db\"\nENV_QPASS_HOME=$ENV_QPASS_USER/home\nENV_QPASS_LITERAL='${ENV_QPASS_USER}'\nENV_QPASS_PRICE=\"\\$5\"\n")
+	if err := EncryptFile(path, key); err != nil {
+		t.Fatalf("EncryptFile: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	text := string(data)
+	for _, want := range []string{"ENV_QPASS_USER=encrypted:", "\nENV_QPASS_DSN=\"postgres://${ENV_QPASS_USER}@db\"\nENV_QPASS_HOME=$ENV_QPASS_USER/home\nENV_QPASS_LITERAL=encrypted:", "\nENV_QPASS_PRICE=encrypted:"} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("expected %q in %q", want, text)
+		}
+	}
+	output := warnings.String()
+	for _, want := range []string{":2: ENV_QPASS_DSN references other variables", ":3: ENV_QPASS_HOME references other variables"} {
+		if !strings.Contains(output, want) {
+			t.Fatalf("expected warning %q, got %q", want, output)
+		}
+	}
+	if strings.Contains(output, "LITERAL") || strings.Contains(output, "PRICE") || strings.Contains(output, "postgres") {
+		t.Fatalf("expected value-free warnings for referencing values only, got %q", output)
+	}
+
+	changeWorkingDirectory(t, directory)
+	if err := Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	want := map[string]string{
+		"ENV_QPASS_DSN":     "postgres://app@db",
+		"ENV_QPASS_HOME":    "app/home",
+		"ENV_QPASS_LITERAL": "${ENV_QPASS_USER}",
+		"ENV_QPASS_PRICE":   "$5",
+	}
+	for key, expected := range want {
+		if got := os.Getenv(key); got != expected {
+			t.Fatalf("expected %s=%q, got %q", key, expected, got)
+		}
+	}
+}
+
+// TestLoadDecryptsEncryptedValues ensures winning encrypted values decrypt before interpolation and stay literal.
+func TestLoadDecryptsEncryptedValues(t *testing.T) {
+	prepareLoaderTest(t, "ENV_QPASS_SECRET", "ENV_QPASS_DSN", envKeyVariable, envKeyFileVariable)
+	t.Setenv("APP_ENV", Production)
+	_ = os.Unsetenv("ENV_QPASS_SECRET")
+	_ = os.Unsetenv("ENV_QPASS_DSN")
+	_ = os.Unsetenv(envKeyFileVariable)
+	key := GenerateKey()
+	t.Setenv(envKeyVariable, key)
+	directory := t.TempDir()
+	writeEnvFile(t, directory, fileEnv, "ENV_QPASS_DSN=postgres://app:${ENV_QPASS_SECRET}@db\n")
+	writeEnvFile(t, directory, envFileProd, "ENV_QPASS_SECRET='pa$$word'\n")
+	if err := EncryptFile(filepath.Join(directory, envFileProd), key); err != nil {
+		t.Fatalf("EncryptFile: %v", err)
+	}
+	changeWorkingDirectory(t, directory)
+
+	if err := Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if got := os.Getenv("ENV_QPASS_SECRET"); got != "pa$$word" {
+		t.Fatalf("expected the literal decrypted value, got %q", got)
+	}
+	if got := os.Getenv("ENV_QPASS_DSN"); got != "postgres://app:pa$$word@db" {
+		t.Fatalf("expected interpolation to see the decrypted value, got %q", got)
+	}
+
+	_ = os.Unsetenv(envKeyVariable)
+	if err := os.WriteFile(filepath.Join(directory, "keys"), []byte(key+"\n"), 0o600); err != nil {
+		t.Fatalf("write key file: %v", err)
+	}
+	t.Setenv(envKeyFileVariable, "keys")
+	if err := Reload(); err != nil {
+		t.Fatalf("Reload with %s: %v", envKeyFileVariable, err)
+	}
+	_ = os.Unsetenv(envKeyFileVariable)
+	writeEnvFile(t, directory, envFileKey, key)
+	if err := Reload(); err != nil {
+		t.Fatalf("Reload with %s: %v", envFileKey, err)
+	}
+	if got := os.Getenv("ENV_QPASS_SECRET"); got != "pa$$word" {
+		t.Fatalf("expected the value to stay decrypted, got %q", got)
+	}
+}
+
+// TestLoadFailsTransactionallyOnDecryptionErrors ensures a missing or wrong key aborts the load without exposing values.
+func TestLoadFailsTransactionallyOnDecryptionErrors(t *testing.T) {
+	prepareLoaderTest(t, "ENV_QPASS_SECRET", "ENV_QPASS_PLAIN", envKeyVariable, envKeyFileVariable)
+	t.Setenv("APP_ENV", Local)
+	_ = os.Unsetenv("ENV_QPASS_SECRET")
+	_ = os.Unsetenv("ENV_QPASS_PLAIN")
+	_ = os.Unsetenv(envKeyVariable)
+	_ = os.Unsetenv(envKeyFileVariable)
+	directory := t.TempDir()
+	writeEnvFile(t, directory, fileEnv, "ENV_QPASS_PLAIN=plain\nENV_QPASS_SECRET=hunter2\n")
+	if err := EncryptFile(filepath.Join(directory, fileEnv), GenerateKey()); err != nil {
+		t.Fatalf("EncryptFile: %v", err)
+	}
+	changeWorkingDirectory(t, directory)
+
+	err := Load()
+	if err == nil || !strings.Contains(err.Error(), "no key") {
+		t.Fatalf("expected a missing key error, got %v", err)
+	}
+	t.Setenv(envKeyVariable, GenerateKey())
+	err = Load()
+	if err == nil || !strings.Contains(err.Error(), "decrypt ENV_QPASS") || strings.Contains(err.Error(), "hunter2") {
+		t.Fatalf("expected a value-free decryption error, got %v", err)
+	}
+	if _, ok := os.LookupEnv("ENV_QPASS_PLAIN"); ok || IsEnvLoaded() {
+		t.Fatal("expected a failed decryption to leave the environment and loader unchanged")
+	}
+}
+
+// TestRotateFileReplacesKey ensures rotation needs the old key and leaves the file untouched on failure.
+func TestRotateFileReplacesKey(t *testing.T) {
+	oldKey, newKey := GenerateKey(), GenerateKey()
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte("TOKEN=abc\nPLAIN=visible\n"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := EncryptFile(path, oldKey); err != nil {
+		t.Fatalf("EncryptFile: %v", err)
+	}
+	if err := RotateFile(path, oldKey, newKey); err != nil {
+		t.Fatalf("RotateFile: %v", err)
+	}
+	rotated, _ := os.ReadFile(path)
+	if err := RotateFile(path, oldKey, newKey); err == nil {
+		t.Fatal("expected the old key to stop working")
+	}
+	if unchanged, _ := os.ReadFile(path); string(unchanged) != string(rotated) {
+		t.Fatal("expected a failed rotation to leave the file unchanged")
+	}
+
+	secret, _ := decodeEnvKey(newKey)
+	entries, err := parseEnvEntries(rotated, path, true)
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("parse rotated file: %v", err)
+	}
+	for index, want := range []string{"abc", "visible"} {
+		if plain, err := decryptEnvValue(secret, entries[index].key, entries[index].value); err != nil || plain != want {
+			t.Fatalf("expected %s to decrypt with the new key, got %q %v", entries[index].key, plain, err)
+		}
+	}
+	if err := EncryptFile(path, "short"); err == nil {
+		t.Fatal("expected an invalid key to be rejected")
+	}
+}
+
+// TestEncryptFileFollowsSymlinks ensures encryption and rotation rewrite a symlink's target and keep the link.
+func TestEncryptFileFollowsSymlinks(t *testing.T) {
+	oldKey, newKey := GenerateKey(), GenerateKey()
+	directory := t.TempDir()
+	path := filepath.Join(directory, fileEnv)
+	writeEnvFile(t, directory, "secrets.env", "TOKEN=hunter2\n")
+	if err := os.Symlink("secrets.env", path); err != nil {
+		t.Fatal(err)
+	}
+
+	steps := []struct {
+		name    string
+		rewrite func() error
+	}{
+		{name: "EncryptFile", rewrite: func() error { return EncryptFile(path, oldKey) }},
+		{name: "RotateFile", rewrite: func() error { return RotateFile(path, oldKey, newKey) }},
+	}
+	for _, step := range steps {
+		name := step.name
+		if err := step.rewrite(); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if target, err := os.Readlink(path); err != nil || target != "secrets.env" {
+			t.Fatalf("%s: expected the symlink to stay, got %q %v", name, target, err)
+		}
+		data, err := os.ReadFile(filepath.Join(directory, "secrets.env"))
+		if err != nil || !strings.HasPrefix(string(data), "TOKEN=encrypted:") {
+			t.Fatalf("%s: expected the target to be sealed, got %q %v", name, data, err)
+		}
+	}
+	secret, _ := decodeEnvKey(newKey)
+	data, _ := os.ReadFile(path)
+	if plain, err := decryptEnvValue(secret, "TOKEN", strings.TrimSpace(strings.TrimPrefix(string(data), "TOKEN="))); err != nil || plain != "hunter2" {
+		t.Fatalf("expected the target to decrypt with the new key, got %q %v", plain, err)
+	}
+}
+
+// TestEncryptionRejectsInvalidInput ensures bad keys, files, and ciphertext fail with descriptive errors.
+func TestEncryptionRejectsInvalidInput(t *testing.T) {
+	key := GenerateKey()
+	secret, _ := decodeEnvKey(key)
+	directory := t.TempDir()
+	broken := filepath.Join(directory, "broken.env")
+	if err := os.WriteFile(broken, []byte("A=1\nBROKEN LINE\n"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		err     error
+		message string
+	}{
+		{name: "structured", err: EncryptFile(filepath.Join(directory, "config.yaml"), key), message: "only dotenv files"},
+		{name: "missing", err: EncryptFile(filepath.Join(directory, "missing.env"), key), message: "missing.env"},
+		{name: "directory", err: EncryptFile(directory, key), message: "is not a regular file"},
+		{name: "syntax", err: EncryptFile(broken, key), message: "expected '=' after key BROKEN"},
+		{name: "base64 key", err: EncryptFile(broken, "not base64!"), message: "not valid base64"},
+		{name: "old key", err: RotateFile(broken, "c2hvcnQ=", key), message: "old key: key must decode to 32 bytes, got 5"},
+		{name: "new key", err: RotateFile(broken, key, ""), message: "new key"},
+	}
+	for _, test := range tests {
+		if test.err == nil || !strings.Contains(test.err.Error(), test.message) {
+			t.Fatalf("%s: expected an error containing %q, got %v", test.name, test.message, test.err)
+		}
+	}
+
+	for value, message := range map[string]string{
+		"encrypted:%%%":  "not valid base64",
+		"encrypted:YWJj": "truncated",
+	} {
+		if _, err := decryptEnvValue(secret, "A", value); err == nil || !strings.Contains(err.Error(), message) {
+			t.Fatalf("expected %q to fail with %q, got %v", value, message, err)
+		}
+	}
+}
+
+// TestLoadRejectsInvalidKeySources ensures malformed ENV_KEY values and unreadable key files fail the load.
+func TestLoadRejectsInvalidKeySources(t *testing.T) {
+	prepareLoaderTest(t, "ENV_QPASS_SECRET", envKeyVariable, envKeyFileVariable)
+	t.Setenv("APP_ENV", Local)
+	_ = os.Unsetenv("ENV_QPASS_SECRET")
+	_ = os.Unsetenv(envKeyVariable)
+	_ = os.Unsetenv(envKeyFileVariable)
+	directory := t.TempDir()
+	writeEnvFile(t, directory, fileEnv, "ENV_QPASS_SECRET=hunter2\n")
+	if err := EncryptFile(filepath.Join(directory, fileEnv), GenerateKey()); err != nil {
+		t.Fatalf("EncryptFile: %v", err)
+	}
+	changeWorkingDirectory(t, directory)
+
+	writeEnvFile(t, directory, envFileKey, "short")
+	if err := Load(); err == nil || !strings.Contains(err.Error(), "env key file") {
+		t.Fatalf("expected an invalid key file error, got %v", err)
+	}
+	t.Setenv(envKeyFileVariable, filepath.Join(directory, "missing.key"))
+	if err := Load(); err == nil || !strings.Contains(err.Error(), "read env key file") {
+		t.Fatalf("expected a missing key file error, got %v", err)
+	}
+	t.Setenv(envKeyVariable, "c2hvcnQ=")
+	if err := Load(); err == nil || !strings.Contains(err.Error(), envKeyVariable+": key must decode") {
+		t.Fatalf("expected an invalid %s error, got %v", envKeyVariable, err)
+	}
+}
@@ -28,7 +28,11 @@ import (
 //	env.Dump(host)
 //	// #string "db.internal"
 func Get(key, fallback string) string {
-	val := os.Getenv(key)
+	return stringValue(os.Getenv(key), fallback)
+}
+
+// stringValue applies Get's empty-means-missing rule to a value from any source.
+func stringValue(val, fallback string) string {
 	if len(val) == 0 {
 		return fallback
 	}
@@ -53,7 +57,11 @@ func Get(key, fallback string) string {
 //	env.Dump(port)
 //	// #int 8080
 func GetInt(key, fallback string) int {
-	val := os.Getenv(key)
+	return intValue(os.Getenv(key), fallback)
+}
+
+// intValue parses val, then fallback, without consulting process state.
+func intValue(val, fallback string) int {
 	if val != "" {
 		if ret, err := strconv.Atoi(val); err == nil {
 			return ret
@@ -85,7 +93,11 @@ func GetInt(key, fallback string) int {
 //	env.Dump(size)
 //	// #int64 512
 func GetInt64(key, fallback string) int64 {
-	val := os.Getenv(key)
+	return int64Value(os.Getenv(key), fallback)
+}
+
+// int64Value parses val, then fallback, without consulting process state.
+func int64Value(val, fallback string) int64 {
 	if val != "" {
 		if ret, err := strconv.ParseInt(val, 10, 64); err == nil {
 			return ret
@@ -117,7 +129,11 @@ func GetInt64(key, fallback string) int64 {
 //	env.Dump(workers)
 //	// #uint 16
 func GetUint(key, fallback string) uint {
-	val := os.Getenv(key)
+	return uintValue(os.Getenv(key), fallback)
+}
+
+// uintValue parses val, then fallback, without consulting process state.
+func uintValue(val, fallback string) uint {
 	if val != "" {
 		if i, err := strconv.ParseUint(val, 10, bits.UintSize); err == nil {
 			return uint(i)
@@ -149,7 +165,11 @@ func GetUint(key, fallback string) uint {
 //	env.Dump(maxItems)
 //	// #uint64 100
 func GetUint64(key, fallback string) uint64 {
-	val := os.Getenv(key)
+	return uint64Value(os.Getenv(key), fallback)
+}
+
+// uint64Value parses val, then fallback, without consulting process state.
+func uint64Value(val, fallback string) uint64 {
 	if val != "" {
 		if i, err := strconv.ParseUint(val, 10, 64); err == nil {
 			return i
@@ -181,7 +201,11 @@ func GetUint64(key, fallback string) uint64 {
 //	env.Dump(threshold)
 //	// #float64 0.75
 func GetFloat(key, fallback string) float64 {
-	val := os.Getenv(key)
+	return floatValue(os.Getenv(key), fallback)
+}
+
+// floatValue parses val, then fallback, without consulting process state.
+func floatValue(val, fallback string) float64 {
 	if val != "" {
 		if f, err := strconv.ParseFloat(val, 64); err == nil {
 			return f
@@ -216,7 +240,11 @@ func GetFloat(key, fallback string) float64 {
 //	env.Dump(debug)
 //	// #bool false
 func GetBool(key, fallback string) bool {
-	val := os.Getenv(key)
+	return boolValue(os.Getenv(key), fallback)
+}
+
+// boolValue parses val, then fallback, without consulting process state.
+func boolValue(val, fallback string) bool {
 	if val != "" {
 		if ret, err := strconv.ParseBool(val); err == nil {
 			return ret
@@ -248,7 +276,11 @@ func GetBool(key, fallback string) bool {
 //	env.Dump(timeout)
 //	// #time.Duration 5s
 func GetDuration(key, fallback string) time.Duration {
-	val := os.Getenv(key)
+	return durationValue(os.Getenv(key), fallback)
+}
+
+// durationValue parses val, then fallback, without consulting process state.
+func durationValue(val, fallback string) time.Duration {
 	if val != "" {
 		if d, err := time.ParseDuration(val); err == nil {
 			return d
@@ -283,7 +315,11 @@ func GetDuration(key, fallback string) time.Duration {
 //	env.Dump(peers)
 //	// #[]string []
 func GetSlice(key, fallback string) []string {
-	val := Get(key, fallback)
+	return sliceValue(Get(key, fallback))
+}
+
+// sliceValue applies GetSlice's comma-separated format without consulting process state.
+func sliceValue(val string) []string {
 	if val == "" {
 		return []string{}
 	}
@@ -371,7 +407,11 @@ func parseStringMap(val string) map[string]string {
 //	//  "misc"     => 2 #int
 //	// ]
 func GetMapInt(key, fallback string, defaultValue int) map[string]int {
-	val := Get(key, fallback)
+	return mapIntValue(Get(key, fallback), defaultValue)
+}
+
+// mapIntValue applies GetMapInt's permissive format without consulting process state.
+func mapIntValue(val string, defaultValue int) map[string]int {
 	m := map[string]int{}
 
 	if defaultValue <= 0 {
@@ -428,7 +468,11 @@ func GetMapInt(key, fallback string, defaultValue int) map[string]int {
 //	env.Dump(appEnv)
 //	// #string "local"
 func GetEnum(key, fallback string, allowed []string) string {
-	val := Get(key, fallback)
+	return enumValue(Get(key, fallback), fallback, allowed)
+}
+
+// enumValue returns val when allowed and fallback otherwise.
+func enumValue(val, fallback string, allowed []string) string {
 	for _, a := range allowed {
 		if val == a {
 			return val
@@ -453,7 +497,11 @@ func GetEnum(key, fallback string, allowed []string) string {
 //	os.Unsetenv("API_SECRET")
 //	secret = env.MustGet("API_SECRET") // panics: env variable missing: API_SECRET
 func MustGet(key string) string {
-	val := os.Getenv(key)
+	return mustValue(key, os.Getenv(key))
+}
+
+// mustValue panics with MustGet's message when val is empty.
+func mustValue(key, val string) string {
 	if val == "" {
 		panic("env variable missing: " + key)
 	}
@@ -476,7 +524,12 @@ func MustGet(key string) string {
 //	_ = os.Setenv("PORT", "not-a-number")
 //	_ = env.MustGetInt("PORT") // panics when parsing
 func MustGetInt(key string) int {
-	value := MustGet(key)
+	return mustIntValue(key, os.Getenv(key))
+}
+
+// mustIntValue panics with MustGetInt's messages when val is missing or invalid.
+func mustIntValue(key, val string) int {
+	value := mustValue(key, val)
 	parsed, err := strconv.Atoi(value)
 	if err != nil {
 		panic("env variable is not an int: " + key)
@@ -500,7 +553,12 @@ func MustGetInt(key string) int {
 //	_ = os.Setenv("FEATURE_ENABLED", "maybe")
 //	_ = env.MustGetBool("FEATURE_ENABLED") // panics when parsing
 func MustGetBool(key string) bool {
-	value := MustGet(key)
+	return mustBoolValue(key, os.Getenv(key))
+}
+
+// mustBoolValue panics with MustGetBool's messages when val is missing or invalid.
+func mustBoolValue(key, val string) bool {
+	value := mustValue(key, val)
 	parsed, err := strconv.ParseBool(value)
 	if err != nil {
 		panic("env variable is not a bool: " + key)
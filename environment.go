@@ -0,0 +1,312 @@
+package env
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// LoadIntoOptions configures LoadInto.
+type LoadIntoOptions struct {
+	// Environ lists ambient KEY=value entries, in os.Environ format, that take precedence over
+	// files exactly like inherited process variables do for Load. Nil means no ambient values;
+	// pass os.Environ() to mirror Load.
+	Environ []string
+	// Compose adds docker-compose service environments, as LoadCompose does for Load. Relative
+	// files resolve against the directory passed to LoadInto.
+	Compose []ComposeService
+}
+
+// Environment is an immutable set of variables resolved by LoadInto, with the file each came from.
+type Environment struct {
+	values  map[string]string
+	sources map[string]string
+	files   []string
+	appEnv  string
+}
+
+// LoadInto resolves the env files for dir into an isolated Environment without changing the process.
+// @group Isolated environment
+// @behavior readonly
+//
+// LoadInto applies the same discovery and layering rules as Load: .env, the APP_ENV layer, .env.host,
+// and .env.testing, each searched from dir through at most nine ancestors. Ambient values from
+// options.Environ win over files and may select APP_ENV. An empty dir uses the working directory.
+// The process environment is neither read nor written, and the values Load applied are left
+// alone, so LoadInto is safe for libraries, parallel tests, and tools that inspect several
+// projects. Process-wide configuration still shapes the plan as it does for Load: SetLayerOptions,
+// RegisterAppEnv, and RegisterRuntimeLayer, runtime detection such as IsDocker, and whether the
+// binary is running tests.
+//
+// Example: inspect a project without mutating the process
+//
+//	tmp, _ := os.MkdirTemp("", "envdoc")
+//	defer os.RemoveAll(tmp)
+//	_ = os.WriteFile(filepath.Join(tmp, ".env"), []byte("APP_ENV=production\nPORT=8080"), 0o644)
+//	_ = os.WriteFile(filepath.Join(tmp, ".env.production"), []byte("PORT=80"), 0o644)
+//
+//	environment, _ := env.LoadInto(tmp, env.LoadIntoOptions{})
+//	env.Dump(environment.AppEnv(), environment.GetInt("PORT", "3000"))
+//	// #string "production"
+//	// #int 80
+func LoadInto(dir string, options LoadIntoOptions) (*Environment, error) {
+	if dir == "" {
+		workingDirectory, err := envFileGetwd()
+		if err != nil {
+			return nil, fmt.Errorf("get working directory for env loading: %w", err)
+		}
+		dir = workingDirectory
+	}
+	startDirectory, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("resolve env directory %s: %w", dir, err)
+	}
+
+	ambient := environValues(options.Environ)
+	lookup := func(key string) (string, bool) {
+		value, ok := ambient[key]
+		return value, ok
+	}
+	startDirectory = filepath.Clean(startDirectory)
+	sources := environmentSources{compose: resolveComposeServices(startDirectory, options.Compose)}
+	plan, err := buildEnvironmentLoadPlan(startDirectory, sources, nil, nil, lookup)
+	if err != nil {
+		return nil, err
+	}
+	return newEnvironment(ambient, plan), nil
+}
+
+// environValues parses os.Environ-style entries; later duplicates win like they do for exec.Cmd.Env.
+func environValues(environ []string) map[string]string {
+	values := make(map[string]string, len(environ))
+	for _, entry := range environ {
+		key, value, ok := strings.Cut(entry, "=")
+		if !ok || key == "" {
+			continue
+		}
+		values[key] = value
+	}
+	return values
+}
+
+// newEnvironment layers a plan over its ambient values with the same precedence Load applies.
+func newEnvironment(ambient map[string]string, plan environmentLoadPlan) *Environment {
+	environment := &Environment{
+		values:  make(map[string]string, len(ambient)+len(plan.fileValues)+len(plan.defaults)),
+		sources: make(map[string]string, len(plan.sources)),
+		files:   append([]string(nil), plan.files...),
+		appEnv:  plan.appEnv,
+	}
+	for key, value := range plan.defaults {
+		environment.values[key] = value
+	}
+	for key, value := range ambient {
+		environment.values[key] = value
+	}
+	for key, value := range plan.fileValues {
+		environment.values[key] = value
+		environment.sources[key] = plan.sources[key]
+	}
+	return environment
+}
+
+// Lookup returns the value for key and whether it is present.
+// @group Isolated environment
+// @behavior readonly
+func (e *Environment) Lookup(key string) (string, bool) {
+	value, ok := e.values[key]
+	return value, ok
+}
+
+// Keys returns every variable name in lexical order.
+// @group Isolated environment
+// @behavior readonly
+func (e *Environment) Keys() []string {
+	keys := make([]string, 0, len(e.values))
+	for key := range e.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Values returns a copy of every variable.
+// @group Isolated environment
+// @behavior readonly
+func (e *Environment) Values() map[string]string {
+	values := make(map[string]string, len(e.values))
+	for key, value := range e.values {
+		values[key] = value
+	}
+	return values
+}
+
+// Environ returns the variables as sorted KEY=value entries suitable for exec.Cmd.Env.
+// @group Isolated environment
+// @behavior readonly
+func (e *Environment) Environ() []string {
+	keys := e.Keys()
+	environ := make([]string, 0, len(keys))
+	for _, key := range keys {
+		environ = append(environ, key+"="+e.values[key])
+	}
+	return environ
+}
+
+// Source returns the env file that supplied key.
+// @group Isolated environment
+// @behavior readonly
+//
+// The result is empty when key came from the ambient environment, a loader default, or is absent.
+//
+// Example: trace a value to its file
+//
+//	tmp, _ := os.MkdirTemp("", "envdoc")
+//	defer os.RemoveAll(tmp)
+//	_ = os.WriteFile(filepath.Join(tmp, ".env"), []byte("PORT=8080"), 0o644)
+//
+//	environment, _ := env.LoadInto(tmp, env.LoadIntoOptions{})
+//	env.Dump(filepath.Base(environment.Source("PORT")))
+//	// #string ".env"
+func (e *Environment) Source(key string) string {
+	return e.sources[key]
+}
+
+// Files returns the env files applied, in layer order.
+// @group Isolated environment
+// @behavior readonly
+func (e *Environment) Files() []string {
+	return append([]string(nil), e.files...)
+}
+
+// AppEnv returns the APP_ENV the layers were selected for.
+// @group Isolated environment
+// @behavior readonly
+func (e *Environment) AppEnv() string {
+	return e.appEnv
+}
+
+// WithPrefix returns a scope rooted at prefix that reads this environment.
+// @group Isolated environment
+// @behavior readonly
+//
+// Example: scoped access to an isolated environment
+//
+//	tmp, _ := os.MkdirTemp("", "envdoc")
+//	defer os.RemoveAll(tmp)
+//	_ = os.WriteFile(filepath.Join(tmp, ".env"), []byte("STORAGE_PUBLIC_ROOT=storage/app/public"), 0o644)
+//
+//	environment, _ := env.LoadInto(tmp, env.LoadIntoOptions{})
+//	public := environment.WithPrefix("STORAGE").Child("PUBLIC")
+//	env.Dump(public.Get("ROOT", ""))
+//	// #string "storage/app/public"
+func (e *Environment) WithPrefix(prefix string) Scope {
+	return Scope{prefix: normalizeScopeSegment(prefix), environment: e}
+}
+
+// Get returns the value for key or fallback when empty.
+// @group Isolated environment
+// @behavior readonly
+func (e *Environment) Get(key, fallback string) string {
+	return stringValue(e.values[key], fallback)
+}
+
+// GetInt parses an int from key or fallback.
+// @group Isolated environment
+// @behavior readonly
+func (e *Environment) GetInt(key, fallback string) int {
+	return intValue(e.values[key], fallback)
+}
+
+// GetInt64 parses an int64 from key or fallback.
+// @group Isolated environment
+// @behavior readonly
+func (e *Environment) GetInt64(key, fallback string) int64 {
+	return int64Value(e.values[key], fallback)
+}
+
+// GetUint parses a uint from key or fallback.
+// @group Isolated environment
+// @behavior readonly
+func (e *Environment) GetUint(key, fallback string) uint {
+	return uintValue(e.values[key], fallback)
+}
+
+// GetUint64 parses a uint64 from key or fallback.
+// @group Isolated environment
+// @behavior readonly
+func (e *Environment) GetUint64(key, fallback string) uint64 {
+	return uint64Value(e.values[key], fallback)
+}
+
+// GetFloat parses a float64 from key or fallback.
+// @group Isolated environment
+// @behavior readonly
+func (e *Environment) GetFloat(key, fallback string) float64 {
+	return floatValue(e.values[key], fallback)
+}
+
+// GetBool parses a bool from key or fallback.
+// @group Isolated environment
+// @behavior readonly
+func (e *Environment) GetBool(key, fallback string) bool {
+	return boolValue(e.values[key], fallback)
+}
+
+// GetDuration parses a Go duration from key or fallback.
+// @group Isolated environment
+// @behavior readonly
+func (e *Environment) GetDuration(key, fallback string) time.Duration {
+	return durationValue(e.values[key], fallback)
+}
+
+// GetSlice splits the comma-separated value of key or fallback.
+// @group Isolated environment
+// @behavior readonly
+func (e *Environment) GetSlice(key, fallback string) []string {
+	return sliceValue(e.Get(key, fallback))
+}
+
+// GetMap parses comma-separated key=value pairs from key or fallback.
+// @group Isolated environment
+// @behavior readonly
+func (e *Environment) GetMap(key, fallback string) map[string]string {
+	return parseStringMap(e.Get(key, fallback))
+}
+
+// GetMapInt parses comma-separated key=int pairs from key or fallback.
+// @group Isolated environment
+// @behavior readonly
+func (e *Environment) GetMapInt(key, fallback string, defaultValue int) map[string]int {
+	return mapIntValue(e.Get(key, fallback), defaultValue)
+}
+
+// GetEnum returns the value of key when allowed and fallback otherwise.
+// @group Isolated environment
+// @behavior readonly
+func (e *Environment) GetEnum(key, fallback string, allowed []string) string {
+	return enumValue(e.Get(key, fallback), fallback, allowed)
+}
+
+// MustGet returns the value of key or panics if missing or empty.
+// @group Isolated environment
+// @behavior panic
+func (e *Environment) MustGet(key string) string {
+	return mustValue(key, e.values[key])
+}
+
+// MustGetInt returns a required int or panics when the value is missing or invalid.
+// @group Isolated environment
+// @behavior panic
+func (e *Environment) MustGetInt(key string) int {
+	return mustIntValue(key, e.values[key])
+}
+
+// MustGetBool returns a required bool or panics when the value is missing or invalid.
+// @group Isolated environment
+// @behavior panic
+func (e *Environment) MustGetBool(key string) bool {
+	return mustBoolValue(key, e.values[key])
+}
@@ -0,0 +1,163 @@
+package env
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// TestLoadIntoLayersWithoutMutatingProcess ensures isolated loads follow Load precedence and leave the process alone.
+func TestLoadIntoLayersWithoutMutatingProcess(t *testing.T) {
+	prepareLoaderTest(t, "ENV_QPASS_BASE", "ENV_QPASS_SHARED", "ENV_QPASS_AMBIENT")
+	_ = os.Unsetenv("APP_ENV")
+	_ = os.Unsetenv("ENV_QPASS_BASE")
+	_ = os.Unsetenv("ENV_QPASS_SHARED")
+	_ = os.Unsetenv("ENV_QPASS_AMBIENT")
+	directory := t.TempDir()
+	writeEnvFile(t, directory, fileEnv, "APP_ENV=staging\nENV_QPASS_BASE=base\nENV_QPASS_SHARED=base\nENV_QPASS_AMBIENT=file\n")
+	writeEnvFile(t, directory, envFileStaging, "ENV_QPASS_SHARED=staging\n")
+	changeWorkingDirectoryWithin(t, t.TempDir(), directory)
+	writes := 0
+	envSet = func(key, value string) error {
+		writes++
+		return os.Setenv(key, value)
+	}
+
+	environment, err := LoadInto(directory, LoadIntoOptions{Environ: []string{"ENV_QPASS_AMBIENT=ambient"}})
+	if err != nil {
+		t.Fatalf("LoadInto: %v", err)
+	}
+
+	want := map[string]string{
+		"APP_ENV":           Staging,
+		"ENV_QPASS_BASE":    "base",
+		"ENV_QPASS_SHARED":  "staging",
+		"ENV_QPASS_AMBIENT": "ambient",
+	}
+	if got := environment.Values(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected values %v, got %v", want, got)
+	}
+	if got := environment.Source("ENV_QPASS_SHARED"); got != filepath.Join(directory, envFileStaging) {
+		t.Fatalf("expected staging provenance, got %q", got)
+	}
+	if got := environment.Source("ENV_QPASS_AMBIENT"); got != "" {
+		t.Fatalf("expected ambient value to have no file source, got %q", got)
+	}
+	if environment.AppEnv() != Staging || len(environment.Files()) != 2 {
+		t.Fatalf("unexpected app env %q or files %v", environment.AppEnv(), environment.Files())
+	}
+	if _, present := os.LookupEnv("ENV_QPASS_BASE"); present || writes != 0 || IsEnvLoaded() {
+		t.Fatal("expected LoadInto to leave the process environment and loader state untouched")
+	}
+}
+
+// TestLoadIntoIgnoresProcessEnvironment ensures process values do not leak into an isolated view.
+func TestLoadIntoIgnoresProcessEnvironment(t *testing.T) {
+	prepareLoaderTest(t, "ENV_QPASS_PROCESS")
+	t.Setenv("APP_ENV", Production)
+	t.Setenv("ENV_QPASS_PROCESS", "process")
+	directory := t.TempDir()
+	writeEnvFile(t, directory, fileEnv, "ENV_QPASS_PROCESS=file\n")
+	changeWorkingDirectory(t, directory)
+
+	environment, err := LoadInto(directory, LoadIntoOptions{})
+	if err != nil {
+		t.Fatalf("LoadInto: %v", err)
+	}
+	if got := environment.Get("ENV_QPASS_PROCESS", ""); got != "file" {
+		t.Fatalf("expected file value without ambient override, got %q", got)
+	}
+	if got := environment.AppEnv(); got != Local {
+		t.Fatalf("expected default APP_ENV, got %q", got)
+	}
+}
+
+// TestLoadIntoReturnsParseErrors ensures isolated loads report malformed files.
+func TestLoadIntoReturnsParseErrors(t *testing.T) {
+	prepareLoaderTest(t)
+	directory := t.TempDir()
+	writeEnvFile(t, directory, fileEnv, "BROKEN='unterminated\n")
+	changeWorkingDirectory(t, directory)
+
+	if _, err := LoadInto(directory, LoadIntoOptions{}); err == nil {
+		t.Fatal("expected parse error")
+	}
+}
+
+// TestLoadIntoUsesWorkingDirectoryByDefault ensures an empty directory follows Load's starting point.
+func TestLoadIntoUsesWorkingDirectoryByDefault(t *testing.T) {
+	prepareLoaderTest(t)
+	workingDirectoryErr := errors.New("injected working directory failure")
+	envFileGetwd = func() (string, error) {
+		return "", workingDirectoryErr
+	}
+	if _, err := LoadInto("", LoadIntoOptions{}); !errors.Is(err, workingDirectoryErr) {
+		t.Fatalf("expected working directory error, got %v", err)
+	}
+}
+
+// TestEnvironmentTypedGetters ensures isolated values share the process getters' parsing rules.
+func TestEnvironmentTypedGetters(t *testing.T) {
+	environment := &Environment{values: map[string]string{
+		"INT":      "42",
+		"UINT":     "7",
+		"FLOAT":    "1.5",
+		"BOOL":     "true",
+		"DURATION": "2s",
+		"SLICE":    "a, b",
+		"MAP":      "a=1, b=2",
+		"ENUM":     "blue",
+		"EMPTY":    "",
+	}}
+
+	if environment.Get("EMPTY", "fallback") != "fallback" ||
+		environment.GetInt("INT", "0") != 42 ||
+		environment.GetInt64("INT", "0") != 42 ||
+		environment.GetUint("UINT", "0") != 7 ||
+		environment.GetUint64("UINT", "0") != 7 ||
+		environment.GetFloat("FLOAT", "0") != 1.5 ||
+		!environment.GetBool("BOOL", "false") ||
+		environment.GetDuration("DURATION", "0s") != 2*time.Second ||
+		environment.GetEnum("ENUM", "red", []string{"red", "blue"}) != "blue" ||
+		environment.MustGetInt("INT") != 42 ||
+		!environment.MustGetBool("BOOL") {
+		t.Fatal("unexpected typed getter result")
+	}
+	if got := environment.GetSlice("SLICE", ""); !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Fatalf("unexpected slice %v", got)
+	}
+	if got := environment.GetMap("MAP", ""); !reflect.DeepEqual(got, map[string]string{"a": "1", "b": "2"}) {
+		t.Fatalf("unexpected map %v", got)
+	}
+	if got := environment.GetMapInt("MAP", "", 1); !reflect.DeepEqual(got, map[string]int{"a": 1, "b": 2}) {
+		t.Fatalf("unexpected int map %v", got)
+	}
+	if got := environment.Environ(); !reflect.DeepEqual(got[:2], []string{"BOOL=true", "DURATION=2s"}) {
+		t.Fatalf("unexpected environ %v", got)
+	}
+	expectPanic(t, "Environment.MustGet", func() { environment.MustGet("EMPTY") })
+}
+
+// TestEnvironmentScopesReadIsolatedValues ensures scopes and child discovery use the isolated view.
+func TestEnvironmentScopesReadIsolatedValues(t *testing.T) {
+	restore := snapshotEnv([]string{"STORAGE_PROCESS_ROOT"})
+	t.Cleanup(restore)
+	_ = os.Setenv("STORAGE_PROCESS_ROOT", "process")
+	environment := &Environment{values: map[string]string{
+		"STORAGE_ROOT":        "private",
+		"STORAGE_PUBLIC_ROOT": "public",
+		"STORAGE_PUBLIC_SIZE": "10",
+	}}
+
+	storage := environment.WithPrefix("STORAGE")
+	public := storage.Child("PUBLIC")
+	if storage.Get("ROOT", "") != "private" || public.Get("ROOT", "") != "public" || public.GetInt("SIZE", "0") != 10 {
+		t.Fatal("expected scoped reads from the isolated environment")
+	}
+	if got := storage.ChildNames([]string{"ROOT", "SIZE"}); !reflect.DeepEqual(got, []string{"PUBLIC"}) {
+		t.Fatalf("expected isolated child names, got %v", got)
+	}
+}
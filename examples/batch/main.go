@@ -0,0 +1,29 @@
+//go:build ignore
+// +build ignore
+
+// Code generated by docs/examplegen; DO NOT EDIT.
+
+package main
+
+import (
+	"os"
+
+	"github.com/goforj/env/v2"
+)
+
+// main keeps this documented example executable so API drift fails during compilation.
+func main() {
+	// Batch applies every update staged by fn as one transaction.
+
+	// Example: switch several settings together
+	err := env.Batch(func(tx *env.Tx) error {
+		tx.Set("DB_HOST", "db-2")
+		tx.Set("DB_PORT", "5433")
+		tx.Unset("DB_REPLICA")
+		return nil
+	})
+	env.Dump(err == nil, os.Getenv("DB_HOST"), os.Getenv("DB_PORT"))
+	// #bool true
+	// #string "db-2"
+	// #string "5433"
+}
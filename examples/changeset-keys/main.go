@@ -0,0 +1,23 @@
+//go:build ignore
+// +build ignore
+
+// Code generated by docs/examplegen; DO NOT EDIT.
+
+package main
+
+import "github.com/goforj/env/v2"
+
+// main keeps this documented example executable so API drift fails during compilation.
+func main() {
+	// Keys returns the changed keys of the given kinds, or every changed key when no kind is given.
+
+	// Example: keys added by a reload
+	changes := env.ChangeSet{
+		{Key: "CACHE_TTL", Kind: env.ChangeAdded},
+		{Key: "DB_HOST", Kind: env.ChangeModified},
+	}
+	env.Dump(changes.Keys(env.ChangeAdded))
+	// #[]string [
+	//  0 => "CACHE_TTL" #string
+	// ]
+}
@@ -0,0 +1,27 @@
+//go:build ignore
+// +build ignore
+
+// Code generated by docs/examplegen; DO NOT EDIT.
+
+package main
+
+import (
+	"os"
+
+	"github.com/goforj/env/v2"
+)
+
+// main keeps this documented example executable so API drift fails during compilation.
+func main() {
+	// Diff returns, in lexical order, the keys whose value or presence differs between two snapshots.
+
+	// Example: find variables changed by a step
+	_ = os.Setenv("CACHE_TTL", "60")
+	before := env.Snapshot("CACHE_TTL", "CACHE_DRIVER")
+	_ = os.Setenv("CACHE_TTL", "120")
+	after := env.Snapshot("CACHE_TTL", "CACHE_DRIVER")
+	env.Dump(before.Diff(after))
+	// #[]string [
+	//  0 => "CACHE_TTL" #string
+	// ]
+}
@@ -0,0 +1,32 @@
+//go:build ignore
+// +build ignore
+
+// Code generated by docs/examplegen; DO NOT EDIT.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/goforj/env/v2"
+)
+
+// main keeps this documented example executable so API drift fails during compilation.
+func main() {
+	// EncryptFile seals every plain value in the dotenv file at path with key.
+
+	// Example: commit an encrypted production file
+	tmp, _ := os.MkdirTemp("", "envdoc")
+	defer os.RemoveAll(tmp)
+	production := filepath.Join(tmp, ".env.production")
+	_ = os.WriteFile(production, []byte("DB_PASSWORD=hunter2 # rotated monthly"), 0o600)
+
+	key := env.GenerateKey()
+	_ = env.EncryptFile(production, key)
+	sealed, _ := os.ReadFile(production)
+	env.Dump(strings.HasPrefix(string(sealed), "DB_PASSWORD=encrypted:"), strings.Contains(string(sealed), "hunter2"))
+	// #bool true
+	// #bool false
+}
@@ -0,0 +1,32 @@
+//go:build ignore
+// +build ignore
+
+// Code generated by docs/examplegen; DO NOT EDIT.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/goforj/env/v2"
+)
+
+// main keeps this documented example executable so API drift fails during compilation.
+func main() {
+	// Export writes the variables env files supplied, in format, like the package-level Export.
+
+	// Example: render a docker --env-file
+	tmp, _ := os.MkdirTemp("", "envdoc")
+	defer os.RemoveAll(tmp)
+	_ = os.WriteFile(filepath.Join(tmp, ".env"), []byte("PORT=8080\nLOG_LEVEL=info"), 0o644)
+
+	project, _ := env.LoadInto(tmp, env.LoadIntoOptions{Environ: []string{"HOME=/root"}})
+	var dockerEnv strings.Builder
+	_ = project.Export(&dockerEnv, env.ExportDocker)
+	fmt.Print(dockerEnv.String())
+	// LOG_LEVEL=info
+	// PORT=8080
+}
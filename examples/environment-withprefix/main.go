@@ -0,0 +1,28 @@
+//go:build ignore
+// +build ignore
+
+// Code generated by docs/examplegen; DO NOT EDIT.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/goforj/env/v2"
+)
+
+// main keeps this documented example executable so API drift fails during compilation.
+func main() {
+	// WithPrefix returns a scope rooted at prefix that reads this environment.
+
+	// Example: scoped access to an isolated environment
+	tmp, _ := os.MkdirTemp("", "envdoc")
+	defer os.RemoveAll(tmp)
+	_ = os.WriteFile(filepath.Join(tmp, ".env"), []byte("STORAGE_PUBLIC_ROOT=storage/app/public"), 0o644)
+
+	environment, _ := env.LoadInto(tmp, env.LoadIntoOptions{})
+	public := environment.WithPrefix("STORAGE").Child("PUBLIC")
+	env.Dump(public.Get("ROOT", ""))
+	// #string "storage/app/public"
+}
@@ -0,0 +1,35 @@
+//go:build ignore
+// +build ignore
+
+// Code generated by docs/examplegen; DO NOT EDIT.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/goforj/env/v2"
+)
+
+// main keeps this documented example executable so API drift fails during compilation.
+func main() {
+	// Export writes the variables the loader applied, with their current values, in format.
+
+	// Example: hand loaded values to a shell
+	tmp, _ := os.MkdirTemp("", "envdoc")
+	defer os.RemoveAll(tmp)
+	originalDirectory, _ := os.Getwd()
+	defer os.Chdir(originalDirectory)
+	_ = os.Chdir(tmp)
+	_ = os.WriteFile(filepath.Join(tmp, ".env"), []byte("APP_ENV=local\nGREETING=\"it's here\""), 0o644)
+
+	_ = env.Load()
+	var shell strings.Builder
+	_ = env.Export(&shell, env.ExportShell)
+	fmt.Print(shell.String())
+	// export APP_ENV='local'
+	// export GREETING='it'\''s here'
+}
@@ -0,0 +1,18 @@
+//go:build ignore
+// +build ignore
+
+// Code generated by docs/examplegen; DO NOT EDIT.
+
+package main
+
+import "github.com/goforj/env/v2"
+
+// main keeps this documented example executable so API drift fails during compilation.
+func main() {
+	// ExportFormats lists every supported format in documentation order.
+
+	// Example: list formats
+	env.Dump(len(env.ExportFormats()), env.ExportFormats()[0])
+	// #int 8
+	// #env.ExportFormat "dotenv"
+}
@@ -0,0 +1,23 @@
+//go:build ignore
+// +build ignore
+
+// Code generated by docs/examplegen; DO NOT EDIT.
+
+package main
+
+import (
+	"encoding/base64"
+
+	"github.com/goforj/env/v2"
+)
+
+// main keeps this documented example executable so API drift fails during compilation.
+func main() {
+	// GenerateKey returns a new random key for EncryptFile, encoded as standard base64.
+
+	// Example: create a key
+	key := env.GenerateKey()
+	decoded, _ := base64.StdEncoding.DecodeString(key)
+	env.Dump(len(decoded))
+	// #int 32
+}
@@ -5,8 +5,9 @@ go 1.24.4
 require github.com/goforj/env/v2 v2.0.0
 
 require (
+	github.com/BurntSushi/toml v1.6.0 // indirect
 	github.com/goforj/godump v1.7.1 // indirect
-	github.com/joho/godotenv v1.5.1 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
 
 replace github.com/goforj/env/v2 => ..
@@ -0,0 +1,24 @@
+//go:build ignore
+// +build ignore
+
+// Code generated by docs/examplegen; DO NOT EDIT.
+
+package main
+
+import (
+	"os"
+
+	"github.com/goforj/env/v2"
+)
+
+// main keeps this documented example executable so API drift fails during compilation.
+func main() {
+	// HasAppEnvTrait reports whether the current APP_ENV was registered with trait.
+
+	// Example: guard destructive commands
+	_ = env.RegisterAppEnv("sandbox", env.AppEnvOptions{Traits: []string{"disposable"}})
+	_ = os.Setenv("APP_ENV", "sandbox")
+	env.Dump(env.HasAppEnvTrait("disposable"), env.HasAppEnvTrait("protected"))
+	// #bool true
+	// #bool false
+}
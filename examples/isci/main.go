@@ -0,0 +1,17 @@
+//go:build ignore
+// +build ignore
+
+// Code generated by docs/examplegen; DO NOT EDIT.
+
+package main
+
+import "github.com/goforj/env/v2"
+
+// main keeps this documented example executable so API drift fails during compilation.
+func main() {
+	// IsCI reports whether the process is running on a continuous integration runner.
+
+	env.Dump(env.IsCI())
+	// #bool true  (on CI runners)
+	// #bool false (on developer machines)
+}
@@ -0,0 +1,29 @@
+//go:build ignore
+// +build ignore
+
+// Code generated by docs/examplegen; DO NOT EDIT.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/goforj/env/v2"
+)
+
+// main keeps this documented example executable so API drift fails during compilation.
+func main() {
+	// LoadCompose transactionally applies the environment a docker-compose service would receive.
+
+	// Example: run a compose service outside Docker
+	project, _ := os.MkdirTemp("", "envdoc")
+	defer os.RemoveAll(project)
+	compose := "services:\n  api:\n    environment:\n      DB_HOST: ${DB_HOST:-localhost}\n      DB_PORT: 5432\n"
+	_ = os.WriteFile(filepath.Join(project, "docker-compose.yml"), []byte(compose), 0o644)
+
+	_ = env.LoadCompose(filepath.Join(project, "docker-compose.yml"), "api")
+	env.Dump(os.Getenv("DB_HOST"), os.Getenv("DB_PORT"))
+	// #string "localhost"
+	// #string "5432"
+}
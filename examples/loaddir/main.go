@@ -0,0 +1,27 @@
+//go:build ignore
+// +build ignore
+
+// Code generated by docs/examplegen; DO NOT EDIT.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/goforj/env/v2"
+)
+
+// main keeps this documented example executable so API drift fails during compilation.
+func main() {
+	// LoadDir transactionally applies a directory that holds one file per key, such as /run/secrets.
+
+	// Example: read Docker secrets
+	secrets, _ := os.MkdirTemp("", "envdoc")
+	defer os.RemoveAll(secrets)
+	_ = os.WriteFile(filepath.Join(secrets, "password"), []byte("s3cr3t\n"), 0o600)
+
+	_ = env.LoadDir(secrets, env.DirOptions{Prefix: "DB_"})
+	env.Dump(os.Getenv("DB_PASSWORD"))
+	// #string "s3cr3t"
+}
@@ -0,0 +1,29 @@
+//go:build ignore
+// +build ignore
+
+// Code generated by docs/examplegen; DO NOT EDIT.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/goforj/env/v2"
+)
+
+// main keeps this documented example executable so API drift fails during compilation.
+func main() {
+	// LoadFiles transactionally applies exactly the listed env files instead of discovering layers.
+
+	// Example: load a deployment file and a secrets file
+	tmp, _ := os.MkdirTemp("", "envdoc")
+	defer os.RemoveAll(tmp)
+	_ = os.WriteFile(filepath.Join(tmp, "prod.env"), []byte("PORT=80\nLOG_LEVEL=warn"), 0o644)
+	_ = os.WriteFile(filepath.Join(tmp, "secrets.env"), []byte("LOG_LEVEL=error"), 0o644)
+
+	_ = env.LoadFiles(filepath.Join(tmp, "prod.env"), filepath.Join(tmp, "secrets.env"))
+	env.Dump(os.Getenv("PORT"), os.Getenv("LOG_LEVEL"))
+	// #string "80"
+	// #string "error"
+}
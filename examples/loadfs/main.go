@@ -0,0 +1,31 @@
+//go:build ignore
+// +build ignore
+
+// Code generated by docs/examplegen; DO NOT EDIT.
+
+package main
+
+import (
+	"os"
+	"testing/fstest"
+
+	"github.com/goforj/env/v2"
+)
+
+// main keeps this documented example executable so API drift fails during compilation.
+func main() {
+	// LoadFS transactionally applies env files from fsys, such as an embed.FS, below every on-disk file.
+
+	// Example: embed defaults into a binary
+	// //go:embed config/.env config/.env.production
+	// var configFS embed.FS
+	configFS := fstest.MapFS{
+		"config/.env":            {Data: []byte("APP_ENV=production\nPORT=3000\nLOG_LEVEL=info")},
+		"config/.env.production": {Data: []byte("LOG_LEVEL=warn")},
+	}
+
+	_ = env.LoadFS(configFS, env.FSOptions{Dir: "config"})
+	env.Dump(os.Getenv("PORT"), os.Getenv("LOG_LEVEL"))
+	// #string "3000"
+	// #string "warn"
+}
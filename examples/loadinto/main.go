@@ -0,0 +1,29 @@
+//go:build ignore
+// +build ignore
+
+// Code generated by docs/examplegen; DO NOT EDIT.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/goforj/env/v2"
+)
+
+// main keeps this documented example executable so API drift fails during compilation.
+func main() {
+	// LoadInto resolves the env files for dir into an isolated Environment without changing the process.
+
+	// Example: inspect a project without mutating the process
+	tmp, _ := os.MkdirTemp("", "envdoc")
+	defer os.RemoveAll(tmp)
+	_ = os.WriteFile(filepath.Join(tmp, ".env"), []byte("APP_ENV=production\nPORT=8080"), 0o644)
+	_ = os.WriteFile(filepath.Join(tmp, ".env.production"), []byte("PORT=80"), 0o644)
+
+	environment, _ := env.LoadInto(tmp, env.LoadIntoOptions{})
+	env.Dump(environment.AppEnv(), environment.GetInt("PORT", "3000"))
+	// #string "production"
+	// #int 80
+}
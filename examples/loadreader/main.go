@@ -0,0 +1,24 @@
+//go:build ignore
+// +build ignore
+
+// Code generated by docs/examplegen; DO NOT EDIT.
+
+package main
+
+import (
+	"os"
+	"strings"
+
+	"github.com/goforj/env/v2"
+)
+
+// main keeps this documented example executable so API drift fails during compilation.
+func main() {
+	// LoadReader transactionally applies dotenv content from r as the lowest-priority layer.
+
+	// Example: read dotenv content from standard input
+	// _ = env.LoadReader("stdin", os.Stdin)
+	_ = env.LoadReader("stdin", strings.NewReader("WORKERS=4"))
+	env.Dump(os.Getenv("WORKERS"))
+	// #string "4"
+}
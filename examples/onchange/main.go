@@ -0,0 +1,37 @@
+//go:build ignore
+// +build ignore
+
+// Code generated by docs/examplegen; DO NOT EDIT.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/goforj/env/v2"
+)
+
+// main keeps this documented example executable so API drift fails during compilation.
+func main() {
+	// OnChange subscribes fn to changes of keys matching keyOrPattern and returns a cancel function.
+
+	// Example: watch database settings
+	tmp, _ := os.MkdirTemp("", "envdoc")
+	defer os.RemoveAll(tmp)
+	originalDirectory, _ := os.Getwd()
+	defer os.Chdir(originalDirectory)
+	_ = os.Chdir(tmp)
+	_ = os.WriteFile(filepath.Join(tmp, ".env"), []byte("DB_HOST=db-1"), 0o644)
+	_ = env.Load()
+
+	cancel := env.OnChange("DB_*", func(change env.Change) {
+		env.Dump(change.Key, string(change.Kind))
+	})
+	defer cancel()
+
+	_ = os.WriteFile(filepath.Join(tmp, ".env"), []byte("DB_HOST=db-2"), 0o644)
+	_ = env.Reload()
+	// #string "DB_HOST"
+	// #string "modified"
+}
@@ -0,0 +1,29 @@
+//go:build ignore
+// +build ignore
+
+// Code generated by docs/examplegen; DO NOT EDIT.
+
+package main
+
+import (
+	"strings"
+
+	"github.com/goforj/env/v2"
+)
+
+// main keeps this documented example executable so API drift fails during compilation.
+func main() {
+	// Parse reads dotenv-formatted data and returns its variables.
+
+	// Example: parse dotenv text
+	values, err := env.Parse(strings.NewReader("export HOST=localhost\nGREETING=\"hello\\nworld\" # two lines\n"), env.ParseOptions{})
+	env.Dump(err == nil, values["HOST"], values["GREETING"])
+	// #bool true
+	// #string "localhost"
+	// #string "hello\nworld"
+
+	// Example: positioned syntax errors
+	_, syntaxErr := env.Parse(strings.NewReader("HOST=localhost\nPORT 8080\n"), env.ParseOptions{Filename: ".env"})
+	env.Dump(syntaxErr.Error())
+	// #string ".env:2:6: expected '=' after key PORT"
+}
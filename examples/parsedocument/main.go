@@ -0,0 +1,28 @@
+//go:build ignore
+// +build ignore
+
+// Code generated by docs/examplegen; DO NOT EDIT.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/goforj/env/v2"
+)
+
+// main keeps this documented example executable so API drift fails during compilation.
+func main() {
+	// ParseDocument reads an env file into an editable Document.
+
+	// Example: update one value and keep the rest
+	document, _ := env.ParseDocument(strings.NewReader("# database\nDB_HOST=localhost # dev only\nDB_PASSWORD='old'\n"))
+	_ = document.Set("DB_PASSWORD", "n3w")
+	_ = document.Set("DB_PORT", "5432")
+	fmt.Print(string(document.Bytes()))
+	// # database
+	// DB_HOST=localhost # dev only
+	// DB_PASSWORD='n3w'
+	// DB_PORT=5432
+}
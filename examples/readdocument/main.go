@@ -0,0 +1,33 @@
+//go:build ignore
+// +build ignore
+
+// Code generated by docs/examplegen; DO NOT EDIT.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/goforj/env/v2"
+)
+
+// main keeps this documented example executable so API drift fails during compilation.
+func main() {
+	// ReadDocument reads the env file at path into an editable Document.
+
+	// Example: rotate a value in place
+	tmp, _ := os.MkdirTemp("", "envdoc")
+	defer os.RemoveAll(tmp)
+	path := filepath.Join(tmp, ".env")
+	_ = os.WriteFile(path, []byte("# rotated monthly\nAPI_TOKEN=\"abc\"\n"), 0o600)
+
+	envFile, _ := env.ReadDocument(path)
+	_ = envFile.Set("API_TOKEN", "xyz")
+	_ = envFile.WriteFile(path)
+	rotated, _ := os.ReadFile(path)
+	fmt.Print(string(rotated))
+	// # rotated monthly
+	// API_TOKEN="xyz"
+}
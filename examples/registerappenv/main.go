@@ -0,0 +1,31 @@
+//go:build ignore
+// +build ignore
+
+// Code generated by docs/examplegen; DO NOT EDIT.
+
+package main
+
+import (
+	"os"
+
+	"github.com/goforj/env/v2"
+)
+
+// main keeps this documented example executable so API drift fails during compilation.
+func main() {
+	// RegisterAppEnv declares an application environment beyond testing, local, staging, and production.
+
+	// Example: declare a QA environment
+	_ = env.RegisterAppEnv("qa", env.AppEnvOptions{Aliases: []string{"quality"}, Traits: []string{"protected"}})
+	_ = env.SetAppEnv("quality")
+	env.Dump(env.GetAppEnv(), env.IsAppEnv("qa"), env.HasAppEnvTrait("protected"))
+	// #string "qa"
+	// #bool true
+	// #bool true
+
+	// Example: alias a built-in environment
+	_ = env.RegisterAppEnv(env.Production, env.AppEnvOptions{Aliases: []string{"prod"}})
+	_ = os.Setenv("APP_ENV", "prod")
+	env.Dump(env.IsAppEnvProduction())
+	// #bool true
+}
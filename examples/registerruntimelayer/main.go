@@ -0,0 +1,34 @@
+//go:build ignore
+// +build ignore
+
+// Code generated by docs/examplegen; DO NOT EDIT.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/goforj/env/v2"
+)
+
+// main keeps this documented example executable so API drift fails during compilation.
+func main() {
+	// RegisterRuntimeLayer adds a .env.<name> layer that Load applies when detect reports true.
+
+	// Example: apply .env.gpu when a GPU is exposed
+	tmp, _ := os.MkdirTemp("", "envdoc")
+	defer os.RemoveAll(tmp)
+	originalDirectory, _ := os.Getwd()
+	defer os.Chdir(originalDirectory)
+	_ = os.Chdir(tmp)
+	_ = os.WriteFile(filepath.Join(tmp, ".env"), []byte("NVIDIA_VISIBLE_DEVICES=all"), 0o644)
+	_ = os.WriteFile(filepath.Join(tmp, ".env.gpu"), []byte("BATCH_SIZE=64"), 0o644)
+
+	_ = env.RegisterRuntimeLayer("gpu", func(getenv func(string) string) bool {
+		return getenv("NVIDIA_VISIBLE_DEVICES") != ""
+	})
+	_ = env.Load()
+	env.Dump(os.Getenv("BATCH_SIZE"))
+	// #string "64"
+}
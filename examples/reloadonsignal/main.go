@@ -0,0 +1,25 @@
+//go:build ignore
+// +build ignore
+
+// Code generated by docs/examplegen; DO NOT EDIT.
+
+package main
+
+import (
+	"context"
+
+	"github.com/goforj/env/v2"
+)
+
+// main keeps this documented example executable so API drift fails during compilation.
+func main() {
+	// ReloadOnSignal reloads env files whenever the process receives one of signals, SIGHUP by default.
+
+	// Example: reload on SIGHUP
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	reloader := env.ReloadOnSignal(ctx)
+	env.Dump(reloader.LastReload().IsZero(), reloader.LastError() == nil)
+	// #bool true
+	// #bool true
+}
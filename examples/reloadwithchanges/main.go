@@ -0,0 +1,33 @@
+//go:build ignore
+// +build ignore
+
+// Code generated by docs/examplegen; DO NOT EDIT.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/goforj/env/v2"
+)
+
+// main keeps this documented example executable so API drift fails during compilation.
+func main() {
+	// ReloadWithChanges behaves like Reload and returns the process variables it changed.
+
+	// Example: react to changed keys
+	tmp, _ := os.MkdirTemp("", "envdoc")
+	defer os.RemoveAll(tmp)
+	originalDirectory, _ := os.Getwd()
+	defer os.Chdir(originalDirectory)
+	_ = os.Chdir(tmp)
+	_ = os.WriteFile(filepath.Join(tmp, ".env"), []byte("DB_HOST=db-1\nDB_POOL=5"), 0o644)
+	_ = env.Load()
+	_ = os.WriteFile(filepath.Join(tmp, ".env"), []byte("DB_HOST=db-2\nDB_POOL=5"), 0o644)
+	changes, _ := env.ReloadWithChanges()
+	env.Dump(changes.Keys(env.ChangeModified))
+	// #[]string [
+	//  0 => "DB_HOST" #string
+	// ]
+}
@@ -0,0 +1,31 @@
+//go:build ignore
+// +build ignore
+
+// Code generated by docs/examplegen; DO NOT EDIT.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/goforj/env/v2"
+)
+
+// main keeps this documented example executable so API drift fails during compilation.
+func main() {
+	// RotateFile re-encrypts every encrypted value in the dotenv file at path from oldKey to newKey.
+
+	// Example: rotate to a new key
+	tmp, _ := os.MkdirTemp("", "envdoc")
+	defer os.RemoveAll(tmp)
+	secrets := filepath.Join(tmp, ".env.production")
+	_ = os.WriteFile(secrets, []byte("API_TOKEN=abc123"), 0o600)
+	oldKey := env.GenerateKey()
+	newKey := env.GenerateKey()
+	_ = env.EncryptFile(secrets, oldKey)
+
+	env.Dump(env.RotateFile(secrets, oldKey, newKey) == nil, env.RotateFile(secrets, oldKey, newKey) == nil)
+	// #bool true
+	// #bool false
+}
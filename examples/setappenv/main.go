@@ -9,7 +9,7 @@ import "github.com/goforj/env/v2"
 
 // main keeps this documented example executable so API drift fails during compilation.
 func main() {
-	// SetAppEnv sets APP_ENV to a supported value.
+	// SetAppEnv sets APP_ENV to a built-in or registered environment.
 
 	// Example: set a supported environment
 	_ = env.SetAppEnv(env.Staging)
@@ -0,0 +1,34 @@
+//go:build ignore
+// +build ignore
+
+// Code generated by docs/examplegen; DO NOT EDIT.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/goforj/env/v2"
+)
+
+// main keeps this documented example executable so API drift fails during compilation.
+func main() {
+	// SetLayerOptions configures the layers and discovery rules used by Load, Reload, and LoadInto.
+
+	// Example: allow the override layer to fix a stale shell variable
+	tmp, _ := os.MkdirTemp("", "envdoc")
+	defer os.RemoveAll(tmp)
+	originalDirectory, _ := os.Getwd()
+	defer os.Chdir(originalDirectory)
+	_ = os.Chdir(tmp)
+	_ = os.WriteFile(filepath.Join(tmp, ".env.defaults"), []byte("CACHE_TTL=60\nREGION=us-east-1"), 0o644)
+	_ = os.WriteFile(filepath.Join(tmp, ".env.override"), []byte("REGION=eu-west-1"), 0o644)
+	_ = os.Setenv("REGION", "stale")
+
+	_ = env.SetLayerOptions(env.LayerOptions{OverrideKeys: []string{"REGION"}})
+	_ = env.Load()
+	env.Dump(os.Getenv("CACHE_TTL"), os.Getenv("REGION"))
+	// #string "60"
+	// #string "eu-west-1"
+}
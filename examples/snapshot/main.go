@@ -0,0 +1,25 @@
+//go:build ignore
+// +build ignore
+
+// Code generated by docs/examplegen; DO NOT EDIT.
+
+package main
+
+import (
+	"os"
+
+	"github.com/goforj/env/v2"
+)
+
+// main keeps this documented example executable so API drift fails during compilation.
+func main() {
+	// Snapshot captures the named process variables, or the whole process environment when no keys are given.
+
+	// Example: restore after a temporary change
+	_ = os.Setenv("FEATURE_FLAG", "off")
+	snapshot := env.Snapshot("FEATURE_FLAG")
+	_ = os.Setenv("FEATURE_FLAG", "on")
+	_ = snapshot.Restore()
+	env.Dump(os.Getenv("FEATURE_FLAG"))
+	// #string "off"
+}
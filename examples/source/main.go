@@ -0,0 +1,27 @@
+//go:build ignore
+// +build ignore
+
+// Code generated by docs/examplegen; DO NOT EDIT.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/goforj/env/v2"
+)
+
+// main keeps this documented example executable so API drift fails during compilation.
+func main() {
+	// Source returns the env file that supplied key.
+
+	// Example: trace a value to its file
+	tmp, _ := os.MkdirTemp("", "envdoc")
+	defer os.RemoveAll(tmp)
+	_ = os.WriteFile(filepath.Join(tmp, ".env"), []byte("PORT=8080"), 0o644)
+
+	environment, _ := env.LoadInto(tmp, env.LoadIntoOptions{})
+	env.Dump(filepath.Base(environment.Source("PORT")))
+	// #string ".env"
+}
@@ -0,0 +1,32 @@
+//go:build ignore
+// +build ignore
+
+// Code generated by docs/examplegen; DO NOT EDIT.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/goforj/env/v2"
+)
+
+// main keeps this documented example executable so API drift fails during compilation.
+func main() {
+	// Unload transactionally restores the process environment that existed before the first successful Load.
+
+	// Example: return to the pre-load environment
+	tmp, _ := os.MkdirTemp("", "envdoc")
+	defer os.RemoveAll(tmp)
+	originalDirectory, _ := os.Getwd()
+	defer os.Chdir(originalDirectory)
+	_ = os.Chdir(tmp)
+	_ = os.WriteFile(filepath.Join(tmp, ".env"), []byte("SERVICE=api"), 0o644)
+	_ = env.Load()
+	_ = env.Unload()
+	_, present := os.LookupEnv("SERVICE")
+	env.Dump(present, env.IsEnvLoaded())
+	// #bool false
+	// #bool false
+}
@@ -0,0 +1,36 @@
+//go:build ignore
+// +build ignore
+
+// Code generated by docs/examplegen; DO NOT EDIT.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/goforj/env/v2"
+)
+
+// main keeps this documented example executable so API drift fails during compilation.
+func main() {
+	// Unredacted returns a copy of the change with actual values in Previous and Current.
+
+	// Example: inspect a changed value
+	tmp, _ := os.MkdirTemp("", "envdoc")
+	defer os.RemoveAll(tmp)
+	originalDirectory, _ := os.Getwd()
+	defer os.Chdir(originalDirectory)
+	_ = os.Chdir(tmp)
+	_ = os.WriteFile(filepath.Join(tmp, ".env"), []byte("SERVICE=api"), 0o644)
+	_ = env.Load()
+	_ = os.WriteFile(filepath.Join(tmp, ".env"), []byte("SERVICE=worker"), 0o644)
+	changes, _ := env.ReloadWithChanges()
+	for _, change := range changes {
+		if change.Key == "SERVICE" {
+			env.Dump(change.Previous, change.Unredacted().Current)
+		}
+	}
+	// #string "[redacted]"
+	// #string "worker"
+}
@@ -0,0 +1,40 @@
+//go:build ignore
+// +build ignore
+
+// Code generated by docs/examplegen; DO NOT EDIT.
+
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/goforj/env/v2"
+)
+
+// main keeps this documented example executable so API drift fails during compilation.
+func main() {
+	// Watch polls every env file the loader discovered, plus files that could appear earlier in any
+	// layer's search path, and reloads after edits settle.
+
+	// Example: reload after an edit
+	tmp, _ := os.MkdirTemp("", "envdoc")
+	defer os.RemoveAll(tmp)
+	originalDirectory, _ := os.Getwd()
+	defer os.Chdir(originalDirectory)
+	_ = os.Chdir(tmp)
+	_ = os.WriteFile(filepath.Join(tmp, ".env"), []byte("SERVICE=api"), 0o644)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	events, _ := env.Watch(ctx, env.WatchOptions{Interval: 20 * time.Millisecond})
+	_ = os.WriteFile(filepath.Join(tmp, ".env"), []byte("SERVICE=worker"), 0o644)
+	event := <-events
+	env.Dump(event.Changes.Keys(), os.Getenv("SERVICE"))
+	// #[]string [
+	//  0 => "SERVICE" #string
+	// ]
+	// #string "worker"
+}
@@ -0,0 +1,26 @@
+//go:build ignore
+// +build ignore
+
+// Code generated by docs/examplegen; DO NOT EDIT.
+
+package main
+
+import (
+	"os"
+
+	"github.com/goforj/env/v2"
+)
+
+// main keeps this documented example executable so API drift fails during compilation.
+func main() {
+	// With applies overrides for the duration of fn and then restores the previous values.
+
+	// Example: run code with temporary overrides
+	_ = os.Setenv("CACHE_DRIVER", "redis")
+	_ = env.With(map[string]string{"CACHE_DRIVER": "memory"}, func() {
+		env.Dump(os.Getenv("CACHE_DRIVER"))
+	})
+	env.Dump(os.Getenv("CACHE_DRIVER"))
+	// #string "memory"
+	// #string "redis"
+}
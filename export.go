@@ -0,0 +1,280 @@
+package env
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ExportFormat names an output syntax for Export.
+type ExportFormat string
+
+const (
+	// ExportDotenv writes KEY=value lines that Load and Parse read back unchanged.
+	ExportDotenv ExportFormat = "dotenv"
+	// ExportShell writes POSIX shell export lines for eval or source.
+	ExportShell ExportFormat = "shell"
+	// ExportFish writes fish set -gx lines.
+	ExportFish ExportFormat = "fish"
+	// ExportPowerShell writes $env: assignments.
+	ExportPowerShell ExportFormat = "powershell"
+	// ExportJSON writes one JSON object.
+	ExportJSON ExportFormat = "json"
+	// ExportDocker writes a file for docker run --env-file, which cannot hold multi-line values.
+	ExportDocker ExportFormat = "docker"
+	// ExportGitHubActions writes lines to append to $GITHUB_ENV.
+	ExportGitHubActions ExportFormat = "github"
+	// ExportKubernetes writes a ConfigMap named env and, for secret-looking keys, a Secret named env.
+	ExportKubernetes ExportFormat = "kubernetes"
+)
+
+// ExportFormats lists every supported format in documentation order.
+// @group Export
+// @behavior readonly
+//
+// Example: list formats
+//
+//	env.Dump(len(env.ExportFormats()), env.ExportFormats()[0])
+//	// #int 8
+//	// #env.ExportFormat "dotenv"
+func ExportFormats() []ExportFormat {
+	return []ExportFormat{
+		ExportDotenv, ExportShell, ExportFish, ExportPowerShell,
+		ExportJSON, ExportDocker, ExportGitHubActions, ExportKubernetes,
+	}
+}
+
+// secretKeySegments mark a key as secret-looking when they appear as an underscore-separated part.
+var secretKeySegments = map[string]struct{}{
+	"APIKEY": {}, "AUTH": {}, "CERT": {}, "CREDENTIAL": {}, "CREDENTIALS": {}, "KEY": {}, "PASS": {},
+	"PASSPHRASE": {}, "PASSWD": {}, "PASSWORD": {}, "PRIVATE": {}, "PWD": {}, "SALT": {}, "SECRET": {},
+	"SECRETS": {}, "TOKEN": {},
+}
+
+// Export writes the variables the loader applied, with their current values, in format.
+// @group Export
+// @behavior readonly
+//
+// Export covers every key a Load, Reload, or LoadFiles call owns, plus defaults it synthesized such
+// as APP_ENV=local, sorted by name. Values are quoted and escaped for the target, so shell output
+// can be passed to eval and dotenv output reads back unchanged. ExportKubernetes puts keys with a
+// secret-looking part, such as PASSWORD, TOKEN, SECRET, or KEY, into the Secret. Export writes
+// nothing before Load. It returns an error for an unknown format, for a multi-line value in
+// ExportDocker, or for a dotted key such as app.name in ExportShell, ExportFish, or
+// ExportPowerShell, before writing anything.
+//
+// Example: hand loaded values to a shell
+//
+//	tmp, _ := os.MkdirTemp("", "envdoc")
+//	defer os.RemoveAll(tmp)
+//	originalDirectory, _ := os.Getwd()
+//	defer os.Chdir(originalDirectory)
+//	_ = os.Chdir(tmp)
+//	_ = os.WriteFile(filepath.Join(tmp, ".env"), []byte("APP_ENV=local\nGREETING=\"it's here\""), 0o644)
+//
+//	_ = env.Load()
+//	var shell strings.Builder
+//	_ = env.Export(&shell, env.ExportShell)
+//	fmt.Print(shell.String())
+//	// export APP_ENV='local'
+//	// export GREETING='it'\''s here'
+func Export(w io.Writer, format ExportFormat) error {
+	processEnvironmentLoader.mu.Lock()
+	values := make(map[string]string, len(processEnvironmentLoader.values)+len(processEnvironmentLoader.defaults))
+	for _, owned := range []map[string]loadedEnvironmentValue{processEnvironmentLoader.values, processEnvironmentLoader.defaults} {
+		for key := range owned {
+			if value, present := envLookup(key); present {
+				values[key] = value
+			}
+		}
+	}
+	processEnvironmentLoader.mu.Unlock()
+	return writeExport(w, format, values)
+}
+
+// Export writes the variables env files supplied, in format, like the package-level Export.
+// @group Isolated environment
+// @behavior readonly
+//
+// Ambient values from LoadIntoOptions.Environ are left out unless a file set them, so the output
+// holds the project's configuration rather than the caller's whole environment.
+//
+// Example: render a docker --env-file
+//
+//	tmp, _ := os.MkdirTemp("", "envdoc")
+//	defer os.RemoveAll(tmp)
+//	_ = os.WriteFile(filepath.Join(tmp, ".env"), []byte("PORT=8080\nLOG_LEVEL=info"), 0o644)
+//
+//	project, _ := env.LoadInto(tmp, env.LoadIntoOptions{Environ: []string{"HOME=/root"}})
+//	var dockerEnv strings.Builder
+//	_ = project.Export(&dockerEnv, env.ExportDocker)
+//	fmt.Print(dockerEnv.String())
+//	// LOG_LEVEL=info
+//	// PORT=8080
+func (e *Environment) Export(w io.Writer, format ExportFormat) error {
+	values := make(map[string]string, len(e.sources))
+	for key := range e.sources {
+		values[key] = e.values[key]
+	}
+	return writeExport(w, format, values)
+}
+
+// writeExport renders values in format into a buffer and writes it in one call, so an error
+// leaves w untouched.
+func writeExport(w io.Writer, format ExportFormat, values map[string]string) error {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	switch format {
+	case ExportShell, ExportFish, ExportPowerShell:
+		for _, key := range keys {
+			if !isShellEnvName(key) {
+				return fmt.Errorf("export env as %s: %s is not a valid %s variable name", format, key, format)
+			}
+		}
+	}
+
+	var out bytes.Buffer
+	switch format {
+	case ExportDotenv:
+		for _, key := range keys {
+			fmt.Fprintf(&out, "%s=%s\n", key, quoteDotenvValue(values[key]))
+		}
+	case ExportShell:
+		for _, key := range keys {
+			fmt.Fprintf(&out, "export %s='%s'\n", key, strings.ReplaceAll(values[key], "'", `'\''`))
+		}
+	case ExportFish:
+		escaper := strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+		for _, key := range keys {
+			fmt.Fprintf(&out, "set -gx %s '%s'\n", key, escaper.Replace(values[key]))
+		}
+	case ExportPowerShell:
+		// PowerShell also ends single-quoted strings at typographic single quotes.
+		escaper := strings.NewReplacer("'", "''", "‘", "‘‘", "’", "’’", "‚", "‚‚", "‛", "‛‛")
+		for _, key := range keys {
+			fmt.Fprintf(&out, "$env:%s = '%s'\n", key, escaper.Replace(values[key]))
+		}
+	case ExportJSON:
+		encoder := json.NewEncoder(&out)
+		encoder.SetEscapeHTML(false)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(values); err != nil {
+			return fmt.Errorf("export env as %s: %w", format, err)
+		}
+	case ExportDocker:
+		for _, key := range keys {
+			if strings.ContainsAny(values[key], "\r\n") {
+				return fmt.Errorf("export env as %s: %s has a multi-line value, which docker --env-file cannot hold", format, key)
+			}
+			fmt.Fprintf(&out, "%s=%s\n", key, values[key])
+		}
+	case ExportGitHubActions:
+		for _, key := range keys {
+			writeGitHubEnvValue(&out, key, values[key])
+		}
+	case ExportKubernetes:
+		if err := writeKubernetesEnv(&out, keys, values); err != nil {
+			return fmt.Errorf("export env as %s: %w", format, err)
+		}
+	default:
+		return fmt.Errorf("export env: unsupported format %q", format)
+	}
+	_, err := w.Write(out.Bytes())
+	return err
+}
+
+// isShellEnvName reports whether a shell can assign key: env files also allow dots, such as
+// app.name, which shells, fish, and PowerShell read as something other than a variable name.
+func isShellEnvName(key string) bool {
+	return isValidEnvKey(key) && !strings.Contains(key, ".")
+}
+
+// quoteDotenvValue leaves plain values bare and double-quotes the rest with the escapes Parse
+// decodes; \$ keeps Load from interpolating.
+func quoteDotenvValue(value string) string {
+	plain := true
+	for index := 0; index < len(value); index++ {
+		current := value[index]
+		if !isEnvKeyPart(current) && !strings.ContainsRune("./:@%+,=-", rune(current)) {
+			plain = false
+			break
+		}
+	}
+	if plain {
+		return value
+	}
+	escaper := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`, "$", `\$`)
+	return `"` + escaper.Replace(value) + `"`
+}
+
+// writeGitHubEnvValue writes KEY=value, or the KEY<<delimiter form GitHub Actions requires for
+// multi-line values, with a delimiter the value does not contain.
+func writeGitHubEnvValue(out *bytes.Buffer, key, value string) {
+	if !strings.ContainsAny(value, "\r\n") {
+		fmt.Fprintf(out, "%s=%s\n", key, value)
+		return
+	}
+	delimiter := "ENV_EOF"
+	for attempt := 1; strings.Contains(value, delimiter); attempt++ {
+		delimiter = "ENV_EOF_" + strconv.Itoa(attempt)
+	}
+	fmt.Fprintf(out, "%s<<%s\n%s\n%s\n", key, delimiter, value, delimiter)
+}
+
+// kubernetesEnvObject is the subset of a ConfigMap or Secret that Export writes.
+type kubernetesEnvObject struct {
+	APIVersion string            `yaml:"apiVersion"`
+	Kind       string            `yaml:"kind"`
+	Metadata   map[string]string `yaml:"metadata"`
+	Type       string            `yaml:"type,omitempty"`
+	Data       map[string]string `yaml:"data"`
+}
+
+// writeKubernetesEnv writes a ConfigMap of plain keys and, when any key looks secret, a Secret
+// holding those keys base64-encoded.
+func writeKubernetesEnv(out *bytes.Buffer, keys []string, values map[string]string) error {
+	plain := map[string]string{}
+	secret := map[string]string{}
+	for _, key := range keys {
+		if isSecretEnvKey(key) {
+			secret[key] = base64.StdEncoding.EncodeToString([]byte(values[key]))
+		} else {
+			plain[key] = values[key]
+		}
+	}
+
+	encoder := yaml.NewEncoder(out)
+	encoder.SetIndent(2)
+	objects := []kubernetesEnvObject{{APIVersion: "v1", Kind: "ConfigMap", Metadata: map[string]string{"name": "env"}, Data: plain}}
+	if len(secret) > 0 {
+		objects = append(objects, kubernetesEnvObject{APIVersion: "v1", Kind: "Secret", Metadata: map[string]string{"name": "env"}, Type: "Opaque", Data: secret})
+	}
+	for _, object := range objects {
+		if err := encoder.Encode(object); err != nil {
+			return err
+		}
+	}
+	return encoder.Close()
+}
+
+// isSecretEnvKey reports whether an underscore-separated part of key, such as PASSWORD in
+// DB_PASSWORD, suggests a credential.
+func isSecretEnvKey(key string) bool {
+	for _, part := range strings.Split(strings.ToUpper(key), "_") {
+		if _, ok := secretKeySegments[part]; ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,194 @@
+package env
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// exportTestValues covers the characters each target has to quote or escape.
+var exportTestValues = map[string]string{
+	"APP_NAME":    "demo",
+	"DB_PASSWORD": "p'a\"s$s\\word",
+	"GREETING":    "hello, world # not a comment",
+	"MOTD":        "line one\nline two\tEOF",
+	"URL":         "https://example.com/a?b=c&d=<e>",
+	"EMPTY":       "",
+	"QUOTES":      "it’s",
+}
+
+// TestWriteExportQuotesEachFormat ensures every format escapes awkward values for its target.
+func TestWriteExportQuotesEachFormat(t *testing.T) {
+	values := map[string]string{"A": "it's $HOME", "B": "‘x’", "C": "two\nlines"}
+	tests := []struct {
+		format ExportFormat
+		want   string
+	}{
+		{ExportDotenv, "A=\"it's \\$HOME\"\nB=\"‘x’\"\nC=\"two\\nlines\"\n"},
+		{ExportShell, "export A='it'\\''s $HOME'\nexport B='‘x’'\nexport C='two\nlines'\n"},
+		{ExportFish, "set -gx A 'it\\'s $HOME'\nset -gx B '‘x’'\nset -gx C 'two\nlines'\n"},
+		{ExportPowerShell, "$env:A = 'it''s $HOME'\n$env:B = '‘‘x’’'\n$env:C = 'two\nlines'\n"},
+		{ExportJSON, "{\n  \"A\": \"it's $HOME\",\n  \"B\": \"‘x’\",\n  \"C\": \"two\\nlines\"\n}\n"},
+		{ExportGitHubActions, "A=it's $HOME\nB=‘x’\nC<<ENV_EOF\ntwo\nlines\nENV_EOF\n"},
+	}
+	for _, test := range tests {
+		t.Run(string(test.format), func(t *testing.T) {
+			var out strings.Builder
+			if err := writeExport(&out, test.format, values); err != nil {
+				t.Fatalf("export: %v", err)
+			}
+			if out.String() != test.want {
+				t.Fatalf("expected:\n%s\ngot:\n%s", test.want, out.String())
+			}
+		})
+	}
+}
+
+// TestWriteExportDotenvRoundTrips ensures dotenv and JSON output parse back to the exported values.
+func TestWriteExportDotenvRoundTrips(t *testing.T) {
+	var dotenv strings.Builder
+	if err := writeExport(&dotenv, ExportDotenv, exportTestValues); err != nil {
+		t.Fatalf("export dotenv: %v", err)
+	}
+	parsed, err := Parse(strings.NewReader(dotenv.String()), ParseOptions{})
+	if err != nil {
+		t.Fatalf("parse exported dotenv: %v\n%s", err, dotenv.String())
+	}
+	if !reflect.DeepEqual(parsed, exportTestValues) {
+		t.Fatalf("expected %v, got %v", exportTestValues, parsed)
+	}
+
+	var document strings.Builder
+	if err := writeExport(&document, ExportJSON, exportTestValues); err != nil {
+		t.Fatalf("export json: %v", err)
+	}
+	var decoded map[string]string
+	if err := json.Unmarshal([]byte(document.String()), &decoded); err != nil || !reflect.DeepEqual(decoded, exportTestValues) {
+		t.Fatalf("expected JSON to round-trip, got %v (%v)", decoded, err)
+	}
+}
+
+// TestWriteExportSplitsKubernetesSecrets ensures secret-looking keys move into a base64 Secret.
+func TestWriteExportSplitsKubernetesSecrets(t *testing.T) {
+	var out strings.Builder
+	values := map[string]string{"APP_NAME": "demo", "DB_PASSWORD": "hunter2", "MONKEY": "banana"}
+	if err := writeExport(&out, ExportKubernetes, values); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	want := "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: env\ndata:\n  APP_NAME: demo\n  MONKEY: banana\n" +
+		"---\napiVersion: v1\nkind: Secret\nmetadata:\n  name: env\ntype: Opaque\ndata:\n  DB_PASSWORD: aHVudGVyMg==\n"
+	if out.String() != want {
+		t.Fatalf("expected:\n%s\ngot:\n%s", want, out.String())
+	}
+
+	out.Reset()
+	if err := writeExport(&out, ExportKubernetes, map[string]string{"PORT": "8080"}); err != nil || strings.Contains(out.String(), "Secret") {
+		t.Fatalf("expected no Secret without secret keys, got %q (%v)", out.String(), err)
+	}
+	for key, want := range map[string]bool{"API_KEY": true, "GITHUB_TOKEN": true, "auth_header": true, "KEYBOARD": false, "PASSTHROUGH": false} {
+		if got := isSecretEnvKey(key); got != want {
+			t.Fatalf("isSecretEnvKey(%q) = %v, want %v", key, got, want)
+		}
+	}
+}
+
+// TestWriteExportRejectsUnrepresentableValues ensures errors leave the writer untouched.
+func TestWriteExportRejectsUnrepresentableValues(t *testing.T) {
+	var out strings.Builder
+	if err := writeExport(&out, ExportDocker, map[string]string{"A": "1", "B": "two\nlines"}); err == nil || !strings.Contains(err.Error(), "B has a multi-line value") {
+		t.Fatalf("expected a multi-line docker error, got %v", err)
+	}
+	if err := writeExport(&out, "ini", map[string]string{"A": "1"}); err == nil || !strings.Contains(err.Error(), `unsupported format "ini"`) {
+		t.Fatalf("expected an unsupported format error, got %v", err)
+	}
+	if out.Len() != 0 {
+		t.Fatalf("expected nothing written, got %q", out.String())
+	}
+
+	if err := writeExport(&out, ExportGitHubActions, map[string]string{"A": "ENV_EOF\nENV_EOF_1"}); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if want := "A<<ENV_EOF_2\nENV_EOF\nENV_EOF_1\nENV_EOF_2\n"; out.String() != want {
+		t.Fatalf("expected a delimiter missing from the value, got %q", out.String())
+	}
+
+	failing := errors.New("closed")
+	if err := writeExport(failingWriter{failing}, ExportDotenv, map[string]string{"A": "1"}); !errors.Is(err, failing) {
+		t.Fatalf("expected the write error, got %v", err)
+	}
+	if len(ExportFormats()) != 8 {
+		t.Fatalf("expected every format listed, got %v", ExportFormats())
+	}
+}
+
+// TestWriteExportRejectsKeysTheTargetCannotAssign ensures dotted keys fail for shells and still export elsewhere.
+func TestWriteExportRejectsKeysTheTargetCannotAssign(t *testing.T) {
+	values := map[string]string{"APP_NAME": "demo", "app.name": "demo"}
+	for _, format := range []ExportFormat{ExportShell, ExportFish, ExportPowerShell} {
+		var out strings.Builder
+		err := writeExport(&out, format, values)
+		if want := "app.name is not a valid " + string(format) + " variable name"; err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("%s: expected %q, got %v", format, want, err)
+		}
+		if out.Len() != 0 {
+			t.Fatalf("%s: expected nothing written, got %q", format, out.String())
+		}
+	}
+	for _, format := range []ExportFormat{ExportDotenv, ExportJSON, ExportDocker, ExportGitHubActions, ExportKubernetes} {
+		var out strings.Builder
+		if err := writeExport(&out, format, values); err != nil || !strings.Contains(out.String(), "app.name") {
+			t.Fatalf("%s: expected the dotted key to export, got %q %v", format, out.String(), err)
+		}
+	}
+}
+
+// failingWriter rejects every write.
+type failingWriter struct{ err error }
+
+func (w failingWriter) Write([]byte) (int, error) { return 0, w.err }
+
+// TestExportUsesLoadedValues ensures Export covers loaded keys and defaults at their current values only.
+func TestExportUsesLoadedValues(t *testing.T) {
+	prepareLoaderTest(t, "ENV_QEXPORT_PORT", "ENV_QEXPORT_AMBIENT")
+	_ = os.Unsetenv("APP_ENV")
+	_ = os.Unsetenv("ENV_QEXPORT_PORT")
+	t.Setenv("ENV_QEXPORT_AMBIENT", "kept out")
+	directory := t.TempDir()
+	writeEnvFile(t, directory, fileEnv, "ENV_QEXPORT_PORT=8080\nENV_QEXPORT_AMBIENT=from file\n")
+	changeWorkingDirectory(t, directory)
+
+	var out strings.Builder
+	if err := Export(&out, ExportDotenv); err != nil || out.Len() != 0 {
+		t.Fatalf("expected nothing before Load, got %q (%v)", out.String(), err)
+	}
+	if err := Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	t.Setenv("ENV_QEXPORT_PORT", "9090")
+	if err := Export(&out, ExportDotenv); err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	if want := "APP_ENV=local\nENV_QEXPORT_PORT=9090\n"; out.String() != want {
+		t.Fatalf("expected %q, got %q", want, out.String())
+	}
+}
+
+// TestEnvironmentExportUsesFileValues ensures an isolated Environment exports only file-supplied keys.
+func TestEnvironmentExportUsesFileValues(t *testing.T) {
+	directory := t.TempDir()
+	writeEnvFile(t, directory, fileEnv, "PORT=8080\nHOME=/srv\n")
+	environment, err := LoadInto(directory, LoadIntoOptions{Environ: []string{"HOME=/root", "USER=app"}})
+	if err != nil {
+		t.Fatalf("LoadInto: %v", err)
+	}
+	var out strings.Builder
+	if err := environment.Export(&out, ExportShell); err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	if want := "export PORT='8080'\n"; out.String() != want {
+		t.Fatalf("expected %q, got %q", want, out.String())
+	}
+}
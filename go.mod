@@ -3,6 +3,7 @@ module github.com/goforj/env/v2
 go 1.24.4
 
 require (
+	github.com/BurntSushi/toml v1.6.0
 	github.com/goforj/godump v1.7.1
-	github.com/joho/godotenv v1.5.1
+	gopkg.in/yaml.v3 v3.0.1
 )
@@ -0,0 +1,96 @@
+package env
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// maxEnvIncludeDepth bounds how many files an #include chain may nest below the file that starts it.
+const maxEnvIncludeDepth = 8
+
+// envInclude is one #include target, kept for ENV_DEBUG output and Watch.
+//
+// found is false for an optional target that does not exist; Watch still tracks it so creating the
+// file triggers a reload.
+type envInclude struct {
+	path  string
+	from  string
+	found bool
+}
+
+// expandEnvIncludes replaces every #include entry of the file at path with the entries it names.
+//
+// Targets resolve relative to the including file and pass the same regular-file check as
+// findEnvFile. chain lists the files being expanded, outermost first, to detect cycles, and
+// includes receives every target in read order. Entries from an included file take the section of
+// the directive that included them; included files may not declare sections of their own.
+func expandEnvIncludes(path string, entries []envEntry, chain []string, includes *[]envInclude) ([]envEntry, error) {
+	expanded := make([]envEntry, 0, len(entries))
+	for _, entry := range entries {
+		if entry.include == "" {
+			expanded = append(expanded, entry)
+			continue
+		}
+
+		target := entry.include
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(filepath.Dir(path), target)
+		}
+		target = filepath.Clean(target)
+		if slices.Contains(chain, target) {
+			return nil, fmt.Errorf("env file %s line %d: include cycle %s", path, entry.line, strings.Join(append(slices.Clone(chain), target), " -> "))
+		}
+		if len(chain) > maxEnvIncludeDepth {
+			return nil, fmt.Errorf("env file %s line %d: includes nest deeper than %d files", path, entry.line, maxEnvIncludeDepth)
+		}
+		found, err := statEnvFile(target)
+		if err != nil {
+			return nil, fmt.Errorf("env file %s line %d: %w", path, entry.line, err)
+		}
+		*includes = append(*includes, envInclude{path: target, from: path, found: found})
+		if !found {
+			if entry.optional {
+				continue
+			}
+			return nil, fmt.Errorf("env file %s line %d: included env file %s does not exist", path, entry.line, target)
+		}
+
+		included, err := envFileRead(target)
+		if err != nil {
+			return nil, fmt.Errorf("read env file %s: %w", target, err)
+		}
+		for _, includedEntry := range included {
+			if includedEntry.section != "" {
+				return nil, &SyntaxError{
+					File:    target,
+					Line:    includedEntry.sectionLine,
+					Column:  includedEntry.sectionColumn,
+					Message: fmt.Sprintf("section [%s] is not allowed in an included file", includedEntry.section),
+				}
+			}
+		}
+		included, err = expandEnvIncludes(target, included, append(slices.Clone(chain), target), includes)
+		if err != nil {
+			return nil, err
+		}
+		for _, includedEntry := range included {
+			includedEntry.section = entry.section
+			includedEntry.sectionLine = entry.sectionLine
+			includedEntry.sectionColumn = entry.sectionColumn
+			expanded = append(expanded, includedEntry)
+		}
+	}
+	return expanded, nil
+}
+
+// mergeEnvIncludes records the includes of file so Watch tracks them and ENV_DEBUG lists them.
+func mergeEnvIncludes(plan *environmentLoadPlan, file environmentFile) {
+	for _, include := range file.includes {
+		plan.watched = append(plan.watched, include.path)
+		if include.found {
+			plan.includes = append(plan.includes, include)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// TestLoadExpandsIncludesInPlace ensures included keys apply at the directive, relative to the including file.
+func TestLoadExpandsIncludesInPlace(t *testing.T) {
+	prepareLoaderTest(t, "ENV_QPASS_INC_A", "ENV_QPASS_INC_B", "ENV_QPASS_INC_C", "ENV_DEBUG")
+	t.Setenv("APP_ENV", Local)
+	for _, key := range []string{"ENV_QPASS_INC_A", "ENV_QPASS_INC_B", "ENV_QPASS_INC_C"} {
+		_ = os.Unsetenv(key)
+	}
+	root := t.TempDir()
+	service := filepath.Join(root, "services", "api")
+	if err := os.MkdirAll(service, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	shared := filepath.Join(root, "services", ".env.common")
+	nested := filepath.Join(root, ".env.root")
+	writeEnvFile(t, filepath.Dir(shared), filepath.Base(shared), "ENV_QPASS_INC_A=shared\nENV_QPASS_INC_B=shared\n#include ../.env.root\n")
+	writeEnvFile(t, root, filepath.Base(nested), "ENV_QPASS_INC_C=root\n")
+	writeEnvFile(t, service, fileEnv, "ENV_QPASS_INC_A=before\n  #include ../.env.common # shared\n#include? .env.missing\n#includes are comments\nENV_QPASS_INC_B=after\n")
+	changeWorkingDirectoryWithin(t, service, root)
+	t.Setenv("ENV_DEBUG", "3")
+
+	output := captureStdout(t, func() {
+		if err := Load(); err != nil {
+			t.Fatalf("Load: %v", err)
+		}
+	})
+	if os.Getenv("ENV_QPASS_INC_A") != "shared" || os.Getenv("ENV_QPASS_INC_B") != "after" || os.Getenv("ENV_QPASS_INC_C") != "root" {
+		t.Fatalf("unexpected values a=%q b=%q c=%q",
+			os.Getenv("ENV_QPASS_INC_A"), os.Getenv("ENV_QPASS_INC_B"), os.Getenv("ENV_QPASS_INC_C"))
+	}
+	for _, want := range []string{
+		fmt.Sprintf("include [%s] from [%s]", shared, filepath.Join(service, fileEnv)),
+		fmt.Sprintf("include [%s] from [%s]", nested, shared),
+	} {
+		if !strings.Contains(output, want) {
+			t.Fatalf("expected debug output to contain %q, got %q", want, output)
+		}
+	}
+	if strings.Contains(output, "shared]") || strings.Contains(output, ".env.missing") {
+		t.Fatalf("expected debug output without values or missing optional files, got %q", output)
+	}
+	watched := watchedEnvFiles()
+	for _, path := range []string{shared, nested, filepath.Join(service, ".env.missing")} {
+		if !slices.Contains(watched, path) {
+			t.Fatalf("expected %s to be watched, got %v", path, watched)
+		}
+	}
+}
+
+// TestLoadRejectsInvalidIncludes ensures missing, cyclic, deep, non-regular, and sectioned includes fail the load.
+func TestLoadRejectsInvalidIncludes(t *testing.T) {
+	prepareLoaderTest(t, "ENV_QPASS_INC_A")
+	t.Setenv("APP_ENV", Local)
+	_ = os.Unsetenv("ENV_QPASS_INC_A")
+
+	deep := t.TempDir()
+	for level := 0; level <= maxEnvIncludeDepth; level++ {
+		writeEnvFile(t, deep, fmt.Sprintf("level%d.env", level), fmt.Sprintf("#include level%d.env\n", level+1))
+	}
+	writeEnvFile(t, deep, fmt.Sprintf("level%d.env", maxEnvIncludeDepth+1), "ENV_QPASS_INC_A=deep\n")
+
+	tests := []struct {
+		name    string
+		files   map[string]string
+		message string
+	}{
+		{name: "missing", files: map[string]string{fileEnv: "#include .env.shared\n"}, message: "included env file"},
+		{name: "cycle", files: map[string]string{fileEnv: "#include a.env\n", "a.env": "#include b.env\n", "b.env": "#include a.env\n"}, message: "include cycle"},
+		{name: "self", files: map[string]string{fileEnv: "#include .env\n"}, message: "include cycle"},
+		{name: "depth", files: map[string]string{fileEnv: "#include " + filepath.Join(deep, "level0.env") + "\n"}, message: "nest deeper than"},
+		{name: "directory", files: map[string]string{fileEnv: "#include? .\n"}, message: "is not a regular file"},
+		{name: "section", files: map[string]string{fileEnv: "#include a.env\n", "a.env": "[production]\nENV_QPASS_INC_A=prod\n"}, message: "not allowed in an included file"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			directory := t.TempDir()
+			for name, contents := range test.files {
+				writeEnvFile(t, directory, name, contents)
+			}
+			changeWorkingDirectoryWithin(t, directory, filepath.Dir(directory))
+			envFileStat = os.Stat
+			err := Reload()
+			if err == nil || !strings.Contains(err.Error(), test.message) {
+				t.Fatalf("expected an error containing %q, got %v", test.message, err)
+			}
+			if _, ok := os.LookupEnv("ENV_QPASS_INC_A"); ok {
+				t.Fatal("expected a failed load to leave the environment unchanged")
+			}
+		})
+	}
+}
+
+// TestLoadReaderRejectsIncludes ensures sources without a directory report #include as a syntax error.
+func TestLoadReaderRejectsIncludes(t *testing.T) {
+	prepareLoaderTest(t)
+	changeWorkingDirectory(t, t.TempDir())
+
+	err := LoadReader("stdin", strings.NewReader("A=1\n#include? shared.env\n"))
+	var syntaxErr *SyntaxError
+	if !errors.As(err, &syntaxErr) || syntaxErr.File != "stdin" || syntaxErr.Line != 2 || syntaxErr.Column != 1 {
+		t.Fatalf("expected a positioned syntax error, got %v", err)
+	}
+}
@@ -0,0 +1,242 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// interpolationError names the file and key whose value could not be expanded.
+type interpolationError struct {
+	file string
+	key  string
+	err  error
+}
+
+// Error reports the key and file without including any value.
+func (e *interpolationError) Error() string {
+	return fmt.Sprintf("interpolate %s in %s: %v", e.key, e.file, e.err)
+}
+
+// Unwrap exposes the underlying cause.
+func (e *interpolationError) Unwrap() error {
+	return e.err
+}
+
+// environmentInterpolator resolves references across every merged layer and the ambient environment.
+type environmentInterpolator struct {
+	plan     *environmentLoadPlan
+	previous map[string]loadedEnvironmentValue
+	resolved map[string]string
+	visiting map[string]bool
+	stack    []string
+}
+
+// interpolateEnvironmentPlan expands ${...} references in file values once every layer is merged.
+//
+// References see the final layered file values, then the ambient process values that Load keeps,
+// then loader defaults, so a value in .env can use a key defined in a later layer.
+func interpolateEnvironmentPlan(plan *environmentLoadPlan, previous map[string]loadedEnvironmentValue) error {
+	interpolator := &environmentInterpolator{
+		plan:     plan,
+		previous: previous,
+		resolved: make(map[string]string, len(plan.templates)),
+		visiting: make(map[string]bool),
+	}
+
+	keys := make([]string, 0, len(plan.templates))
+	for key := range plan.templates {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if _, err := interpolator.resolveKey(key); err != nil {
+			return err
+		}
+	}
+	for key, value := range interpolator.resolved {
+		plan.fileValues[key] = value
+	}
+	return nil
+}
+
+// resolveKey returns the expanded value of a file key, detecting reference cycles.
+func (i *environmentInterpolator) resolveKey(key string) (string, error) {
+	if value, ok := i.resolved[key]; ok {
+		return value, nil
+	}
+	template, ok := i.plan.templates[key]
+	if !ok {
+		return i.plan.fileValues[key], nil
+	}
+	if i.visiting[key] {
+		cycle := append(i.cycleFrom(key), key)
+		return "", &interpolationError{
+			file: i.plan.sources[key],
+			key:  key,
+			err:  fmt.Errorf("reference cycle %s", strings.Join(cycle, " -> ")),
+		}
+	}
+
+	i.visiting[key] = true
+	i.stack = append(i.stack, key)
+	value, err := expandEnvironmentTemplate(template, i.lookup)
+	i.stack = i.stack[:len(i.stack)-1]
+	delete(i.visiting, key)
+	if err != nil {
+		var nested *interpolationError
+		if errors.As(err, &nested) {
+			return "", err
+		}
+		return "", &interpolationError{file: i.plan.sources[key], key: key, err: err}
+	}
+	i.resolved[key] = value
+	return value, nil
+}
+
+// cycleFrom returns the reference chain that starts at key.
+func (i *environmentInterpolator) cycleFrom(key string) []string {
+	for index, candidate := range i.stack {
+		if candidate == key {
+			return append([]string(nil), i.stack[index:]...)
+		}
+	}
+	return nil
+}
+
+// lookup resolves a referenced name to the value it will have after the plan is applied.
+func (i *environmentInterpolator) lookup(name string) (string, bool, error) {
+	if _, ok := i.plan.fileValues[name]; ok {
+		value, err := i.resolveKey(name)
+		return value, true, err
+	}
+	if snapshot := originalEnvironmentSnapshot(name, i.previous, i.plan.lookup); snapshot.present {
+		return snapshot.value, true, nil
+	}
+	value, ok := i.plan.defaults[name]
+	return value, ok, nil
+}
+
+// expandEnvironmentTemplate expands ${VAR}, ${VAR:-default}, ${VAR:?message}, $VAR, and $$.
+//
+// Like godotenv, an unbraced $VAR name uses uppercase letters, digits, and underscores, so a value
+// such as pa$word stays literal. A $ that does not start one of those forms is kept literally.
+// Defaults may contain references and are only expanded when used.
+func expandEnvironmentTemplate(
+	template string,
+	lookup func(string) (string, bool, error),
+) (string, error) {
+	var expanded strings.Builder
+	for index := 0; index < len(template); {
+		current := template[index]
+		switch {
+		case current != '$':
+			expanded.WriteByte(current)
+			index++
+		case strings.HasPrefix(template[index:], "$$"):
+			expanded.WriteByte('$')
+			index += 2
+		case strings.HasPrefix(template[index:], "${"):
+			value, width, err := expandEnvironmentReference(template[index:], lookup)
+			if err != nil {
+				return "", err
+			}
+			expanded.WriteString(value)
+			index += width
+		case index+1 < len(template) && isUnbracedNameStart(template[index+1]):
+			nameEnd := index + 2
+			for nameEnd < len(template) && isUnbracedNamePart(template[nameEnd]) {
+				nameEnd++
+			}
+			value, _, err := lookup(template[index+1 : nameEnd])
+			if err != nil {
+				return "", err
+			}
+			expanded.WriteString(value)
+			index = nameEnd
+		default:
+			expanded.WriteByte(current)
+			index++
+		}
+	}
+	return expanded.String(), nil
+}
+
+// isUnbracedNameStart reports whether c may start a $VAR name.
+func isUnbracedNameStart(c byte) bool {
+	return c == '_' || 'A' <= c && c <= 'Z'
+}
+
+// isUnbracedNamePart reports whether c may continue a $VAR name.
+func isUnbracedNamePart(c byte) bool {
+	return isUnbracedNameStart(c) || '0' <= c && c <= '9'
+}
+
+// expandEnvironmentReference expands the ${...} reference at the start of text and returns its width.
+func expandEnvironmentReference(
+	text string,
+	lookup func(string) (string, bool, error),
+) (string, int, error) {
+	nameStart := len("${")
+	nameEnd := nameStart
+	for nameEnd < len(text) && isEnvKeyPart(text[nameEnd]) {
+		nameEnd++
+	}
+	name := text[nameStart:nameEnd]
+	if name == "" || !isEnvKeyStart(name[0]) {
+		return "", 0, errors.New("invalid variable name in reference")
+	}
+
+	end := matchingReferenceBrace(text)
+	if end < 0 {
+		return "", 0, fmt.Errorf("unterminated reference to %s", name)
+	}
+	operator, argument := "", ""
+	switch rest := text[nameEnd:end]; {
+	case rest == "":
+	case strings.HasPrefix(rest, ":-"), strings.HasPrefix(rest, ":?"):
+		operator, argument = rest[:2], rest[2:]
+	default:
+		return "", 0, fmt.Errorf("unsupported modifier in reference to %s", name)
+	}
+
+	value, present, err := lookup(name)
+	if err != nil {
+		return "", 0, err
+	}
+	if present && value != "" || operator == "" {
+		return value, end + 1, nil
+	}
+	if operator == ":?" {
+		if argument == "" {
+			return "", 0, fmt.Errorf("%s is required", name)
+		}
+		return "", 0, fmt.Errorf("%s: %s", name, argument)
+	}
+	fallback, err := expandEnvironmentTemplate(argument, lookup)
+	if err != nil {
+		return "", 0, err
+	}
+	return fallback, end + 1, nil
+}
+
+// matchingReferenceBrace returns the index of the brace closing the reference that opens text.
+func matchingReferenceBrace(text string) int {
+	depth := 0
+	for index := 0; index < len(text); index++ {
+		switch {
+		case strings.HasPrefix(text[index:], "$$"):
+			index++
+		case strings.HasPrefix(text[index:], "${"):
+			depth++
+			index++
+		case text[index] == '}':
+			depth--
+			if depth == 0 {
+				return index
+			}
+		}
+	}
+	return -1
+}
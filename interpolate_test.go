@@ -0,0 +1,147 @@
+package env
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestLoadInterpolatesAcrossLayersAndProcess ensures references see later layers and ambient values.
+func TestLoadInterpolatesAcrossLayersAndProcess(t *testing.T) {
+	prepareLoaderTest(t, "ENV_QPASS_URL", "ENV_QPASS_USER", "ENV_QPASS_HOST", "ENV_QPASS_PORT", "ENV_QPASS_AMBIENT")
+	t.Setenv("APP_ENV", Production)
+	t.Setenv("ENV_QPASS_AMBIENT", "from-process")
+	for _, key := range []string{"ENV_QPASS_URL", "ENV_QPASS_USER", "ENV_QPASS_HOST", "ENV_QPASS_PORT"} {
+		_ = os.Unsetenv(key)
+	}
+	directory := t.TempDir()
+	writeEnvFile(t, directory, fileEnv, strings.Join([]string{
+		"ENV_QPASS_URL=postgres://${ENV_QPASS_USER}@${ENV_QPASS_HOST}:${ENV_QPASS_PORT:-5432}/${ENV_QPASS_AMBIENT}",
+		"ENV_QPASS_USER=app",
+		"ENV_QPASS_HOST=localhost",
+	}, "\n"))
+	writeEnvFile(t, directory, envFileProd, "ENV_QPASS_HOST=db.internal\n")
+	changeWorkingDirectory(t, directory)
+
+	if err := Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if got := os.Getenv("ENV_QPASS_URL"); got != "postgres://app@db.internal:5432/from-process" {
+		t.Fatalf("unexpected interpolated value %q", got)
+	}
+}
+
+// TestInterpolationQuotingAndEscapes ensures literal quotes and escapes keep dollar signs.
+func TestInterpolationQuotingAndEscapes(t *testing.T) {
+	prepareLoaderTest(t)
+	directory := t.TempDir()
+	writeEnvFile(t, directory, fileEnv, strings.Join([]string{
+		"NAME=world",
+		"UNQUOTED=hello ${NAME}",
+		`DOUBLE="hello ${NAME}"`,
+		`SINGLE='hello ${NAME}'`,
+		"BACKTICK=`hello ${NAME}`",
+		"DOLLARS=cost $$5 and ${NAME}",
+		`ESCAPED="\${NAME}"`,
+		"BARE=pa$word $NAME/$MISSING_1.",
+		"NESTED=${MISSING:-${NAME:-none}}",
+		"EMPTY_DEFAULT=${MISSING:-}",
+		"UNSET=${MISSING}",
+	}, "\n"))
+	changeWorkingDirectory(t, directory)
+
+	environment, err := LoadInto(directory, LoadIntoOptions{})
+	if err != nil {
+		t.Fatalf("LoadInto: %v", err)
+	}
+	want := map[string]string{
+		"UNQUOTED":      "hello world",
+		"DOUBLE":        "hello world",
+		"SINGLE":        "hello ${NAME}",
+		"BACKTICK":      "hello ${NAME}",
+		"DOLLARS":       "cost $5 and world",
+		"ESCAPED":       "${NAME}",
+		"BARE":          "pa$word world/.",
+		"NESTED":        "world",
+		"EMPTY_DEFAULT": "",
+		"UNSET":         "",
+	}
+	for key, value := range want {
+		if got := environment.Get(key, ""); got != value {
+			t.Errorf("%s: expected %q, got %q", key, value, got)
+		}
+	}
+}
+
+// TestInterpolationErrorsNameFileAndKey ensures failures identify where the bad reference lives.
+func TestInterpolationErrorsNameFileAndKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+		want     []string
+	}{
+		{name: "required", contents: "DB_PASS=${SECRET:?set SECRET first}\n", want: []string{"interpolate DB_PASS in", "SECRET: set SECRET first"}},
+		{name: "required without message", contents: "DB_PASS=${SECRET:?}\n", want: []string{"SECRET is required"}},
+		{name: "cycle", contents: "A=${B}\nB=${C}\nC=${A}\n", want: []string{"interpolate A in", "reference cycle A -> B -> C -> A"}},
+		{name: "self reference", contents: "A=x${A}\n", want: []string{"reference cycle A -> A"}},
+		{name: "unterminated", contents: "A=${B\n", want: []string{"interpolate A in", "unterminated reference to B"}},
+		{name: "invalid name", contents: "A=${1B}\n", want: []string{"invalid variable name in reference"}},
+		{name: "unsupported modifier", contents: "A=${B:=x}\n", want: []string{"unsupported modifier in reference to B"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			prepareLoaderTest(t)
+			directory := t.TempDir()
+			writeEnvFile(t, directory, fileEnv, test.contents)
+			changeWorkingDirectory(t, directory)
+
+			_, err := LoadInto(directory, LoadIntoOptions{})
+			if err == nil {
+				t.Fatal("expected interpolation error")
+			}
+			for _, fragment := range append(test.want, filepath.Join(directory, fileEnv)) {
+				if !strings.Contains(err.Error(), fragment) {
+					t.Fatalf("expected %q in %q", fragment, err.Error())
+				}
+			}
+		})
+	}
+}
+
+// TestInterpolationErrorNamesReferencedFile ensures a failure in another layer names that layer.
+func TestInterpolationErrorNamesReferencedFile(t *testing.T) {
+	prepareLoaderTest(t)
+	directory := t.TempDir()
+	writeEnvFile(t, directory, fileEnv, "APP_ENV=staging\nURL=${HOST}\n")
+	writeEnvFile(t, directory, envFileStaging, "HOST=${MISSING:?staging needs MISSING}\n")
+	changeWorkingDirectory(t, directory)
+
+	_, err := LoadInto(directory, LoadIntoOptions{})
+	if err == nil || !strings.Contains(err.Error(), "interpolate HOST in "+filepath.Join(directory, envFileStaging)) {
+		t.Fatalf("expected staging file and HOST key in error, got %v", err)
+	}
+}
+
+// TestReloadInterpolatesRefreshedValues ensures reloads expand against the newest layered values.
+func TestReloadInterpolatesRefreshedValues(t *testing.T) {
+	prepareLoaderTest(t, "ENV_QPASS_URL", "ENV_QPASS_HOST")
+	t.Setenv("APP_ENV", Local)
+	_ = os.Unsetenv("ENV_QPASS_URL")
+	_ = os.Unsetenv("ENV_QPASS_HOST")
+	directory := t.TempDir()
+	writeEnvFile(t, directory, fileEnv, "ENV_QPASS_URL=http://${ENV_QPASS_HOST}\nENV_QPASS_HOST=one\n")
+	changeWorkingDirectory(t, directory)
+	if err := Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	writeEnvFile(t, directory, fileEnv, "ENV_QPASS_URL=http://${ENV_QPASS_HOST}\nENV_QPASS_HOST=two\n")
+	if err := Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	if got := os.Getenv("ENV_QPASS_URL"); got != "http://two" {
+		t.Fatalf("expected refreshed interpolation, got %q", got)
+	}
+}
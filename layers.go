@@ -0,0 +1,190 @@
+package env
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+const (
+	envFileDefaults = ".env.defaults"
+	envFileOverride = ".env.override"
+
+	// envRootMarker marks the repository root where a cascading search starts.
+	envRootMarker = ".git"
+)
+
+// LayerOptions configures the defaults and override layers and how Load, Reload, and LoadInto
+// discover env files.
+type LayerOptions struct {
+	// DefaultsFile is searched and merged before .env, so every other file and every process
+	// value beats it. Empty means ".env.defaults".
+	DefaultsFile string
+	// OverrideFile is merged after every other layer and, for OverrideKeys, also beats process
+	// values, such as a stale variable exported by a shell. A key the application changed keeps its
+	// value on Reload even when the override layer sets it. Empty means ".env.override".
+	OverrideFile string
+	// OverrideKeys lists the keys, or path.Match patterns such as "FEATURE_*", the override layer
+	// may set. An override file that sets any other key fails the load.
+	OverrideKeys []string
+	// Cascade merges every file of each layer from the nearest directory holding a root marker
+	// down to the working directory, outermost first, so a service's .env overrides the shared
+	// repository-root .env instead of hiding it. Without a marker within the search bound, layers
+	// fall back to the nearest file. ENV_DEBUG=4 also prints the directory each key came from.
+	Cascade bool
+	// RootMarkers name the entries, such as ".git", whose directory is the repository root where a
+	// cascade starts. Empty means ".git".
+	RootMarkers []string
+	// Sections lets .env group keys under [name] headers. Keys before the first header apply
+	// everywhere; the section named after the canonical APP_ENV, each detected runtime layer such
+	// as [linux] or [kubernetes], and [testing] merge just before the matching .env.<name> file, so
+	// that file still wins. Headers in any other file, or without Sections, fail the load with a
+	// *SyntaxError. ENV_DEBUG labels these layers section:<name>.
+	Sections bool
+	// Formats lists structured formats, "yaml", "json", or "toml", whose siblings of every layer
+	// file, such as .env.yaml or .env.production.json, are searched like dotenv files and merge just
+	// before the dotenv file of the same layer, so .env still beats .env.yaml. Nested objects
+	// flatten into SCREAMING_SNAKE keys joined by underscores, so storage.public.root becomes
+	// STORAGE_PUBLIC_ROOT, and arrays of scalars join with commas for GetSlice. Values are literal
+	// and never interpolated. LoadFiles and ENV_FILE always decode these extensions.
+	Formats []string
+}
+
+// layerSettings guards the configured layer options; Load and LoadInto read them while planning.
+var layerSettings = struct {
+	mu      sync.RWMutex
+	options LayerOptions
+}{}
+
+// SetLayerOptions configures the layers and discovery rules used by Load, Reload, and LoadInto.
+// @group Environment loading
+// @behavior mutates-loader
+//
+// The options replace any set before and apply from the next load; LayerOptions describes each
+// one. The defaults and override layers are discovered like .env, applied transactionally, and
+// owned by the loader until application code changes a value. Filenames and root markers must be
+// plain names, and override key patterns must be valid for path.Match.
+//
+// Example: allow the override layer to fix a stale shell variable
+//
+//	tmp, _ := os.MkdirTemp("", "envdoc")
+//	defer os.RemoveAll(tmp)
+//	originalDirectory, _ := os.Getwd()
+//	defer os.Chdir(originalDirectory)
+//	_ = os.Chdir(tmp)
+//	_ = os.WriteFile(filepath.Join(tmp, ".env.defaults"), []byte("CACHE_TTL=60\nREGION=us-east-1"), 0o644)
+//	_ = os.WriteFile(filepath.Join(tmp, ".env.override"), []byte("REGION=eu-west-1"), 0o644)
+//	_ = os.Setenv("REGION", "stale")
+//
+//	_ = env.SetLayerOptions(env.LayerOptions{OverrideKeys: []string{"REGION"}})
+//	_ = env.Load()
+//	env.Dump(os.Getenv("CACHE_TTL"), os.Getenv("REGION"))
+//	// #string "60"
+//	// #string "eu-west-1"
+func SetLayerOptions(options LayerOptions) error {
+	for _, file := range []string{options.DefaultsFile, options.OverrideFile} {
+		if file != "" && (filepath.Base(file) != file || file == fileEnv) {
+			return fmt.Errorf("set env layer options: file %q must be a filename other than %s", file, fileEnv)
+		}
+	}
+	for _, marker := range options.RootMarkers {
+		if marker == "" || filepath.Base(marker) != marker {
+			return fmt.Errorf("set env layer options: root marker %q must be a plain name", marker)
+		}
+	}
+	for _, format := range options.Formats {
+		if _, ok := structuredEnvFormats[format]; !ok {
+			return fmt.Errorf("set env layer options: unknown format %q, want yaml, json, or toml", format)
+		}
+	}
+	for _, pattern := range options.OverrideKeys {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("set env layer options: invalid override key pattern %q: %w", pattern, err)
+		}
+	}
+
+	options.OverrideKeys = append([]string(nil), options.OverrideKeys...)
+	options.RootMarkers = append([]string(nil), options.RootMarkers...)
+	options.Formats = append([]string(nil), options.Formats...)
+	layerSettings.mu.Lock()
+	defer layerSettings.mu.Unlock()
+	layerSettings.options = options
+	return nil
+}
+
+// currentLayerOptions returns the configured options with default filenames filled in.
+func currentLayerOptions() LayerOptions {
+	layerSettings.mu.RLock()
+	defer layerSettings.mu.RUnlock()
+	options := layerSettings.options
+	if options.DefaultsFile == "" {
+		options.DefaultsFile = envFileDefaults
+	}
+	if options.OverrideFile == "" {
+		options.OverrideFile = envFileOverride
+	}
+	if len(options.RootMarkers) == 0 {
+		options.RootMarkers = []string{envRootMarker}
+	}
+	return options
+}
+
+// overrideKeyAllowed reports whether the override layer may set key.
+func (o LayerOptions) overrideKeyAllowed(key string) bool {
+	for _, pattern := range o.OverrideKeys {
+		if matched, _ := path.Match(pattern, key); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeOverrideLayer applies allowlisted keys over every layer and ambient value.
+//
+// Keys in released were file-owned until the application changed them, so they stay with the
+// application while set, just as they would for any other layer.
+func mergeOverrideLayer(
+	plan *environmentLoadPlan,
+	startDirectory string,
+	options LayerOptions,
+	released map[string]struct{},
+) error {
+	files, err := loadEnvLayer(plan, startDirectory, options.OverrideFile)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		for _, key := range sortedFileKeys(file) {
+			if !options.overrideKeyAllowed(key) {
+				return fmt.Errorf("env override file %s sets %s, which is not in LayerOptions.OverrideKeys", file.path, key)
+			}
+		}
+	}
+
+	for _, file := range files {
+		plan.files = append(plan.files, file.path)
+		plan.layers = append(plan.layers, layerOverride)
+		mergeEnvIncludes(plan, file)
+		for _, key := range sortedFileKeys(file) {
+			if _, taken := released[key]; taken {
+				if _, present := plan.lookup(key); present {
+					continue
+				}
+			}
+			setPlanFileValue(plan, file, key)
+		}
+	}
+	return nil
+}
+
+// sortedFileKeys returns the keys of file in a stable order for deterministic errors.
+func sortedFileKeys(file environmentFile) []string {
+	keys := make([]string, 0, len(file.values))
+	for key := range file.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,254 @@
+package env
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestDefaultsLayerOnlyFillsGaps ensures .env.defaults loses to every file and process value.
+func TestDefaultsLayerOnlyFillsGaps(t *testing.T) {
+	prepareLoaderTest(t, "ENV_QPASS_GAP", "ENV_QPASS_FILE", "ENV_QPASS_PROCESS")
+	t.Setenv("APP_ENV", Local)
+	t.Setenv("ENV_QPASS_PROCESS", "process")
+	_ = os.Unsetenv("ENV_QPASS_GAP")
+	_ = os.Unsetenv("ENV_QPASS_FILE")
+	directory := t.TempDir()
+	writeEnvFile(t, directory, envFileDefaults, "ENV_QPASS_GAP=default\nENV_QPASS_FILE=default\nENV_QPASS_PROCESS=default\n")
+	writeEnvFile(t, directory, envFileLocal, "ENV_QPASS_FILE=local\n")
+	changeWorkingDirectory(t, directory)
+
+	if err := Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if os.Getenv("ENV_QPASS_GAP") != "default" || os.Getenv("ENV_QPASS_FILE") != "local" || os.Getenv("ENV_QPASS_PROCESS") != "process" {
+		t.Fatalf("unexpected precedence gap=%q file=%q process=%q",
+			os.Getenv("ENV_QPASS_GAP"), os.Getenv("ENV_QPASS_FILE"), os.Getenv("ENV_QPASS_PROCESS"))
+	}
+
+	writeEnvFile(t, directory, envFileDefaults, "ENV_QPASS_GAP=default-2\n")
+	if err := Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	if got := os.Getenv("ENV_QPASS_GAP"); got != "default-2" {
+		t.Fatalf("expected defaults to stay file-owned, got %q", got)
+	}
+}
+
+// TestOverrideLayerBeatsProcessForAllowlistedKeys ensures the override layer fixes stale ambient values and restores them on Unload.
+func TestOverrideLayerBeatsProcessForAllowlistedKeys(t *testing.T) {
+	prepareLoaderTest(t, "ENV_QPASS_REGION", "ENV_QPASS_FLAG_A")
+	t.Setenv("APP_ENV", Local)
+	t.Setenv("ENV_QPASS_REGION", "stale")
+	_ = os.Unsetenv("ENV_QPASS_FLAG_A")
+	directory := t.TempDir()
+	writeEnvFile(t, directory, fileEnv, "ENV_QPASS_REGION=base\n")
+	writeEnvFile(t, directory, envFileOverride, "ENV_QPASS_REGION=eu-west-1\nENV_QPASS_FLAG_A=on\n")
+	changeWorkingDirectory(t, directory)
+	if err := SetLayerOptions(LayerOptions{OverrideKeys: []string{"ENV_QPASS_REGION", "ENV_QPASS_FLAG_*"}}); err != nil {
+		t.Fatalf("SetLayerOptions: %v", err)
+	}
+
+	if err := Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if os.Getenv("ENV_QPASS_REGION") != "eu-west-1" || os.Getenv("ENV_QPASS_FLAG_A") != "on" {
+		t.Fatalf("expected override values, got region=%q flag=%q", os.Getenv("ENV_QPASS_REGION"), os.Getenv("ENV_QPASS_FLAG_A"))
+	}
+
+	if err := Unload(); err != nil {
+		t.Fatalf("Unload: %v", err)
+	}
+	if got := os.Getenv("ENV_QPASS_REGION"); got != "stale" {
+		t.Fatalf("expected Unload to restore the shell value, got %q", got)
+	}
+}
+
+// TestOverrideLayerRespectsApplicationOwnership ensures Reload does not clobber a value the application changed.
+func TestOverrideLayerRespectsApplicationOwnership(t *testing.T) {
+	prepareLoaderTest(t, "ENV_QPASS_REGION")
+	t.Setenv("APP_ENV", Local)
+	t.Setenv("ENV_QPASS_REGION", "stale")
+	directory := t.TempDir()
+	writeEnvFile(t, directory, envFileOverride, "ENV_QPASS_REGION=override\n")
+	changeWorkingDirectory(t, directory)
+	if err := SetLayerOptions(LayerOptions{OverrideKeys: []string{"ENV_QPASS_REGION"}}); err != nil {
+		t.Fatalf("SetLayerOptions: %v", err)
+	}
+	if err := Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	writeEnvFile(t, directory, envFileOverride, "ENV_QPASS_REGION=override-2\n")
+	if err := Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	if got := os.Getenv("ENV_QPASS_REGION"); got != "override-2" {
+		t.Fatalf("expected unchanged override key to refresh, got %q", got)
+	}
+
+	_ = os.Setenv("ENV_QPASS_REGION", "application")
+	if err := Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	if got := os.Getenv("ENV_QPASS_REGION"); got != "application" {
+		t.Fatalf("expected application value to stay authoritative, got %q", got)
+	}
+
+	_ = os.Unsetenv("ENV_QPASS_REGION")
+	if err := Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	if got := os.Getenv("ENV_QPASS_REGION"); got != "override-2" {
+		t.Fatalf("expected an unset key to be supplied again, got %q", got)
+	}
+}
+
+// TestOverrideLayerRejectsUnlistedKeys ensures a key outside the allowlist fails the load without mutation.
+func TestOverrideLayerRejectsUnlistedKeys(t *testing.T) {
+	prepareLoaderTest(t, "ENV_QPASS_ALLOWED", "ENV_QPASS_DENIED")
+	t.Setenv("APP_ENV", Local)
+	_ = os.Unsetenv("ENV_QPASS_ALLOWED")
+	_ = os.Unsetenv("ENV_QPASS_DENIED")
+	directory := t.TempDir()
+	writeEnvFile(t, directory, envFileOverride, "ENV_QPASS_ALLOWED=yes\nENV_QPASS_DENIED=no\n")
+	changeWorkingDirectory(t, directory)
+	if err := SetLayerOptions(LayerOptions{OverrideKeys: []string{"ENV_QPASS_ALLOWED"}}); err != nil {
+		t.Fatalf("SetLayerOptions: %v", err)
+	}
+
+	err := Load()
+	if err == nil || !strings.Contains(err.Error(), "sets ENV_QPASS_DENIED") || !strings.Contains(err.Error(), filepath.Join(directory, envFileOverride)) {
+		t.Fatalf("expected allowlist error naming file and key, got %v", err)
+	}
+	if _, present := os.LookupEnv("ENV_QPASS_ALLOWED"); present || IsEnvLoaded() {
+		t.Fatal("expected rejected override layer to leave the environment unchanged")
+	}
+}
+
+// TestSetLayerOptionsCustomFilesAndValidation ensures custom filenames are used and invalid options are rejected.
+func TestSetLayerOptionsCustomFilesAndValidation(t *testing.T) {
+	prepareLoaderTest(t)
+	directory := t.TempDir()
+	writeEnvFile(t, directory, "defaults.env", "FROM_DEFAULTS=yes\n")
+	writeEnvFile(t, directory, "force.env", "FORCED=yes\n")
+	changeWorkingDirectory(t, directory)
+	if err := SetLayerOptions(LayerOptions{DefaultsFile: "defaults.env", OverrideFile: "force.env", OverrideKeys: []string{"FORCED"}}); err != nil {
+		t.Fatalf("SetLayerOptions: %v", err)
+	}
+
+	environment, err := LoadInto(directory, LoadIntoOptions{Environ: []string{"FORCED=ambient"}})
+	if err != nil {
+		t.Fatalf("LoadInto: %v", err)
+	}
+	if environment.Get("FROM_DEFAULTS", "") != "yes" || environment.Get("FORCED", "") != "yes" {
+		t.Fatalf("unexpected values %v", environment.Values())
+	}
+
+	for _, options := range []LayerOptions{
+		{DefaultsFile: "config/.env.defaults"},
+		{OverrideFile: fileEnv},
+		{OverrideKeys: []string{"["}},
+	} {
+		if err := SetLayerOptions(options); err == nil {
+			t.Fatalf("expected %+v to be rejected", options)
+		}
+	}
+}
+
+// TestSectionsLayerLikeTheirFiles ensures .env sections apply for APP_ENV and detected runtimes, each just below its file.
+func TestSectionsLayerLikeTheirFiles(t *testing.T) {
+	keys := []string{"ENV_QPASS_SECTION_BASE", "ENV_QPASS_SECTION_APP", "ENV_QPASS_SECTION_FILE", "ENV_QPASS_SECTION_OS", "ENV_QPASS_SECTION_TEST"}
+	prepareLoaderTest(t, append(keys, "KUBERNETES_SERVICE_HOST", "CI")...)
+	t.Cleanup(resetRuntime)
+	t.Setenv("APP_ENV", Production)
+	t.Setenv("CI", "false")
+	for _, key := range append(keys, "KUBERNETES_SERVICE_HOST") {
+		_ = os.Unsetenv(key)
+	}
+	goos = "plan9"
+	stubContainerRuntime(t, false)
+	directory := t.TempDir()
+	writeEnvFile(t, directory, fileEnv, strings.Join([]string{
+		"ENV_QPASS_SECTION_BASE=base",
+		"ENV_QPASS_SECTION_APP=base",
+		"ENV_QPASS_SECTION_FILE=base",
+		"[production]",
+		"ENV_QPASS_SECTION_APP=production",
+		"ENV_QPASS_SECTION_FILE=section",
+		"[staging]",
+		"ENV_QPASS_SECTION_APP=staging",
+		"[plan9]",
+		"ENV_QPASS_SECTION_OS=plan9",
+		"[testing]",
+		"ENV_QPASS_SECTION_TEST=testing",
+		"",
+	}, "\n"))
+	writeEnvFile(t, directory, envFileProd, "ENV_QPASS_SECTION_FILE=file\n")
+	changeWorkingDirectory(t, directory)
+	if err := SetLayerOptions(LayerOptions{Sections: true}); err != nil {
+		t.Fatalf("SetLayerOptions: %v", err)
+	}
+
+	if err := Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	want := map[string]string{
+		"ENV_QPASS_SECTION_BASE": "base",
+		"ENV_QPASS_SECTION_APP":  "production",
+		"ENV_QPASS_SECTION_FILE": "file",
+		"ENV_QPASS_SECTION_OS":   "plan9",
+		"ENV_QPASS_SECTION_TEST": "testing",
+	}
+	for key, value := range want {
+		if got := os.Getenv(key); got != value {
+			t.Fatalf("expected %s=%q, got %q", key, value, got)
+		}
+	}
+
+	if err := Unload(); err != nil {
+		t.Fatalf("Unload: %v", err)
+	}
+	if _, ok := os.LookupEnv("ENV_QPASS_SECTION_APP"); ok {
+		t.Fatal("expected Unload to remove section values")
+	}
+}
+
+// TestSectionsRequireOptionAndBaseFile ensures headers fail with a position unless enabled, and only in .env.
+func TestSectionsRequireOptionAndBaseFile(t *testing.T) {
+	prepareLoaderTest(t, "ENV_QPASS_SECTION_APP")
+	t.Setenv("APP_ENV", Production)
+	_ = os.Unsetenv("ENV_QPASS_SECTION_APP")
+	directory := t.TempDir()
+	writeEnvFile(t, directory, fileEnv, "ENV_QPASS_SECTION_APP=base\n\n[production]\nENV_QPASS_SECTION_APP=production\n")
+	changeWorkingDirectory(t, directory)
+
+	var syntaxErr *SyntaxError
+	if err := Load(); !errors.As(err, &syntaxErr) || syntaxErr.Line != 3 || syntaxErr.Column != 1 ||
+		!strings.Contains(syntaxErr.Message, "LayerOptions.Sections") {
+		t.Fatalf("expected a positioned section error without the option, got %v", err)
+	}
+	if _, ok := os.LookupEnv("ENV_QPASS_SECTION_APP"); ok {
+		t.Fatal("expected a failed load to leave the environment unchanged")
+	}
+
+	if err := SetLayerOptions(LayerOptions{Sections: true}); err != nil {
+		t.Fatalf("SetLayerOptions: %v", err)
+	}
+	writeEnvFile(t, directory, envFileProd, "[staging]\nENV_QPASS_SECTION_APP=staging\n")
+	if err := Load(); !errors.As(err, &syntaxErr) || !strings.HasSuffix(syntaxErr.File, envFileProd) || syntaxErr.Line != 1 {
+		t.Fatalf("expected a section in .env.production to fail, got %v", err)
+	}
+
+	if err := os.Remove(filepath.Join(directory, envFileProd)); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	if err := Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if got := os.Getenv("ENV_QPASS_SECTION_APP"); got != "production" {
+		t.Fatalf("expected the production section, got %q", got)
+	}
+}
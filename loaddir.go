@@ -0,0 +1,189 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DirOptions configures how LoadDir maps files to keys.
+type DirOptions struct {
+	// Prefix is prepended to every key after Transform, such as "DB_".
+	Prefix string
+	// Transform maps a filename to a key. Nil uppercases the name and replaces '-' and '.' with '_',
+	// so db-password becomes DB_PASSWORD.
+	Transform func(name string) string
+	// KeepTrailingNewline keeps a final newline instead of trimming one "\n" or "\r\n".
+	KeepTrailingNewline bool
+	// Optional lets a missing directory contribute nothing instead of failing the load.
+	Optional bool
+}
+
+// directorySource is one directory registered with LoadDir.
+type directorySource struct {
+	dir     string
+	options DirOptions
+}
+
+// LoadDir transactionally applies a directory that holds one file per key, such as /run/secrets.
+// @group Environment loading
+// @behavior mutates-process-env
+//
+// Kubernetes secret and ConfigMap volumes, Docker swarm secrets, and systemd's
+// $CREDENTIALS_DIRECTORY all use this layout. Each regular file, or symlink to one, becomes a key
+// whose value is the file content with one trailing newline trimmed. Hidden entries, including the
+// ..data and timestamped directories Kubernetes uses for atomic updates, and subdirectories are
+// ignored. A name that does not map to a valid key, or two files mapping to the same key, fail the
+// load. Values are taken literally and never interpolated.
+//
+// Directory layers rank above every env file but below the override layer, and process values
+// still beat them. Later directories win over earlier ones; calling LoadDir again for the same
+// directory replaces its options. Like LoadFiles, LoadDir applies even after an earlier load, and
+// Reload and Watch keep reading the directory until Unload.
+//
+// Example: read Docker secrets
+//
+//	secrets, _ := os.MkdirTemp("", "envdoc")
+//	defer os.RemoveAll(secrets)
+//	_ = os.WriteFile(filepath.Join(secrets, "password"), []byte("s3cr3t\n"), 0o600)
+//
+//	_ = env.LoadDir(secrets, env.DirOptions{Prefix: "DB_"})
+//	env.Dump(os.Getenv("DB_PASSWORD"))
+//	// #string "s3cr3t"
+func LoadDir(dir string, options DirOptions) error {
+	if dir == "" {
+		return errors.New("load env dir: empty directory")
+	}
+	if !filepath.IsAbs(dir) {
+		workingDirectory, err := envFileGetwd()
+		if err != nil {
+			return fmt.Errorf("get working directory for env loading: %w", err)
+		}
+		dir = filepath.Join(workingDirectory, dir)
+	}
+	source := directorySource{dir: filepath.Clean(dir), options: options}
+	_, err := load(true, func(sources *environmentSources) {
+		dirs := make([]directorySource, 0, len(sources.dirs)+1)
+		for _, existing := range sources.dirs {
+			if existing.dir != source.dir {
+				dirs = append(dirs, existing)
+			}
+		}
+		sources.dirs = append(dirs, source)
+	})
+	return err
+}
+
+// mergeDirectoryLayers merges every registered directory in order, keeping process values authoritative.
+func mergeDirectoryLayers(
+	plan *environmentLoadPlan,
+	dirs []directorySource,
+	previous map[string]loadedEnvironmentValue,
+) error {
+	for _, source := range dirs {
+		plan.watched = append(plan.watched, source.dir)
+		values, paths, found, err := readDirectoryValues(source)
+		if err != nil {
+			return err
+		}
+		if !found {
+			continue
+		}
+
+		keys := make([]string, 0, len(values))
+		for key := range values {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			plan.watched = append(plan.watched, paths[key])
+			if _, fileOwned := previous[key]; !fileOwned {
+				if _, processOwned := plan.lookup(key); processOwned {
+					continue
+				}
+			}
+			plan.fileValues[key] = values[key]
+			plan.sources[key] = paths[key]
+			delete(plan.templates, key)
+		}
+		plan.files = append(plan.files, source.dir)
+		plan.layers = append(plan.layers, layerDirectory)
+	}
+	return nil
+}
+
+// readDirectoryValues maps the visible regular files of one directory to keys and values.
+func readDirectoryValues(source directorySource) (map[string]string, map[string]string, bool, error) {
+	entries, err := os.ReadDir(source.dir)
+	switch {
+	case errors.Is(err, os.ErrNotExist) && source.options.Optional:
+		return nil, nil, false, nil
+	case err != nil:
+		return nil, nil, false, fmt.Errorf("read env dir %s: %w", source.dir, err)
+	}
+
+	transform := source.options.Transform
+	if transform == nil {
+		transform = directoryKey
+	}
+	values := make(map[string]string, len(entries))
+	paths := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, ".") {
+			continue
+		}
+		path := filepath.Join(source.dir, name)
+		info, err := envFileStat(path)
+		if err != nil {
+			return nil, nil, false, fmt.Errorf("stat env dir file %s: %w", path, err)
+		}
+		if !info.Mode().IsRegular() {
+			continue
+		}
+
+		key := source.options.Prefix + transform(name)
+		if !isValidEnvKey(key) {
+			return nil, nil, false, fmt.Errorf("env dir file %s maps to invalid key %q", path, key)
+		}
+		if other, duplicate := paths[key]; duplicate {
+			return nil, nil, false, fmt.Errorf("env dir files %s and %s both map to %s", other, path, key)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, nil, false, fmt.Errorf("read env dir file %s: %w", path, err)
+		}
+		value := string(data)
+		if !source.options.KeepTrailingNewline {
+			if trimmed, ok := strings.CutSuffix(value, "\r\n"); ok {
+				value = trimmed
+			} else {
+				value = strings.TrimSuffix(value, "\n")
+			}
+		}
+		values[key] = value
+		paths[key] = path
+	}
+	return values, paths, true, nil
+}
+
+// directoryKey uppercases a filename and replaces '-' and '.' with '_'.
+func directoryKey(name string) string {
+	return strings.NewReplacer("-", "_", ".", "_").Replace(strings.ToUpper(name))
+}
+
+// isValidEnvKey reports whether key is a name the parser would accept.
+func isValidEnvKey(key string) bool {
+	if key == "" || !isEnvKeyStart(key[0]) {
+		return false
+	}
+	for index := 1; index < len(key); index++ {
+		if !isEnvKeyPart(key[index]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,132 @@
+package env
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeKubernetesVolume lays out files the way a Kubernetes secret volume does, behind ..data.
+func writeKubernetesVolume(t *testing.T, directory string, files map[string]string) {
+	t.Helper()
+	timestamped := filepath.Join(directory, "..2026_10_19_00_00_00.000000001")
+	if err := os.Mkdir(timestamped, 0o755); err != nil {
+		t.Fatalf("make timestamped directory: %v", err)
+	}
+	for name, contents := range files {
+		writeEnvFile(t, timestamped, name, contents)
+	}
+	if err := os.Symlink(filepath.Base(timestamped), filepath.Join(directory, "..data")); err != nil {
+		t.Fatalf("link ..data: %v", err)
+	}
+	for name := range files {
+		if err := os.Symlink(filepath.Join("..data", name), filepath.Join(directory, name)); err != nil {
+			t.Fatalf("link %s: %v", name, err)
+		}
+	}
+}
+
+// TestLoadDirMapsFilesToKeys ensures directory layers follow symlinks, skip Kubernetes machinery, and rank above files.
+func TestLoadDirMapsFilesToKeys(t *testing.T) {
+	prepareLoaderTest(t, "DB_PASSWORD", "DB_TLS_CA_PEM", "DB_HOST", "DB_USER")
+	t.Setenv("APP_ENV", Local)
+	t.Setenv("DB_USER", "process")
+	_ = os.Unsetenv("DB_PASSWORD")
+	_ = os.Unsetenv("DB_TLS_CA_PEM")
+	_ = os.Unsetenv("DB_HOST")
+	directory := t.TempDir()
+	secrets := filepath.Join(directory, "secrets")
+	if err := os.Mkdir(secrets, 0o755); err != nil {
+		t.Fatalf("make secrets directory: %v", err)
+	}
+	writeKubernetesVolume(t, secrets, map[string]string{
+		"password":   "s3cr3t\r\n",
+		"tls-ca.pem": "line1\nline2\n\n",
+		"host":       "db.internal",
+		"user":       "volume",
+	})
+	if err := os.Mkdir(filepath.Join(secrets, "nested"), 0o755); err != nil {
+		t.Fatalf("make nested directory: %v", err)
+	}
+	writeEnvFile(t, directory, fileEnv, "DB_HOST=localhost\n")
+	changeWorkingDirectory(t, directory)
+
+	if err := LoadDir("secrets", DirOptions{Prefix: "DB_"}); err != nil {
+		t.Fatalf("LoadDir: %v", err)
+	}
+	want := map[string]string{
+		"DB_PASSWORD":   "s3cr3t",
+		"DB_TLS_CA_PEM": "line1\nline2\n",
+		"DB_HOST":       "db.internal",
+		"DB_USER":       "process",
+	}
+	for key, value := range want {
+		if got := os.Getenv(key); got != value {
+			t.Fatalf("%s = %q, want %q", key, got, value)
+		}
+	}
+
+	writeEnvFile(t, filepath.Join(secrets, "..2026_10_19_00_00_00.000000001"), "password", "rotated\n")
+	if err := Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	if got := os.Getenv("DB_PASSWORD"); got != "rotated" {
+		t.Fatalf("expected Reload to re-read the directory, got %q", got)
+	}
+	processEnvironmentLoader.mu.Lock()
+	watched := append([]string(nil), processEnvironmentLoader.watched...)
+	processEnvironmentLoader.mu.Unlock()
+	if !strings.Contains(strings.Join(watched, "\n"), filepath.Join(secrets, "password")) {
+		t.Fatalf("expected directory files to be watched, got %v", watched)
+	}
+
+	if err := Unload(); err != nil {
+		t.Fatalf("Unload: %v", err)
+	}
+	if _, present := os.LookupEnv("DB_PASSWORD"); present {
+		t.Fatal("expected Unload to restore directory keys")
+	}
+}
+
+// TestLoadDirOptions ensures transforms, newline handling, optional directories, and key errors behave as documented.
+func TestLoadDirOptions(t *testing.T) {
+	prepareLoaderTest(t, "api_token", "API_TOKEN")
+	t.Setenv("APP_ENV", Local)
+	_ = os.Unsetenv("api_token")
+	_ = os.Unsetenv("API_TOKEN")
+	directory := t.TempDir()
+	changeWorkingDirectory(t, directory)
+	credentials := filepath.Join(directory, "credentials")
+	if err := os.Mkdir(credentials, 0o755); err != nil {
+		t.Fatalf("make credentials directory: %v", err)
+	}
+	writeEnvFile(t, credentials, "api-token", "token\n")
+
+	if err := LoadDir(filepath.Join(directory, "missing"), DirOptions{Optional: true}); err != nil {
+		t.Fatalf("expected optional missing directory to load, got %v", err)
+	}
+	if err := LoadDir(filepath.Join(directory, "missing-required"), DirOptions{}); err == nil {
+		t.Fatal("expected a missing required directory to fail")
+	}
+	if err := LoadDir(credentials, DirOptions{Transform: func(name string) string { return name }}); err == nil ||
+		!strings.Contains(err.Error(), `invalid key "api-token"`) {
+		t.Fatalf("expected invalid key error, got %v", err)
+	}
+
+	err := LoadDir(credentials, DirOptions{
+		Transform:           func(name string) string { return strings.ReplaceAll(name, "-", "_") },
+		KeepTrailingNewline: true,
+	})
+	if err != nil {
+		t.Fatalf("LoadDir: %v", err)
+	}
+	if got := os.Getenv("api_token"); got != "token\n" {
+		t.Fatalf("expected transformed key with newline kept, got %q", got)
+	}
+
+	writeEnvFile(t, credentials, "API_TOKEN", "duplicate\n")
+	if err := LoadDir(credentials, DirOptions{}); err == nil || !strings.Contains(err.Error(), "both map to API_TOKEN") {
+		t.Fatalf("expected duplicate key error, got %v", err)
+	}
+}
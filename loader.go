@@ -3,14 +3,13 @@ package env
 import (
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
 	"sync"
-
-	"github.com/joho/godotenv"
 )
 
 // MaxDirectorySeekLevels bounds env-file discovery to the working directory and nine ancestors.
@@ -19,19 +18,45 @@ const MaxDirectorySeekLevels int = 10
 const (
 	fileEnv        = ".env"
 	fileEnvHost    = ".env.host"
+	envFileDocker  = ".env.docker"
+	envFileKube    = ".env.kubernetes"
+	envFileCI      = ".env.ci"
 	envFileTesting = ".env.testing"
 	envFileLocal   = ".env.local"
 	envFileStaging = ".env.staging"
 	envFileProd    = ".env.production"
+
+	// envFilePersonalSuffix marks the uncommitted, machine-specific layer for an APP_ENV.
+	envFilePersonalSuffix = ".local"
+)
+
+// Layer labels identify why a file was applied in ENV_DEBUG output.
+const (
+	layerDefaults   = "defaults"
+	layerBase       = "base"
+	layerAppEnv     = "app"
+	layerHost       = "host"
+	layerDocker     = "docker"
+	layerKubernetes = "kubernetes"
+	layerCI         = "ci"
+	layerTesting    = "testing"
+	layerPersonal   = "personal"
+	layerOverride   = "override"
+	layerExplicit   = "explicit"
+	layerDirectory  = "dir"
+
+	// layerSectionPrefix labels a [name] section of .env, such as section:production.
+	layerSectionPrefix = "section:"
 )
 
 var (
-	envFileGetwd = os.Getwd
-	envFileStat  = os.Stat
-	envFileRead  = godotenv.Read
-	envLookup    = os.LookupEnv
-	envSet       = os.Setenv
-	envUnset     = os.Unsetenv
+	envFileGetwd                   = os.Getwd
+	envFileStat                    = os.Stat
+	envFileRead                    = readEnvFile
+	envFileWarningWriter io.Writer = os.Stderr
+	envLookup                      = os.LookupEnv
+	envSet                         = os.Setenv
+	envUnset                       = os.Unsetenv
 )
 
 // environmentSnapshot retains both presence and value because an empty variable differs from an unset one.
@@ -47,30 +72,70 @@ type loadedEnvironmentValue struct {
 }
 
 // environmentLoaderState serializes loading and protects ownership metadata and the ambient baseline.
+//
+// defaults tracks values the loader synthesized, such as APP_ENV=local, so Unload can remove them;
+// they are never file-owned and do not affect Reload precedence. sources remembers what LoadFiles,
+// LoadFS, or LoadReader selected so Reload reads the same sources.
 type environmentLoaderState struct {
 	mu       sync.Mutex
 	loaded   bool
 	values   map[string]loadedEnvironmentValue
+	defaults map[string]loadedEnvironmentValue
 	baseline map[string]environmentSnapshot
+	watched  []string
+	sources  environmentSources
 }
 
 var processEnvironmentLoader = environmentLoaderState{
 	values:   make(map[string]loadedEnvironmentValue),
+	defaults: make(map[string]loadedEnvironmentValue),
 	baseline: make(map[string]environmentSnapshot),
 }
 
+// environmentSources selects what a load reads besides the discovered on-disk layers.
+type environmentSources struct {
+	// files replaces discovery with explicit paths, as LoadFiles does.
+	files []string
+	// embedded supplies layers below every on-disk file, as LoadFS and LoadReader do.
+	embedded *embeddedEnvSource
+	// compose reads docker-compose service environments above the file layers, as LoadCompose does.
+	compose []ComposeService
+	// dirs maps one file per key above the compose layers, as LoadDir does.
+	dirs []directorySource
+}
+
 // environmentFile contains one parsed file before any process environment mutation occurs.
+//
+// templates holds the interpolation template of every value that may reference other variables,
+// and sections holds the keys under each [name] header of an .env file read with sections.
 type environmentFile struct {
-	path   string
-	values map[string]string
+	path      string
+	values    map[string]string
+	templates map[string]string
+	sections  map[string]environmentFile
+	includes  []envInclude
 }
 
 // environmentLoadPlan is the complete, deterministic result of discovery and layering.
 type environmentLoadPlan struct {
 	fileValues map[string]string
+	templates  map[string]string
 	defaults   map[string]string
+	sources    map[string]string
 	files      []string
+	layers     []string
+	watched    []string
 	appEnv     string
+	lookup     func(string) (string, bool)
+	// cascadeRoot is the repository root a cascading search starts from, or empty for nearest-file search.
+	cascadeRoot string
+	// sections reports whether .env files may hold [name] sections; sectioned lists those that do.
+	sections  bool
+	sectioned []environmentFile
+	// formats lists the structured formats discovered alongside each dotenv file.
+	formats []string
+	// includes lists the files read through #include directives, in read order.
+	includes []envInclude
 }
 
 // Load loads the nearest env files with deterministic layering.
@@ -84,13 +149,34 @@ type environmentLoadPlan struct {
 // @behavior mutates-process-env
 //
 // Layer order:
+//   - .env.defaults, which only fills keys nothing else sets
 //   - .env
-//   - .env.local, .env.staging, or .env.production selected after parsing .env
-//   - .env.host on hosts and Docker-in-Docker
+//   - .env.local, .env.staging, .env.production, or a RegisterAppEnv file selected after parsing .env
+//   - runtime layers: .env.<goos>, .env.host on hosts and Docker-in-Docker, .env.docker,
+//     .env.kubernetes, .env.ci, then layers added with RegisterRuntimeLayer
 //   - .env.testing when APP_ENV or the process identifies a test
+//   - .env.<appenv>.local, a personal override layer for the selected APP_ENV
+//   - .env.override, which also beats process values for keys allowed by SetLayerOptions
+//
+// With LayerOptions.Sections, a matching [name] section of .env is merged just before each
+// .env.<name> file above. With LayerOptions.Formats, structured siblings such as .env.yaml and
+// .env.production.json are read too, each just before the dotenv file of its layer.
+//
+// A file on disk may pull in another with an #include path line, or #include? when the file is
+// optional. The path is relative to the including file, the included keys apply where the line
+// stands, and includes nest up to eight files deep; cycles and non-regular files fail the load.
+// ENV_DEBUG=3 lists every included file.
+//
+// Values written as encrypted:<base64> by EncryptFile are decrypted once layering is planned, with
+// the key from ENV_KEY, ENV_KEY_FILE, or the nearest .env.key; a missing or wrong key fails the load.
+//
+// When the process sets ENV_FILE to a comma-separated list of paths, those files replace every
+// discovered layer, as they do for LoadFiles.
 //
 // Each filename is searched independently from the working directory through at most nine
 // ancestors. APP_ENV defaults to local when neither the ambient environment nor a file sets it.
+// After layering, unquoted and double-quoted values expand $VAR, ${VAR}, ${VAR:-default}, and
+// ${VAR:?message} against the final file values and the process environment; $$ is a literal $.
 //
 // Example: test-specific env file
 //
@@ -106,7 +192,8 @@ type environmentLoadPlan struct {
 //	env.Dump(os.Getenv("PORT"))
 //	// #string "9090"
 func Load() error {
-	return load(false)
+	_, err := load(false, nil)
+	return err
 }
 
 // Reload re-discovers and transactionally reapplies env files even after Load has run.
@@ -134,116 +221,353 @@ func Load() error {
 //	env.Dump(os.Getenv("SERVICE"))
 //	// #string "worker"
 func Reload() error {
-	return load(true)
+	_, err := load(true, nil)
+	return err
 }
 
-// load serializes discovery, application, and state publication as one loader operation.
-func load(force bool) error {
+// load applies the environment and then notifies subscribers outside the loader lock.
+//
+// update, when non-nil, changes the remembered sources for this and later loads.
+func load(force bool, update func(*environmentSources)) (ChangeSet, error) {
+	changes, err := loadLocked(force, update)
+	if err != nil {
+		return nil, err
+	}
+	environmentChanges.deliver()
+	return changes, nil
+}
+
+// loadLocked serializes discovery, application, and state publication as one loader operation.
+func loadLocked(force bool, update func(*environmentSources)) (ChangeSet, error) {
 	processEnvironmentLoader.mu.Lock()
 	defer processEnvironmentLoader.mu.Unlock()
 
 	if processEnvironmentLoader.loaded && !force {
-		return nil
+		return ChangeSet{}, nil
 	}
 
 	workingDirectory, err := envFileGetwd()
 	if err != nil {
-		return fmt.Errorf("get working directory for env loading: %w", err)
+		return nil, fmt.Errorf("get working directory for env loading: %w", err)
 	}
 
 	previous := unchangedLoadedEnvironmentValues(processEnvironmentLoader.values)
+	released := make(map[string]struct{})
+	for key := range processEnvironmentLoader.values {
+		if _, unchanged := previous[key]; !unchanged {
+			released[key] = struct{}{}
+		}
+	}
 	baseline := cloneEnvironmentSnapshots(processEnvironmentLoader.baseline)
 	if !processEnvironmentLoader.loaded {
 		baseline = snapshotProcessEnvironment()
 	}
-	plan, err := buildEnvironmentLoadPlan(filepath.Clean(workingDirectory), previous)
+	workingDirectory = filepath.Clean(workingDirectory)
+	sources := processEnvironmentLoader.sources
+	if update != nil {
+		update(&sources)
+	}
+	sources.files = resolveEnvFilePaths(workingDirectory, sources.files)
+	plan, err := buildEnvironmentLoadPlan(workingDirectory, sources, previous, released, envLookup)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	next, err := applyEnvironmentLoadPlan(previous, baseline, plan)
+	next, changes, err := applyEnvironmentLoadPlan(previous, baseline, plan)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	processEnvironmentLoader.values = next
+	processEnvironmentLoader.defaults = synthesizedEnvironmentValues(processEnvironmentLoader.defaults, baseline, plan)
 	processEnvironmentLoader.baseline = baseline
+	processEnvironmentLoader.watched = plan.watched
+	processEnvironmentLoader.sources = sources
 	processEnvironmentLoader.loaded = true
+	environmentChanges.enqueue(changes)
+
+	if debugLevel := environmentPlanInt(plan, previous, "ENV_DEBUG"); debugLevel >= 3 {
+		printLoadedEnvFiles(plan)
+		if debugLevel >= 4 {
+			printEnvKeySources(plan)
+		}
+	}
+	return changes, nil
+}
 
-	if environmentPlanInt(plan, previous, "ENV_DEBUG") >= 3 {
-		printLoadedEnvFiles(plan.files, plan.appEnv)
+// Unload transactionally restores the process environment that existed before the first successful Load.
+// @group Environment loading
+// @behavior mutates-process-env
+//
+// Every unchanged file-owned key, and every value the loader synthesized such as the default
+// APP_ENV, returns to its baseline state, including the difference between unset and explicitly
+// empty. Keys the application has changed since loading are left untouched. On success IsEnvLoaded
+// reports false and the next Load takes a fresh baseline. If restoring any variable fails, every
+// earlier restoration is rolled back and the loaded state is preserved. Unload is a no-op when
+// nothing is loaded.
+//
+// Example: return to the pre-load environment
+//
+//	tmp, _ := os.MkdirTemp("", "envdoc")
+//	defer os.RemoveAll(tmp)
+//	originalDirectory, _ := os.Getwd()
+//	defer os.Chdir(originalDirectory)
+//	_ = os.Chdir(tmp)
+//	_ = os.WriteFile(filepath.Join(tmp, ".env"), []byte("SERVICE=api"), 0o644)
+//	_ = env.Load()
+//	_ = env.Unload()
+//	_, present := os.LookupEnv("SERVICE")
+//	env.Dump(present, env.IsEnvLoaded())
+//	// #bool false
+//	// #bool false
+func Unload() error {
+	if err := unloadLocked(); err != nil {
+		return err
 	}
+	environmentChanges.deliver()
 	return nil
 }
 
-// buildEnvironmentLoadPlan parses every selected layer before process-wide mutation begins.
-func buildEnvironmentLoadPlan(startDirectory string, previous map[string]loadedEnvironmentValue) (environmentLoadPlan, error) {
-	plan := environmentLoadPlan{
+// unloadLocked restores owned keys through the plan application path so failures roll back.
+func unloadLocked() error {
+	processEnvironmentLoader.mu.Lock()
+	defer processEnvironmentLoader.mu.Unlock()
+
+	if !processEnvironmentLoader.loaded {
+		return nil
+	}
+
+	previous := unchangedLoadedEnvironmentValues(processEnvironmentLoader.defaults)
+	for key, loaded := range unchangedLoadedEnvironmentValues(processEnvironmentLoader.values) {
+		previous[key] = loaded
+	}
+	empty := environmentLoadPlan{
 		fileValues: make(map[string]string),
+		templates:  make(map[string]string),
 		defaults:   make(map[string]string),
 	}
+	_, changes, err := applyEnvironmentLoadPlan(previous, processEnvironmentLoader.baseline, empty)
+	if err != nil {
+		return fmt.Errorf("unload env files: %w", err)
+	}
 
-	appEnv := effectiveEnvironmentValue("APP_ENV", plan.fileValues, previous)
-	if appEnv == "" {
-		appEnv = Local
+	processEnvironmentLoader.values = make(map[string]loadedEnvironmentValue)
+	processEnvironmentLoader.defaults = make(map[string]loadedEnvironmentValue)
+	processEnvironmentLoader.baseline = make(map[string]environmentSnapshot)
+	processEnvironmentLoader.watched = nil
+	processEnvironmentLoader.sources = environmentSources{}
+	processEnvironmentLoader.loaded = false
+	environmentChanges.enqueue(changes)
+	return nil
+}
+
+// synthesizedEnvironmentValues keeps still-visible earlier defaults and records the plan's new ones.
+func synthesizedEnvironmentValues(
+	current map[string]loadedEnvironmentValue,
+	baseline map[string]environmentSnapshot,
+	plan environmentLoadPlan,
+) map[string]loadedEnvironmentValue {
+	next := unchangedLoadedEnvironmentValues(current)
+	for key := range plan.fileValues {
+		delete(next, key)
+	}
+	for key, value := range plan.defaults {
+		if _, ok := next[key]; ok {
+			continue
+		}
+		next[key] = loadedEnvironmentValue{
+			original: baseline[key],
+			applied:  environmentSnapshot{value: value, present: true},
+		}
+	}
+	return next
+}
+
+// buildEnvironmentLoadPlan parses every selected layer before process-wide mutation begins.
+//
+// lookup supplies the ambient environment whose values take precedence over files; Load passes the
+// process environment and LoadInto passes its isolated view. released lists keys the application
+// took over from the loader, which even the override layer leaves alone. sources.files replaces
+// discovery with an explicit list; when it is empty, an ambient ENV_FILE may supply one.
+// sources.embedded layers sit below every on-disk file.
+func buildEnvironmentLoadPlan(
+	startDirectory string,
+	sources environmentSources,
+	previous map[string]loadedEnvironmentValue,
+	released map[string]struct{},
+	lookup func(string) (string, bool),
+) (environmentLoadPlan, error) {
+	plan := environmentLoadPlan{
+		fileValues: make(map[string]string),
+		templates:  make(map[string]string),
+		defaults:   make(map[string]string),
+		sources:    make(map[string]string),
+		lookup:     lookup,
 	}
 
-	base, found, err := loadEnvFile(startDirectory, fileEnv)
+	embedded, err := mergeEmbeddedBase(&plan, sources.embedded, previous)
 	if err != nil {
 		return environmentLoadPlan{}, err
 	}
-	if found {
-		mergeEnvironmentFile(&plan, base, previous)
-		if value, ok := plan.fileValues["APP_ENV"]; ok {
-			appEnv = value
-		}
+	files := sources.files
+	if len(files) == 0 {
+		files = envFileListValue(originalEnvironmentSnapshot(envFileVariable, previous, lookup))
 	}
-
-	if appEnvFile, ok := envFileForAppEnv(appEnv); ok {
-		layer, found, err := loadEnvFile(startDirectory, appEnvFile)
-		if err != nil {
+	layerOptions := currentLayerOptions()
+	if len(files) > 0 {
+		if err := mergeExplicitEnvFiles(&plan, startDirectory, files, previous); err != nil {
 			return environmentLoadPlan{}, err
 		}
-		if found {
-			mergeEnvironmentFile(&plan, layer, previous)
+	} else if err := mergeDiscoveredLayers(&plan, startDirectory, layerOptions, previous); err != nil {
+		return environmentLoadPlan{}, err
+	}
+	if err := mergeComposeLayers(&plan, sources.compose, previous); err != nil {
+		return environmentLoadPlan{}, err
+	}
+	if err := mergeDirectoryLayers(&plan, sources.dirs, previous); err != nil {
+		return environmentLoadPlan{}, err
+	}
+	if len(files) == 0 {
+		if err := mergeOverrideLayer(&plan, startDirectory, layerOptions, released); err != nil {
+			return environmentLoadPlan{}, err
 		}
 	}
+	if err := mergeEmbeddedLayers(&plan, sources.embedded, embedded, previous); err != nil {
+		return environmentLoadPlan{}, err
+	}
+
+	if err := decryptEnvironmentPlan(&plan, startDirectory, previous); err != nil {
+		return environmentLoadPlan{}, err
+	}
 
-	lookup := func(key string) string {
-		return effectiveEnvironmentValue(key, plan.fileValues, previous)
+	if _, fileOwnsAppEnv := plan.fileValues["APP_ENV"]; !fileOwnsAppEnv {
+		ambient := originalEnvironmentSnapshot("APP_ENV", previous, plan.lookup)
+		if !ambient.present {
+			plan.defaults["APP_ENV"] = Local
+		}
+	}
+	if err := interpolateEnvironmentPlan(&plan, previous); err != nil {
+		return environmentLoadPlan{}, err
 	}
-	if isHostEnvironmentWithEnv(lookup) || IsDockerInDocker() {
-		host, found, err := loadEnvFile(startDirectory, fileEnvHost)
+	plan.appEnv = environmentPlanValue(plan, previous, "APP_ENV")
+	return plan, nil
+}
+
+// mergeDiscoveredLayers searches for and merges every file layer below the override layer.
+func mergeDiscoveredLayers(
+	plan *environmentLoadPlan,
+	startDirectory string,
+	layerOptions LayerOptions,
+	previous map[string]loadedEnvironmentValue,
+) error {
+	if layerOptions.Cascade {
+		root, err := findCascadeRoot(startDirectory, layerOptions.RootMarkers)
 		if err != nil {
-			return environmentLoadPlan{}, err
+			return err
 		}
-		if found {
-			mergeEnvironmentFile(&plan, host, previous)
+		plan.cascadeRoot = root
+	}
+	plan.sections = layerOptions.Sections
+	plan.formats = layerOptions.Formats
+	appEnv := effectiveEnvironmentValue("APP_ENV", *plan, previous)
+	if appEnv == "" {
+		appEnv = Local
+	}
+
+	if err := mergeEnvironmentLayer(plan, startDirectory, layerOptions.DefaultsFile, layerDefaults, previous); err != nil {
+		return err
+	}
+	if err := mergeEnvironmentLayer(plan, startDirectory, fileEnv, layerBase, previous); err != nil {
+		return err
+	}
+	if value, ok := plan.fileValues["APP_ENV"]; ok {
+		appEnv = value
+	}
+
+	if definition, ok := appEnvironments.lookup(appEnv); ok && definition.name != Testing {
+		mergeEnvironmentSection(plan, definition.name, previous)
+	}
+	if appEnvFile, ok := envFileForAppEnv(appEnv); ok {
+		if err := mergeEnvironmentLayer(plan, startDirectory, appEnvFile, layerAppEnv, previous); err != nil {
+			return err
 		}
 	}
 
-	appEnv = effectiveEnvironmentValue("APP_ENV", plan.fileValues, previous)
+	if err := mergeRuntimeLayers(plan, startDirectory, previous); err != nil {
+		return err
+	}
+
+	appEnv = effectiveEnvironmentValue("APP_ENV", *plan, previous)
 	if appEnv == "" {
 		appEnv = Local
 	}
 	if isAppEnvTestingValue(appEnv) {
-		testing, found, err := loadEnvFile(startDirectory, envFileTesting)
-		if err != nil {
-			return environmentLoadPlan{}, err
+		mergeEnvironmentSection(plan, Testing, previous)
+		if err := mergeEnvironmentLayer(plan, startDirectory, envFileTesting, layerTesting, previous); err != nil {
+			return err
 		}
-		if found {
-			mergeEnvironmentFile(&plan, testing, previous)
+	}
+	if personalFile, ok := personalEnvFileForAppEnv(appEnv); ok {
+		return mergeEnvironmentLayer(plan, startDirectory, personalFile, layerPersonal, previous)
+	}
+	return nil
+}
+
+// mergeEnvironmentLayer discovers and merges one named layer and records every path its search inspects.
+func mergeEnvironmentLayer(
+	plan *environmentLoadPlan,
+	startDirectory, name, layer string,
+	previous map[string]loadedEnvironmentValue,
+) error {
+	files, err := loadEnvLayer(plan, startDirectory, name)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		mergeEnvironmentFile(plan, file, previous)
+		plan.layers = append(plan.layers, layer)
+		if len(file.sections) > 0 {
+			plan.sectioned = append(plan.sectioned, file)
 		}
 	}
+	return nil
+}
 
-	if _, fileOwnsAppEnv := plan.fileValues["APP_ENV"]; !fileOwnsAppEnv {
-		ambient := originalEnvironmentSnapshot("APP_ENV", previous)
-		if !ambient.present {
-			plan.defaults["APP_ENV"] = Local
+// mergeEnvironmentSection merges the [name] section of every sectioned .env file, outermost first.
+func mergeEnvironmentSection(plan *environmentLoadPlan, name string, previous map[string]loadedEnvironmentValue) {
+	for _, file := range plan.sectioned {
+		if section, ok := file.sections[name]; ok {
+			mergeEnvironmentFile(plan, section, previous)
+			plan.layers = append(plan.layers, layerSectionPrefix+name)
 		}
 	}
-	plan.appEnv = environmentPlanValue(plan, previous, "APP_ENV")
-	return plan, nil
+}
+
+// loadEnvLayer parses every file selected for one layer, outermost first, and records every path
+// its search inspects. Without a cascade root only the nearest file is selected. Structured
+// siblings for LayerOptions.Formats, such as .env.yaml, are searched independently and come first.
+func loadEnvLayer(plan *environmentLoadPlan, startDirectory, name string) ([]environmentFile, error) {
+	var files []environmentFile
+	for _, candidate := range structuredEnvNames(name, plan.formats) {
+		sections := plan.sections && candidate == fileEnv
+		if plan.cascadeRoot != "" {
+			cascaded, err := loadCascadeEnvFiles(plan, startDirectory, candidate, sections)
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, cascaded...)
+			continue
+		}
+		file, found, err := loadEnvFile(startDirectory, candidate, sections)
+		plan.watched = append(plan.watched, envFileCandidates(startDirectory, candidate, file.path)...)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			files = append(files, file)
+		}
+	}
+	return files, nil
 }
 
 // mergeEnvironmentFile keeps existing process values authoritative while preserving file layering.
@@ -253,22 +577,34 @@ func mergeEnvironmentFile(
 	previous map[string]loadedEnvironmentValue,
 ) {
 	plan.files = append(plan.files, file.path)
-	for key, value := range file.values {
+	mergeEnvIncludes(plan, file)
+	for key := range file.values {
 		if _, fileOwned := previous[key]; !fileOwned {
-			if _, processOwned := envLookup(key); processOwned {
+			if _, processOwned := plan.lookup(key); processOwned {
 				continue
 			}
 		}
-		plan.fileValues[key] = value
+		setPlanFileValue(plan, file, key)
+	}
+}
+
+// setPlanFileValue records key from file as the current winner, including its provenance and template.
+func setPlanFileValue(plan *environmentLoadPlan, file environmentFile, key string) {
+	plan.fileValues[key] = file.values[key]
+	plan.sources[key] = file.path
+	if template, ok := file.templates[key]; ok {
+		plan.templates[key] = template
+	} else {
+		delete(plan.templates, key)
 	}
 }
 
 // effectiveEnvironmentValue uses stable originals for owned keys and live ambient values otherwise.
-func effectiveEnvironmentValue(key string, fileValues map[string]string, previous map[string]loadedEnvironmentValue) string {
-	if value, ok := fileValues[key]; ok {
+func effectiveEnvironmentValue(key string, plan environmentLoadPlan, previous map[string]loadedEnvironmentValue) string {
+	if value, ok := plan.fileValues[key]; ok {
 		return value
 	}
-	snapshot := originalEnvironmentSnapshot(key, previous)
+	snapshot := originalEnvironmentSnapshot(key, previous, plan.lookup)
 	if !snapshot.present {
 		return ""
 	}
@@ -276,11 +612,15 @@ func effectiveEnvironmentValue(key string, fileValues map[string]string, previou
 }
 
 // originalEnvironmentSnapshot returns the stable ambient value for file-owned keys and the live value otherwise.
-func originalEnvironmentSnapshot(key string, previous map[string]loadedEnvironmentValue) environmentSnapshot {
+func originalEnvironmentSnapshot(
+	key string,
+	previous map[string]loadedEnvironmentValue,
+	lookup func(string) (string, bool),
+) environmentSnapshot {
 	if loaded, ok := previous[key]; ok {
 		return loaded.original
 	}
-	value, present := envLookup(key)
+	value, present := lookup(key)
 	return environmentSnapshot{value: value, present: present}
 }
 
@@ -292,7 +632,7 @@ func environmentPlanValue(plan environmentLoadPlan, previous map[string]loadedEn
 	if value, ok := plan.defaults[key]; ok {
 		return value
 	}
-	snapshot := originalEnvironmentSnapshot(key, previous)
+	snapshot := originalEnvironmentSnapshot(key, previous, plan.lookup)
 	if !snapshot.present {
 		return ""
 	}
@@ -307,7 +647,11 @@ func environmentPlanInt(plan environmentLoadPlan, previous map[string]loadedEnvi
 }
 
 // applyEnvironmentLoadPlan rolls back every affected variable when any application step fails.
-func applyEnvironmentLoadPlan(previous map[string]loadedEnvironmentValue, baseline map[string]environmentSnapshot, plan environmentLoadPlan) (map[string]loadedEnvironmentValue, error) {
+func applyEnvironmentLoadPlan(
+	previous map[string]loadedEnvironmentValue,
+	baseline map[string]environmentSnapshot,
+	plan environmentLoadPlan,
+) (map[string]loadedEnvironmentValue, ChangeSet, error) {
 	keys := environmentPlanKeys(previous, plan)
 	before := make(map[string]environmentSnapshot, len(keys))
 	for _, key := range keys {
@@ -315,6 +659,7 @@ func applyEnvironmentLoadPlan(previous map[string]loadedEnvironmentValue, baseli
 		before[key] = environmentSnapshot{value: value, present: present}
 	}
 
+	changes := ChangeSet{}
 	for _, key := range keys {
 		target := environmentPlanTarget(key, previous, plan)
 		if snapshotsEqual(before[key], target) {
@@ -324,10 +669,11 @@ func applyEnvironmentLoadPlan(previous map[string]loadedEnvironmentValue, baseli
 			rollbackErr := restoreEnvironmentSnapshots(keys, before)
 			applyErr := fmt.Errorf("apply env value %s: %w", key, err)
 			if rollbackErr != nil {
-				return nil, errors.Join(applyErr, rollbackErr)
+				return nil, nil, errors.Join(applyErr, rollbackErr)
 			}
-			return nil, applyErr
+			return nil, nil, applyErr
 		}
+		changes = append(changes, newChange(key, environmentChangeKind(key, before[key], target, previous, plan), before[key], target))
 	}
 
 	next := make(map[string]loadedEnvironmentValue, len(plan.fileValues))
@@ -343,7 +689,7 @@ func applyEnvironmentLoadPlan(previous map[string]loadedEnvironmentValue, baseli
 			applied:  applied,
 		}
 	}
-	return next, nil
+	return next, changes, nil
 }
 
 // environmentPlanKeys returns affected keys in stable order for deterministic application and rollback.
@@ -461,18 +807,30 @@ func LoadEnvFileIfExists() error {
 	return Load()
 }
 
-// envFileForAppEnv returns the layered env filename for the given APP_ENV.
+// envFileForAppEnv returns the layered env filename for a built-in or registered APP_ENV.
 func envFileForAppEnv(appEnv string) (string, bool) {
-	switch appEnv {
-	case Local:
-		return envFileLocal, true
-	case Staging:
-		return envFileStaging, true
-	case Production:
-		return envFileProd, true
-	default:
+	definition, ok := appEnvironments.lookup(appEnv)
+	if !ok || definition.file == "" {
+		return "", false
+	}
+	return definition.file, true
+}
+
+// personalEnvFileForAppEnv returns the machine-specific layer for APP_ENV, such as .env.staging.local.
+//
+// Registered environments use their layer file plus .local, so aliases share one personal file.
+// Other APP_ENV values qualify only when they are safe to embed in a filename.
+func personalEnvFileForAppEnv(appEnv string) (string, bool) {
+	if definition, ok := appEnvironments.lookup(appEnv); ok {
+		if definition.file != "" {
+			return definition.file + envFilePersonalSuffix, true
+		}
+		return fileEnv + "." + definition.name + envFilePersonalSuffix, true
+	}
+	if !isValidAppEnvName(appEnv) {
 		return "", false
 	}
+	return fileEnv + "." + appEnv + envFilePersonalSuffix, true
 }
 
 // IsEnvLoaded reports whether a Load or Reload completed successfully in this process.
@@ -491,16 +849,109 @@ func IsEnvLoaded() bool {
 }
 
 // loadEnvFile returns the nearest parsed regular file without changing the process environment.
-func loadEnvFile(startDirectory, name string) (environmentFile, bool, error) {
+func loadEnvFile(startDirectory, name string, sections bool) (environmentFile, bool, error) {
 	path, found, err := findEnvFile(startDirectory, name)
 	if err != nil || !found {
 		return environmentFile{}, found, err
 	}
-	values, err := envFileRead(path)
+	file, err := parseEnvFile(path, sections)
+	if err != nil {
+		return environmentFile{}, false, err
+	}
+	return file, true, nil
+}
+
+// parseEnvFile reads one discovered file into its values and interpolation templates.
+func parseEnvFile(path string, sections bool) (environmentFile, error) {
+	_, file, err := readEnvFileEntries(path, sections)
+	return file, err
+}
+
+// readEnvFileEntries reads path with its #include files expanded, returning the entries in file
+// order along with the environmentFile they form.
+func readEnvFileEntries(path string, sections bool) ([]envEntry, environmentFile, error) {
+	entries, err := envFileRead(path)
+	if err != nil {
+		return nil, environmentFile{}, fmt.Errorf("read env file %s: %w", path, err)
+	}
+	var includes []envInclude
+	entries, err = expandEnvIncludes(path, entries, []string{filepath.Clean(path)}, &includes)
+	if err != nil {
+		return nil, environmentFile{}, err
+	}
+	file, err := newEnvironmentFile(path, entries, sections)
+	file.includes = includes
+	return entries, file, err
+}
+
+// newEnvironmentFile collects parsed entries; a later duplicate replaces the value and template.
+//
+// Entries under a [name] header go to sections, which only an .env file read with
+// LayerOptions.Sections may use; anywhere else a header is a syntax error. #include entries must
+// already be expanded, so one that remains comes from a source that is not on disk.
+func newEnvironmentFile(path string, entries []envEntry, sections bool) (environmentFile, error) {
+	file := environmentFile{path: path, values: map[string]string{}, templates: map[string]string{}}
+	for _, entry := range entries {
+		if entry.include != "" {
+			return environmentFile{}, &SyntaxError{
+				File:    path,
+				Line:    entry.line,
+				Column:  entry.column,
+				Message: "#include is only supported in env files on disk",
+			}
+		}
+		target := file
+		if entry.section != "" {
+			if !sections {
+				return environmentFile{}, &SyntaxError{
+					File:    path,
+					Line:    entry.sectionLine,
+					Column:  entry.sectionColumn,
+					Message: fmt.Sprintf("section [%s] is only allowed in %s with LayerOptions.Sections", entry.section, fileEnv),
+				}
+			}
+			if file.sections == nil {
+				file.sections = make(map[string]environmentFile)
+			}
+			section, ok := file.sections[entry.section]
+			if !ok {
+				section = environmentFile{path: path, values: map[string]string{}, templates: map[string]string{}}
+				file.sections[entry.section] = section
+			}
+			target = section
+		}
+		target.values[entry.key] = entry.value
+		if entry.expand {
+			target.templates[entry.key] = entry.template
+		} else {
+			delete(target.templates, entry.key)
+		}
+	}
+	return file, nil
+}
+
+// readEnvFile parses one env file and reports duplicate keys on standard error without their values.
+func readEnvFile(path string) ([]envEntry, error) {
+	data, err := os.ReadFile(path)
 	if err != nil {
-		return environmentFile{}, false, fmt.Errorf("read env file %s: %w", path, err)
+		return nil, err
+	}
+	return parseEnvFileData(path, data)
+}
+
+// parseEnvFileData parses env file content named path, warning about duplicate keys. A .yaml,
+// .yml, .json, or .toml path is decoded as a structured file instead.
+func parseEnvFileData(path string, data []byte) ([]envEntry, error) {
+	if decode, ok := structuredEnvDecoder(path); ok {
+		return parseStructuredEnvData(path, data, decode)
 	}
-	return environmentFile{path: path, values: values}, true, nil
+	return parseEnvDocument(data, ParseOptions{
+		Filename:   path,
+		directives: true,
+		Warn: func(err error) {
+			fmt.Fprintf(envFileWarningWriter, " %s env file warning · %v\n", debugMark(), err)
+		},
+	})
 }
 
 // findEnvFile performs exactly the documented bounded nearest-ancestor search and follows regular-file symlinks.
@@ -508,17 +959,12 @@ func findEnvFile(startDirectory, name string) (string, bool, error) {
 	directory := filepath.Clean(startDirectory)
 	for level := 0; level < MaxDirectorySeekLevels; level++ {
 		candidate := filepath.Join(directory, name)
-		info, err := envFileStat(candidate)
-		switch {
-		case err == nil:
-			if !info.Mode().IsRegular() {
-				return "", false, fmt.Errorf("env file %s is not a regular file", candidate)
-			}
+		found, err := statEnvFile(candidate)
+		if err != nil {
+			return "", false, err
+		}
+		if found {
 			return candidate, true, nil
-		case errors.Is(err, os.ErrNotExist):
-			// Missing candidates are the only errors that permit ancestor fallback.
-		default:
-			return "", false, fmt.Errorf("stat env file %s: %w", candidate, err)
 		}
 
 		parent := filepath.Dir(directory)
@@ -530,6 +976,43 @@ func findEnvFile(startDirectory, name string) (string, bool, error) {
 	return "", false, nil
 }
 
+// statEnvFile reports whether candidate is a regular file; a missing candidate is not an error.
+func statEnvFile(candidate string) (bool, error) {
+	info, err := envFileStat(candidate)
+	switch {
+	case err == nil:
+		if !info.Mode().IsRegular() {
+			return false, fmt.Errorf("env file %s is not a regular file", candidate)
+		}
+		return true, nil
+	case errors.Is(err, os.ErrNotExist):
+		// Missing candidates are the only errors that permit ancestor fallback.
+		return false, nil
+	default:
+		return false, fmt.Errorf("stat env file %s: %w", candidate, err)
+	}
+}
+
+// envFileCandidates lists the paths findEnvFile inspects, stopping at found when the search succeeded.
+func envFileCandidates(startDirectory, name, found string) []string {
+	var candidates []string
+	directory := filepath.Clean(startDirectory)
+	for level := 0; level < MaxDirectorySeekLevels; level++ {
+		candidate := filepath.Join(directory, name)
+		candidates = append(candidates, candidate)
+		if candidate == found {
+			break
+		}
+
+		parent := filepath.Dir(directory)
+		if parent == directory {
+			break
+		}
+		directory = parent
+	}
+	return candidates
+}
+
 const (
 	colorGray  = "\033[90m"
 	colorReset = "\033[0m"
@@ -545,9 +1028,25 @@ func colorMark(color, symbol string) string {
 	return fmt.Sprintf("%s%s%s", color, symbol, colorReset)
 }
 
-// printLoadedEnvFiles reports filenames and APP_ENV only; file values are intentionally never logged.
-func printLoadedEnvFiles(paths []string, appEnv string) {
-	for _, path := range paths {
-		fmt.Fprintf(os.Stdout, " %s .env file loader · env [%v] file [%v]\n", debugMark(), appEnv, path)
+// printLoadedEnvFiles reports filenames, layers, and APP_ENV only; file values are intentionally never logged.
+func printLoadedEnvFiles(plan environmentLoadPlan) {
+	for index, path := range plan.files {
+		fmt.Fprintf(os.Stdout, " %s .env file loader · env [%v] layer [%v] file [%v]\n", debugMark(), plan.appEnv, plan.layers[index], path)
+	}
+	for _, include := range plan.includes {
+		fmt.Fprintf(os.Stdout, " %s .env file loader · env [%v] include [%v] from [%v]\n", debugMark(), plan.appEnv, include.path, include.from)
+	}
+}
+
+// printEnvKeySources reports the directory that supplied each file-owned key, never its value.
+func printEnvKeySources(plan environmentLoadPlan) {
+	keys := make([]string, 0, len(plan.sources))
+	for key := range plan.sources {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		path := plan.sources[key]
+		fmt.Fprintf(os.Stdout, " %s .env file loader · key [%v] dir [%v] file [%v]\n", debugMark(), key, filepath.Dir(path), filepath.Base(path))
 	}
 }
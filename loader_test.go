@@ -24,32 +24,48 @@ func prepareLoaderTest(t *testing.T, keys ...string) {
 	originalGetwd := envFileGetwd
 	originalStat := envFileStat
 	originalRead := envFileRead
+	originalWarningWriter := envFileWarningWriter
+	layerSettings.mu.RLock()
+	originalLayerOptions := layerSettings.options
+	layerSettings.mu.RUnlock()
 	originalLookup := envLookup
 	originalSet := envSet
 	originalUnset := envUnset
 	originalStatFile := statFile
 	originalReadFile := readFile
 	originalGetEnv := getEnv
+	originalWatchReadFile := watchReadFile
 
 	processEnvironmentLoader.mu.Lock()
 	originalLoaded := processEnvironmentLoader.loaded
 	originalValues := cloneLoadedEnvironmentValues(processEnvironmentLoader.values)
 	originalBaseline := cloneEnvironmentSnapshots(processEnvironmentLoader.baseline)
+	originalDefaults := cloneLoadedEnvironmentValues(processEnvironmentLoader.defaults)
+	originalWatched := processEnvironmentLoader.watched
+	originalSources := processEnvironmentLoader.sources
 	processEnvironmentLoader.loaded = false
 	processEnvironmentLoader.values = make(map[string]loadedEnvironmentValue)
+	processEnvironmentLoader.defaults = make(map[string]loadedEnvironmentValue)
 	processEnvironmentLoader.baseline = make(map[string]environmentSnapshot)
+	processEnvironmentLoader.watched = nil
+	processEnvironmentLoader.sources = environmentSources{}
 	processEnvironmentLoader.mu.Unlock()
 
 	t.Cleanup(func() {
 		envFileGetwd = originalGetwd
 		envFileStat = originalStat
 		envFileRead = originalRead
+		envFileWarningWriter = originalWarningWriter
+		layerSettings.mu.Lock()
+		layerSettings.options = originalLayerOptions
+		layerSettings.mu.Unlock()
 		envLookup = originalLookup
 		envSet = originalSet
 		envUnset = originalUnset
 		statFile = originalStatFile
 		readFile = originalReadFile
 		getEnv = originalGetEnv
+		watchReadFile = originalWatchReadFile
 		_ = os.Chdir(originalDirectory)
 		restoreEnvironment()
 
@@ -57,6 +73,9 @@ func prepareLoaderTest(t *testing.T, keys ...string) {
 		processEnvironmentLoader.loaded = originalLoaded
 		processEnvironmentLoader.values = originalValues
 		processEnvironmentLoader.baseline = originalBaseline
+		processEnvironmentLoader.defaults = originalDefaults
+		processEnvironmentLoader.watched = originalWatched
+		processEnvironmentLoader.sources = originalSources
 		processEnvironmentLoader.mu.Unlock()
 	})
 }
@@ -786,6 +805,110 @@ func TestReloadFailurePreservesPreviousConfiguration(t *testing.T) {
 	}
 }
 
+// TestUnloadRestoresBaseline ensures file-owned and synthesized keys return to their exact pre-load state.
+func TestUnloadRestoresBaseline(t *testing.T) {
+	prepareLoaderTest(t, "ENV_QPASS_ABSENT", "ENV_QPASS_EMPTY", "ENV_QPASS_TAKEN")
+	_ = os.Unsetenv("APP_ENV")
+	_ = os.Unsetenv("ENV_QPASS_ABSENT")
+	_ = os.Unsetenv("ENV_QPASS_TAKEN")
+	t.Setenv("ENV_QPASS_EMPTY", "")
+	directory := t.TempDir()
+	writeEnvFile(t, directory, fileEnv, "ENV_QPASS_ABSENT=file\nENV_QPASS_TAKEN=file\n")
+	changeWorkingDirectory(t, directory)
+
+	if err := Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	_ = os.Unsetenv("ENV_QPASS_EMPTY")
+	writeEnvFile(t, directory, fileEnv, "ENV_QPASS_ABSENT=file\nENV_QPASS_TAKEN=file\nENV_QPASS_EMPTY=file\n")
+	if err := Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	t.Setenv("ENV_QPASS_TAKEN", "runtime")
+
+	if err := Unload(); err != nil {
+		t.Fatalf("Unload: %v", err)
+	}
+	if _, present := os.LookupEnv("ENV_QPASS_ABSENT"); present {
+		t.Fatal("expected originally absent key to be unset")
+	}
+	if value, present := os.LookupEnv("ENV_QPASS_EMPTY"); !present || value != "" {
+		t.Fatalf("expected originally empty key to be restored as empty, got value=%q present=%v", value, present)
+	}
+	if _, present := os.LookupEnv("APP_ENV"); present {
+		t.Fatal("expected synthesized APP_ENV default to be removed")
+	}
+	if got := os.Getenv("ENV_QPASS_TAKEN"); got != "runtime" {
+		t.Fatalf("expected application-owned key to remain, got %q", got)
+	}
+	if IsEnvLoaded() {
+		t.Fatal("expected Unload to reset loaded state")
+	}
+	if err := Unload(); err != nil {
+		t.Fatalf("expected repeated Unload to be a no-op, got %v", err)
+	}
+}
+
+// TestUnloadRollsBackFailedRestore ensures a failed Unload leaves the loaded configuration intact.
+func TestUnloadRollsBackFailedRestore(t *testing.T) {
+	prepareLoaderTest(t, "ENV_QPASS_A", "ENV_QPASS_B")
+	t.Setenv("APP_ENV", Local)
+	_ = os.Unsetenv("ENV_QPASS_A")
+	_ = os.Unsetenv("ENV_QPASS_B")
+	directory := t.TempDir()
+	writeEnvFile(t, directory, fileEnv, "ENV_QPASS_A=a\nENV_QPASS_B=b\n")
+	changeWorkingDirectory(t, directory)
+	if err := Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	unsetErr := errors.New("injected unset failure")
+	envUnset = func(key string) error {
+		if key == "ENV_QPASS_B" {
+			return unsetErr
+		}
+		return os.Unsetenv(key)
+	}
+	if err := Unload(); !errors.Is(err, unsetErr) {
+		t.Fatalf("expected unset failure, got %v", err)
+	}
+	if os.Getenv("ENV_QPASS_A") != "a" || os.Getenv("ENV_QPASS_B") != "b" || !IsEnvLoaded() {
+		t.Fatal("expected failed Unload to roll back and stay loaded")
+	}
+
+	envUnset = os.Unsetenv
+	if err := Unload(); err != nil {
+		t.Fatalf("retry Unload: %v", err)
+	}
+	if _, present := os.LookupEnv("ENV_QPASS_A"); present {
+		t.Fatal("expected retry to unload")
+	}
+}
+
+// TestLoadAfterUnloadTakesFreshBaseline ensures values set between Unload and Load become the new baseline.
+func TestLoadAfterUnloadTakesFreshBaseline(t *testing.T) {
+	prepareLoaderTest(t, "ENV_QPASS_FRESH")
+	t.Setenv("APP_ENV", Local)
+	_ = os.Unsetenv("ENV_QPASS_FRESH")
+	directory := t.TempDir()
+	writeEnvFile(t, directory, fileEnv, "ENV_QPASS_FRESH=file\n")
+	changeWorkingDirectory(t, directory)
+	if err := Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if err := Unload(); err != nil {
+		t.Fatalf("Unload: %v", err)
+	}
+
+	t.Setenv("ENV_QPASS_FRESH", "ambient")
+	if err := Load(); err != nil {
+		t.Fatalf("second Load: %v", err)
+	}
+	if got := os.Getenv("ENV_QPASS_FRESH"); got != "ambient" {
+		t.Fatalf("expected ambient value to win after a fresh Load, got %q", got)
+	}
+}
+
 // TestFindEnvFileUsesBoundedNearestSearch ensures discovery prefers proximity and stops at the documented ancestor limit.
 func TestFindEnvFileUsesBoundedNearestSearch(t *testing.T) {
 	t.Run("finds ninth ancestor", func(t *testing.T) {
@@ -989,3 +1112,64 @@ func captureStdout(t *testing.T, fn func()) string {
 	os.Stdout = original
 	return output
 }
+
+// TestLoadAppliesPersonalLayerLast ensures .env.<appenv>.local overrides every shared layer.
+func TestLoadAppliesPersonalLayerLast(t *testing.T) {
+	prepareLoaderTest(t, "ENV_QPASS_PERSONAL", "ENV_QPASS_SHARED")
+	t.Setenv("APP_ENV", Staging)
+	_ = os.Unsetenv("ENV_QPASS_PERSONAL")
+	_ = os.Unsetenv("ENV_QPASS_SHARED")
+	directory := t.TempDir()
+	writeEnvFile(t, directory, fileEnv, "ENV_QPASS_PERSONAL=base\nENV_QPASS_SHARED=base\n")
+	writeEnvFile(t, directory, envFileStaging, "ENV_QPASS_PERSONAL=staging\nENV_QPASS_SHARED=staging\n")
+	writeEnvFile(t, directory, envFileTesting, "ENV_QPASS_PERSONAL=testing\nENV_QPASS_SHARED=testing\n")
+	writeEnvFile(t, directory, envFileStaging+envFilePersonalSuffix, "ENV_QPASS_PERSONAL=mine\n")
+	writeEnvFile(t, directory, envFileProd+envFilePersonalSuffix, "ENV_QPASS_SHARED=wrong-environment\n")
+	changeWorkingDirectory(t, directory)
+
+	output := captureStdout(t, func() {
+		t.Setenv("ENV_DEBUG", "3")
+		if err := Load(); err != nil {
+			t.Fatalf("Load: %v", err)
+		}
+	})
+	if got := os.Getenv("ENV_QPASS_PERSONAL"); got != "mine" {
+		t.Fatalf("expected personal layer to win, got %q", got)
+	}
+	if got := os.Getenv("ENV_QPASS_SHARED"); got != "testing" {
+		t.Fatalf("expected other environments' personal layers to be ignored, got %q", got)
+	}
+	personalLine := "layer [personal] file [" + filepath.Join(directory, ".env.staging.local") + "]"
+	if !strings.Contains(output, personalLine) || !strings.Contains(output, "layer [app] file ["+filepath.Join(directory, envFileStaging)+"]") {
+		t.Fatalf("expected layers in debug output, got %q", output)
+	}
+	if strings.Index(output, "layer [testing]") > strings.Index(output, "layer [personal]") {
+		t.Fatalf("expected personal layer after testing layer, got %q", output)
+	}
+}
+
+// TestPersonalEnvFileForAppEnv ensures personal layers follow registered files and reject unsafe names.
+func TestPersonalEnvFileForAppEnv(t *testing.T) {
+	restoreAppEnvRegistry(t)
+	if err := RegisterAppEnv("qa", AppEnvOptions{File: ".env.quality", Aliases: []string{"quality"}}); err != nil {
+		t.Fatalf("RegisterAppEnv: %v", err)
+	}
+	cases := []struct {
+		appEnv string
+		file   string
+		found  bool
+	}{
+		{appEnv: Local, file: ".env.local.local", found: true},
+		{appEnv: Testing, file: ".env.testing.local", found: true},
+		{appEnv: "quality", file: ".env.quality.local", found: true},
+		{appEnv: "preview", file: ".env.preview.local", found: true},
+		{appEnv: "../secrets"},
+		{appEnv: ""},
+	}
+	for _, test := range cases {
+		got, found := personalEnvFileForAppEnv(test.appEnv)
+		if got != test.file || found != test.found {
+			t.Fatalf("personalEnvFileForAppEnv(%q) = %q, %v; want %q, %v", test.appEnv, got, found, test.file, test.found)
+		}
+	}
+}
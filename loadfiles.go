@@ -0,0 +1,107 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// envFileVariable lists env files that replace discovery, like docker compose --env-file.
+const envFileVariable = "ENV_FILE"
+
+// LoadFiles transactionally applies exactly the listed env files instead of discovering layers.
+// @group Environment loading
+// @behavior mutates-process-env
+//
+// Files are merged in order, so later files win, and existing process values still beat every
+// file. Relative paths are resolved against the working directory when LoadFiles is called. A
+// missing file is an error, and any error leaves the environment and loader state unchanged.
+// Unlike Load, LoadFiles applies even after an earlier load; keys owned by the previous source
+// and absent from the listed files are restored like they are on Reload. Reload and Watch then
+// re-read the same files until Unload.
+//
+// Setting ENV_FILE to a comma-separated list in the process environment has the same effect for
+// Load, Reload, and LoadInto.
+//
+// Example: load a deployment file and a secrets file
+//
+//	tmp, _ := os.MkdirTemp("", "envdoc")
+//	defer os.RemoveAll(tmp)
+//	_ = os.WriteFile(filepath.Join(tmp, "prod.env"), []byte("PORT=80\nLOG_LEVEL=warn"), 0o644)
+//	_ = os.WriteFile(filepath.Join(tmp, "secrets.env"), []byte("LOG_LEVEL=error"), 0o644)
+//
+//	_ = env.LoadFiles(filepath.Join(tmp, "prod.env"), filepath.Join(tmp, "secrets.env"))
+//	env.Dump(os.Getenv("PORT"), os.Getenv("LOG_LEVEL"))
+//	// #string "80"
+//	// #string "error"
+func LoadFiles(paths ...string) error {
+	if len(paths) == 0 {
+		return errors.New("load env files: no paths given")
+	}
+	for _, path := range paths {
+		if strings.TrimSpace(path) == "" {
+			return errors.New("load env files: empty path")
+		}
+	}
+	files := append([]string(nil), paths...)
+	_, err := load(true, func(sources *environmentSources) {
+		sources.files = files
+	})
+	return err
+}
+
+// envFileListValue splits an ENV_FILE value into its paths, ignoring blank entries.
+func envFileListValue(snapshot environmentSnapshot) []string {
+	if !snapshot.present {
+		return nil
+	}
+	var paths []string
+	for _, path := range strings.Split(snapshot.value, ",") {
+		if path = strings.TrimSpace(path); path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// resolveEnvFilePaths makes relative paths absolute against directory.
+func resolveEnvFilePaths(directory string, paths []string) []string {
+	if paths == nil {
+		return nil
+	}
+	resolved := make([]string, len(paths))
+	for index, path := range paths {
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(directory, path)
+		}
+		resolved[index] = filepath.Clean(path)
+	}
+	return resolved
+}
+
+// mergeExplicitEnvFiles merges every listed file in order; unlike discovered layers, each one must exist.
+func mergeExplicitEnvFiles(
+	plan *environmentLoadPlan,
+	startDirectory string,
+	paths []string,
+	previous map[string]loadedEnvironmentValue,
+) error {
+	for _, path := range resolveEnvFilePaths(startDirectory, paths) {
+		plan.watched = append(plan.watched, path)
+		found, err := statEnvFile(path)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return fmt.Errorf("env file %s does not exist", path)
+		}
+		file, err := parseEnvFile(path, false)
+		if err != nil {
+			return err
+		}
+		mergeEnvironmentFile(plan, file, previous)
+		plan.layers = append(plan.layers, layerExplicit)
+	}
+	return nil
+}
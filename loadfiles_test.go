@@ -0,0 +1,119 @@
+package env
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestLoadFilesAppliesListedFilesInOrder ensures explicit files layer in order and Reload re-reads them.
+func TestLoadFilesAppliesListedFilesInOrder(t *testing.T) {
+	prepareLoaderTest(t, "ENV_QPASS_PORT", "ENV_QPASS_LEVEL", "ENV_QPASS_PROCESS", "ENV_QPASS_DISCOVERED")
+	t.Setenv("APP_ENV", Local)
+	t.Setenv("ENV_QPASS_PROCESS", "process")
+	_ = os.Unsetenv("ENV_QPASS_PORT")
+	_ = os.Unsetenv("ENV_QPASS_LEVEL")
+	_ = os.Unsetenv("ENV_QPASS_DISCOVERED")
+	directory := t.TempDir()
+	deploy := filepath.Join(directory, "deploy")
+	if err := os.Mkdir(deploy, 0o755); err != nil {
+		t.Fatalf("make deploy directory: %v", err)
+	}
+	writeEnvFile(t, directory, fileEnv, "ENV_QPASS_DISCOVERED=yes\n")
+	writeEnvFile(t, deploy, "prod.env", "ENV_QPASS_PORT=80\nENV_QPASS_LEVEL=warn\nENV_QPASS_PROCESS=file\n")
+	writeEnvFile(t, directory, "secrets.env", "ENV_QPASS_LEVEL=error\n")
+	changeWorkingDirectory(t, directory)
+
+	if err := Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if err := LoadFiles(filepath.Join("deploy", "prod.env"), "secrets.env"); err != nil {
+		t.Fatalf("LoadFiles: %v", err)
+	}
+	if os.Getenv("ENV_QPASS_PORT") != "80" || os.Getenv("ENV_QPASS_LEVEL") != "error" || os.Getenv("ENV_QPASS_PROCESS") != "process" {
+		t.Fatalf("unexpected values port=%q level=%q process=%q",
+			os.Getenv("ENV_QPASS_PORT"), os.Getenv("ENV_QPASS_LEVEL"), os.Getenv("ENV_QPASS_PROCESS"))
+	}
+	if _, present := os.LookupEnv("ENV_QPASS_DISCOVERED"); present {
+		t.Fatal("expected LoadFiles to restore keys owned only by discovery")
+	}
+
+	writeEnvFile(t, directory, "secrets.env", "ENV_QPASS_LEVEL=debug\n")
+	changeWorkingDirectoryWithin(t, deploy, directory)
+	if err := Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	if got := os.Getenv("ENV_QPASS_LEVEL"); got != "debug" {
+		t.Fatalf("expected Reload to re-read the listed files, got %q", got)
+	}
+
+	if err := Unload(); err != nil {
+		t.Fatalf("Unload: %v", err)
+	}
+	if _, present := os.LookupEnv("ENV_QPASS_PORT"); present {
+		t.Fatal("expected Unload to restore explicit file keys")
+	}
+	processEnvironmentLoader.mu.Lock()
+	files := processEnvironmentLoader.sources.files
+	processEnvironmentLoader.mu.Unlock()
+	if files != nil {
+		t.Fatalf("expected Unload to forget the explicit files, got %v", files)
+	}
+}
+
+// TestLoadFilesRejectsMissingFiles ensures a missing listed file fails without mutation.
+func TestLoadFilesRejectsMissingFiles(t *testing.T) {
+	prepareLoaderTest(t, "ENV_QPASS_PORT")
+	t.Setenv("APP_ENV", Local)
+	_ = os.Unsetenv("ENV_QPASS_PORT")
+	directory := t.TempDir()
+	writeEnvFile(t, directory, "prod.env", "ENV_QPASS_PORT=80\n")
+	changeWorkingDirectory(t, directory)
+
+	err := LoadFiles("prod.env", "missing.env")
+	if err == nil || !strings.Contains(err.Error(), filepath.Join(directory, "missing.env")) {
+		t.Fatalf("expected missing file error, got %v", err)
+	}
+	if _, present := os.LookupEnv("ENV_QPASS_PORT"); present || IsEnvLoaded() {
+		t.Fatal("expected failed LoadFiles to leave environment and state unchanged")
+	}
+	if err := LoadFiles(); err == nil {
+		t.Fatal("expected LoadFiles without paths to fail")
+	}
+	if err := LoadFiles(" "); err == nil {
+		t.Fatal("expected LoadFiles with a blank path to fail")
+	}
+}
+
+// TestEnvFileVariableReplacesDiscovery ensures ENV_FILE selects files for Load and LoadInto.
+func TestEnvFileVariableReplacesDiscovery(t *testing.T) {
+	prepareLoaderTest(t, "ENV_FILE", "ENV_QPASS_PORT", "ENV_QPASS_DISCOVERED")
+	t.Setenv("APP_ENV", Local)
+	_ = os.Unsetenv("ENV_QPASS_PORT")
+	_ = os.Unsetenv("ENV_QPASS_DISCOVERED")
+	directory := t.TempDir()
+	writeEnvFile(t, directory, fileEnv, "ENV_QPASS_DISCOVERED=yes\n")
+	writeEnvFile(t, directory, "a.env", "ENV_QPASS_PORT=1\n")
+	writeEnvFile(t, directory, "b.env", "ENV_QPASS_PORT=2\n")
+	changeWorkingDirectory(t, directory)
+	t.Setenv("ENV_FILE", " a.env, ,b.env ")
+
+	if err := Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if got := os.Getenv("ENV_QPASS_PORT"); got != "2" {
+		t.Fatalf("expected ENV_FILE files in order, got %q", got)
+	}
+	if _, present := os.LookupEnv("ENV_QPASS_DISCOVERED"); present {
+		t.Fatal("expected ENV_FILE to replace discovery")
+	}
+
+	environment, err := LoadInto(directory, LoadIntoOptions{Environ: []string{"ENV_FILE=a.env"}})
+	if err != nil {
+		t.Fatalf("LoadInto: %v", err)
+	}
+	if got := environment.Get("ENV_QPASS_PORT", ""); got != "1" {
+		t.Fatalf("expected LoadInto to honor its ENV_FILE, got %q", got)
+	}
+}
@@ -0,0 +1,239 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"slices"
+)
+
+// Embedded layers are labeled with this prefix and their layer name in ENV_DEBUG output.
+const (
+	layerEmbeddedPrefix = "embedded-"
+	embeddedPathPrefix  = "embed:"
+)
+
+// FSOptions configures LoadFS.
+type FSOptions struct {
+	// Dir is the directory within the file system where discovery starts, such as "config".
+	// Empty means the root.
+	Dir string
+}
+
+// embeddedEnvSource supplies layers that sit below every on-disk file.
+//
+// LoadFS sets fsys and dir; LoadReader sets file, parsed once because a reader cannot be re-read.
+type embeddedEnvSource struct {
+	fsys fs.FS
+	dir  string
+	file *environmentFile
+}
+
+// LoadFS transactionally applies env files from fsys, such as an embed.FS, below every on-disk file.
+// @group Environment loading
+// @behavior mutates-process-env
+//
+// The file system is searched like the working directory, starting at options.Dir and moving up
+// through its ancestors, for .env, the APP_ENV file, the runtime layers, and .env.testing. Every
+// embedded layer ranks below every on-disk file, so files on disk override embedded defaults key
+// by key, and process values still beat both. APP_ENV set by the embedded .env selects the on-disk
+// APP_ENV layer too. Like LoadFiles, LoadFS applies even after an earlier load; Reload then reads
+// the same file system until Unload. ENV_DEBUG reports embedded files with an "embed:" prefix.
+//
+// Example: embed defaults into a binary
+//
+//	// //go:embed config/.env config/.env.production
+//	// var configFS embed.FS
+//	configFS := fstest.MapFS{
+//		"config/.env":            {Data: []byte("APP_ENV=production\nPORT=3000\nLOG_LEVEL=info")},
+//		"config/.env.production": {Data: []byte("LOG_LEVEL=warn")},
+//	}
+//
+//	_ = env.LoadFS(configFS, env.FSOptions{Dir: "config"})
+//	env.Dump(os.Getenv("PORT"), os.Getenv("LOG_LEVEL"))
+//	// #string "3000"
+//	// #string "warn"
+func LoadFS(fsys fs.FS, options FSOptions) error {
+	if fsys == nil {
+		return errors.New("load env fs: nil file system")
+	}
+	dir := options.Dir
+	if dir == "" {
+		dir = "."
+	}
+	if !fs.ValidPath(dir) {
+		return fmt.Errorf("load env fs: invalid directory %q", options.Dir)
+	}
+	source := &embeddedEnvSource{fsys: fsys, dir: dir}
+	_, err := load(true, func(sources *environmentSources) {
+		sources.embedded = source
+	})
+	return err
+}
+
+// LoadReader transactionally applies dotenv content from r as the lowest-priority layer.
+// @group Environment loading
+// @behavior mutates-process-env
+//
+// r is read and parsed once, and name identifies the content in syntax errors and ENV_DEBUG
+// output, such as "stdin". The content ranks below every on-disk file, which are discovered and
+// applied as usual, and process values still beat both. Like LoadFiles, LoadReader applies even
+// after an earlier load; Reload re-applies the same content with fresh on-disk files until Unload.
+//
+// Example: read dotenv content from standard input
+//
+//	// _ = env.LoadReader("stdin", os.Stdin)
+//	_ = env.LoadReader("stdin", strings.NewReader("WORKERS=4"))
+//	env.Dump(os.Getenv("WORKERS"))
+//	// #string "4"
+func LoadReader(name string, r io.Reader) error {
+	if name == "" {
+		return errors.New("load env reader: empty name")
+	}
+	if r == nil {
+		return fmt.Errorf("load env reader %s: nil reader", name)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("read env reader %s: %w", name, err)
+	}
+	entries, err := parseEnvFileData(name, data)
+	if err != nil {
+		return err
+	}
+	file, err := newEnvironmentFile(name, entries, false)
+	if err != nil {
+		return err
+	}
+	source := &embeddedEnvSource{file: &file}
+	_, err = load(true, func(sources *environmentSources) {
+		sources.embedded = source
+	})
+	return err
+}
+
+// mergeEmbeddedBase merges the embedded .env or reader content as the lowest layer, before any
+// on-disk file, so its APP_ENV can select on-disk layers. It returns the paths of embedded files.
+func mergeEmbeddedBase(
+	plan *environmentLoadPlan,
+	source *embeddedEnvSource,
+	previous map[string]loadedEnvironmentValue,
+) (map[string]struct{}, error) {
+	embedded := make(map[string]struct{})
+	if source == nil {
+		return embedded, nil
+	}
+	file := source.file
+	if source.fsys != nil {
+		found, ok, err := loadFSEnvFile(source.fsys, source.dir, fileEnv)
+		if err != nil || !ok {
+			return embedded, err
+		}
+		file = &found
+	}
+	mergeEnvironmentFile(plan, *file, previous)
+	plan.layers = append(plan.layers, layerEmbeddedPrefix+layerBase)
+	embedded[file.path] = struct{}{}
+	return embedded, nil
+}
+
+// mergeEmbeddedLayers merges the embedded APP_ENV, runtime, and testing layers once on-disk layers
+// are planned, so they are selected by the final APP_ENV yet only replace keys that no on-disk file
+// supplies. They are listed right after the embedded base so ENV_DEBUG shows the real precedence.
+func mergeEmbeddedLayers(
+	plan *environmentLoadPlan,
+	source *embeddedEnvSource,
+	embedded map[string]struct{},
+	previous map[string]loadedEnvironmentValue,
+) error {
+	if source == nil || source.fsys == nil {
+		return nil
+	}
+	planned := func(key string) string {
+		return effectiveEnvironmentValue(key, *plan, previous)
+	}
+	appEnv := planned("APP_ENV")
+	if appEnv == "" {
+		appEnv = Local
+	}
+
+	type selectedLayer struct{ name, label string }
+	var selected []selectedLayer
+	if appEnvFile, ok := envFileForAppEnv(appEnv); ok {
+		selected = append(selected, selectedLayer{appEnvFile, layerAppEnv})
+	}
+	for _, layer := range currentRuntimeLayers() {
+		if layer.detect(planned) {
+			selected = append(selected, selectedLayer{layer.file(), layer.name})
+		}
+	}
+	if isAppEnvTestingValue(appEnv) {
+		selected = append(selected, selectedLayer{envFileTesting, layerTesting})
+	}
+
+	insertAt := len(embedded)
+	for _, layer := range selected {
+		file, found, err := loadFSEnvFile(source.fsys, source.dir, layer.name)
+		if err != nil {
+			return err
+		}
+		if !found {
+			continue
+		}
+		for _, key := range sortedFileKeys(file) {
+			if _, fileOwned := previous[key]; !fileOwned {
+				if _, processOwned := plan.lookup(key); processOwned {
+					continue
+				}
+			}
+			if winner, ok := plan.sources[key]; ok {
+				if _, lower := embedded[winner]; !lower {
+					continue
+				}
+			}
+			setPlanFileValue(plan, file, key)
+		}
+		embedded[file.path] = struct{}{}
+		plan.files = slices.Insert(plan.files, insertAt, file.path)
+		plan.layers = slices.Insert(plan.layers, insertAt, layerEmbeddedPrefix+layer.label)
+		insertAt++
+	}
+	return nil
+}
+
+// loadFSEnvFile finds and parses the nearest file named name in dir or its ancestors within fsys.
+func loadFSEnvFile(fsys fs.FS, dir, name string) (environmentFile, bool, error) {
+	directory := dir
+	for level := 0; level < MaxDirectorySeekLevels; level++ {
+		candidate := path.Join(directory, name)
+		info, err := fs.Stat(fsys, candidate)
+		switch {
+		case err == nil:
+			display := embeddedPathPrefix + candidate
+			if !info.Mode().IsRegular() {
+				return environmentFile{}, false, fmt.Errorf("env file %s is not a regular file", display)
+			}
+			data, err := fs.ReadFile(fsys, candidate)
+			if err != nil {
+				return environmentFile{}, false, fmt.Errorf("read env file %s: %w", display, err)
+			}
+			entries, err := parseEnvFileData(display, data)
+			if err != nil {
+				return environmentFile{}, false, err
+			}
+			file, err := newEnvironmentFile(display, entries, false)
+			return file, err == nil, err
+		case errors.Is(err, fs.ErrNotExist):
+		default:
+			return environmentFile{}, false, fmt.Errorf("stat env file %s%s: %w", embeddedPathPrefix, candidate, err)
+		}
+
+		if directory == "." {
+			break
+		}
+		directory = path.Dir(directory)
+	}
+	return environmentFile{}, false, nil
+}
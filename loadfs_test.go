@@ -0,0 +1,125 @@
+package env
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"testing/iotest"
+)
+
+// TestLoadFSRanksEmbeddedLayersBelowDisk ensures on-disk files override every embedded layer key by key.
+func TestLoadFSRanksEmbeddedLayersBelowDisk(t *testing.T) {
+	keys := []string{"ENV_QPASS_PORT", "ENV_QPASS_LEVEL", "ENV_QPASS_EMBEDDED", "ENV_QPASS_DISK_APP", "ENV_DEBUG"}
+	prepareLoaderTest(t, keys...)
+	for _, key := range append(keys, "APP_ENV") {
+		_ = os.Unsetenv(key)
+	}
+	directory := t.TempDir()
+	writeEnvFile(t, directory, fileEnv, "ENV_QPASS_LEVEL=disk\nENV_DEBUG=3\n")
+	writeEnvFile(t, directory, envFileProd, "ENV_QPASS_DISK_APP=yes\n")
+	changeWorkingDirectory(t, directory)
+	fsys := fstest.MapFS{
+		"config/.env":            {Data: []byte("APP_ENV=production\nENV_QPASS_PORT=3000\nENV_QPASS_LEVEL=embedded\n")},
+		"config/.env.production": {Data: []byte("ENV_QPASS_LEVEL=embedded-production\nENV_QPASS_EMBEDDED=yes\n")},
+	}
+
+	output := captureStdout(t, func() {
+		if err := LoadFS(fsys, FSOptions{Dir: "config/service"}); err != nil {
+			t.Fatalf("LoadFS: %v", err)
+		}
+	})
+	want := map[string]string{
+		"APP_ENV":            Production,
+		"ENV_QPASS_PORT":     "3000",
+		"ENV_QPASS_LEVEL":    "disk",
+		"ENV_QPASS_EMBEDDED": "yes",
+		"ENV_QPASS_DISK_APP": "yes",
+	}
+	for key, value := range want {
+		if got := os.Getenv(key); got != value {
+			t.Fatalf("%s = %q, want %q", key, got, value)
+		}
+	}
+	embeddedApp := strings.Index(output, "layer [embedded-app] file [embed:config/.env.production]")
+	diskBase := strings.Index(output, "layer [base]")
+	if strings.Index(output, "layer [embedded-base] file [embed:config/.env]") < 0 || embeddedApp < 0 || diskBase < embeddedApp {
+		t.Fatalf("expected embedded layers to be reported below disk layers, got %q", output)
+	}
+
+	writeEnvFile(t, directory, fileEnv, "ENV_DEBUG=0\n")
+	if err := Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	if got := os.Getenv("ENV_QPASS_LEVEL"); got != "embedded-production" {
+		t.Fatalf("expected Reload to keep the embedded layers, got %q", got)
+	}
+
+	if err := Unload(); err != nil {
+		t.Fatalf("Unload: %v", err)
+	}
+	if err := Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if _, present := os.LookupEnv("ENV_QPASS_PORT"); present {
+		t.Fatal("expected Unload to forget the embedded file system")
+	}
+}
+
+// TestLoadFSRejectsInvalidInput ensures LoadFS validates its file system and directory.
+func TestLoadFSRejectsInvalidInput(t *testing.T) {
+	prepareLoaderTest(t)
+	changeWorkingDirectory(t, t.TempDir())
+
+	if err := LoadFS(nil, FSOptions{}); err == nil {
+		t.Fatal("expected nil file system to be rejected")
+	}
+	if err := LoadFS(fstest.MapFS{}, FSOptions{Dir: "../config"}); err == nil {
+		t.Fatal("expected invalid directory to be rejected")
+	}
+	broken := fstest.MapFS{".env": {Data: []byte("BROKEN='unterminated\n")}}
+	var syntaxErr *SyntaxError
+	if err := LoadFS(broken, FSOptions{}); !errors.As(err, &syntaxErr) || syntaxErr.File != "embed:.env" {
+		t.Fatalf("expected syntax error naming the embedded file, got %v", err)
+	}
+	if IsEnvLoaded() {
+		t.Fatal("expected failed LoadFS to leave the loader unloaded")
+	}
+}
+
+// TestLoadReaderAppliesContentBelowDisk ensures reader content is the lowest layer and is kept for Reload.
+func TestLoadReaderAppliesContentBelowDisk(t *testing.T) {
+	prepareLoaderTest(t, "ENV_QPASS_WORKERS", "ENV_QPASS_LEVEL")
+	t.Setenv("APP_ENV", Local)
+	_ = os.Unsetenv("ENV_QPASS_WORKERS")
+	_ = os.Unsetenv("ENV_QPASS_LEVEL")
+	directory := t.TempDir()
+	writeEnvFile(t, directory, fileEnv, "ENV_QPASS_LEVEL=disk\n")
+	changeWorkingDirectory(t, directory)
+
+	if err := LoadReader("stdin", strings.NewReader("ENV_QPASS_WORKERS=4\nENV_QPASS_LEVEL=stdin\n")); err != nil {
+		t.Fatalf("LoadReader: %v", err)
+	}
+	if os.Getenv("ENV_QPASS_WORKERS") != "4" || os.Getenv("ENV_QPASS_LEVEL") != "disk" {
+		t.Fatalf("unexpected values workers=%q level=%q", os.Getenv("ENV_QPASS_WORKERS"), os.Getenv("ENV_QPASS_LEVEL"))
+	}
+	if err := Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	if got := os.Getenv("ENV_QPASS_WORKERS"); got != "4" {
+		t.Fatalf("expected Reload to keep reader content, got %q", got)
+	}
+
+	var syntaxErr *SyntaxError
+	if err := LoadReader("stdin", strings.NewReader("=broken\n")); !errors.As(err, &syntaxErr) || syntaxErr.File != "stdin" {
+		t.Fatalf("expected syntax error naming the reader, got %v", err)
+	}
+	readErr := errors.New("injected read failure")
+	if err := LoadReader("stdin", iotest.ErrReader(readErr)); !errors.Is(err, readErr) {
+		t.Fatalf("expected read error, got %v", err)
+	}
+	if err := LoadReader("", strings.NewReader("")); err == nil {
+		t.Fatal("expected empty name to be rejected")
+	}
+}
@@ -0,0 +1,594 @@
+package env
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"unicode/utf8"
+)
+
+// DuplicateKeyMode selects how Parse treats a key that is defined more than once.
+type DuplicateKeyMode int
+
+const (
+	// DuplicateKeysWarn reports each redefinition through ParseOptions.Warn and keeps the last value.
+	DuplicateKeysWarn DuplicateKeyMode = iota
+	// DuplicateKeysError rejects a redefinition with a *SyntaxError.
+	DuplicateKeysError
+	// DuplicateKeysAllow keeps the last value without reporting anything.
+	DuplicateKeysAllow
+)
+
+// ParseOptions configures Parse.
+type ParseOptions struct {
+	// Filename is recorded in every *SyntaxError; it is not opened.
+	Filename string
+	// DuplicateKeys selects how a redefined key is handled; the zero value warns.
+	DuplicateKeys DuplicateKeyMode
+	// Warn receives non-fatal problems such as duplicate keys. Nil discards them.
+	Warn func(error)
+
+	// directives lets the loader read [name] headers and #include lines; Load decides where they apply.
+	directives bool
+}
+
+// SyntaxError reports a malformed env file with a 1-based line and column.
+//
+// Columns count characters, not bytes. Messages never include values.
+type SyntaxError struct {
+	File    string
+	Line    int
+	Column  int
+	Message string
+}
+
+// Error formats the error as file:line:column: message.
+func (e *SyntaxError) Error() string {
+	if e.File == "" {
+		return fmt.Sprintf("%d:%d: %s", e.Line, e.Column, e.Message)
+	}
+	return fmt.Sprintf("%s:%d:%d: %s", e.File, e.Line, e.Column, e.Message)
+}
+
+// Parse reads dotenv-formatted data and returns its variables.
+// @group Environment loading
+// @behavior readonly
+//
+// Each line holds KEY=value with an optional export prefix. Blank lines and lines starting with #
+// are ignored. Unquoted values end at the line or at a # preceded by whitespace, and surrounding
+// whitespace is trimmed. Single-quoted and backtick-quoted values are literal; double-quoted
+// values support \n, \r, \t, \", \\, \$, and \' escapes and keep any other backslash, as in
+// "C:\dev\bin", literally. Quoted values may span lines and may be followed only by whitespace
+// and a comment. Malformed input returns a *SyntaxError with the
+// position of the problem. Parse is the parser Load uses for env files; it returns values as
+// written and leaves ${...} references for Load to expand. Parse reads #include lines as comments
+// and rejects [section] headers; only Load resolves them.
+//
+// Example: parse dotenv text
+//
+//	values, err := env.Parse(strings.NewReader("export HOST=localhost\nGREETING=\"hello\\nworld\" # two lines\n"), env.ParseOptions{})
+//	env.Dump(err == nil, values["HOST"], values["GREETING"])
+//	// #bool true
+//	// #string "localhost"
+//	// #string "hello\nworld"
+//
+// Example: positioned syntax errors
+//
+//	_, syntaxErr := env.Parse(strings.NewReader("HOST=localhost\nPORT 8080\n"), env.ParseOptions{Filename: ".env"})
+//	env.Dump(syntaxErr.Error())
+//	// #string ".env:2:6: expected '=' after key PORT"
+func Parse(r io.Reader, options ParseOptions) (map[string]string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("read env data: %w", err)
+	}
+	entries, err := parseEnvDocument(data, options)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		values[entry.key] = entry.value
+	}
+	return values, nil
+}
+
+// parseEnvDocument parses data and applies the duplicate-key policy, keeping every entry in source order.
+func parseEnvDocument(data []byte, options ParseOptions) ([]envEntry, error) {
+	entries, err := parseEnvEntries(data, options.Filename, options.directives)
+	if err != nil {
+		return nil, err
+	}
+	if options.DuplicateKeys == DuplicateKeysAllow {
+		return entries, nil
+	}
+
+	// A key may appear once per section, so duplicates are tracked by section and key.
+	type sectionKey struct{ section, key string }
+	firstLines := make(map[sectionKey]int, len(entries))
+	for _, entry := range entries {
+		if entry.include != "" {
+			continue
+		}
+		id := sectionKey{entry.section, entry.key}
+		firstLine, duplicate := firstLines[id]
+		if !duplicate {
+			firstLines[id] = entry.line
+			continue
+		}
+		duplicateErr := &SyntaxError{
+			File:    options.Filename,
+			Line:    entry.line,
+			Column:  entry.column,
+			Message: fmt.Sprintf("duplicate key %s (first defined on line %d)", entry.key, firstLine),
+		}
+		if options.DuplicateKeys == DuplicateKeysError {
+			return nil, duplicateErr
+		}
+		if options.Warn != nil {
+			options.Warn(duplicateErr)
+		}
+	}
+	return entries, nil
+}
+
+// envEntry is one parsed assignment with the position of its key.
+//
+// template is the value with every literal dollar sign doubled, ready for interpolation; it is only
+// meaningful when expand is true, which excludes single-quoted and backtick-quoted values. section
+// names the [header] the entry follows, if any, and sectionLine and sectionColumn locate it. An
+// #include directive is an entry with an include path and no key. valueStart and valueEnd are the
+// byte offsets of the value as written, quotes included, after any byte order mark; start and end
+// bound the whole assignment, from the beginning of its first line through its final line break.
+type envEntry struct {
+	key           string
+	value         string
+	template      string
+	expand        bool
+	quote         byte
+	line          int
+	column        int
+	section       string
+	sectionLine   int
+	sectionColumn int
+	include       string
+	optional      bool
+	valueStart    int
+	valueEnd      int
+	start         int
+	end           int
+}
+
+// envParser walks dotenv data byte by byte while tracking line starts for positioned errors.
+type envParser struct {
+	data      []byte
+	file      string
+	offset    int
+	line      int
+	lineStart int
+}
+
+// envParserPosition remembers a location so errors can point back at it.
+type envParserPosition struct {
+	offset    int
+	line      int
+	lineStart int
+}
+
+// parseEnvEntries returns every assignment in source order, including duplicates.
+//
+// With directives, a [name] line starts a section that lasts until the next header, and an
+// #include or #include? line becomes an include entry.
+func parseEnvEntries(data []byte, file string, directives bool) ([]envEntry, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	parser := &envParser{data: data, file: file, line: 1}
+
+	var entries []envEntry
+	var section string
+	var sectionPosition envParserPosition
+	for parser.offset < len(parser.data) {
+		parser.skipInlineSpace()
+		switch parser.peek() {
+		case '\n', '\r':
+			if err := parser.finishLine(); err != nil {
+				return nil, err
+			}
+		case '#':
+			if !directives {
+				parser.skipComment()
+				continue
+			}
+			entry, ok, err := parser.parseIncludeDirective()
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				entry.section = section
+				if section != "" {
+					entry.sectionLine = sectionPosition.line
+					entry.sectionColumn = parser.column(sectionPosition)
+				}
+				entries = append(entries, entry)
+			}
+		case '[':
+			if !directives {
+				return nil, parser.errorAt(parser.mark(), "expected variable name, found %s", parser.describe())
+			}
+			sectionPosition = parser.mark()
+			name, err := parser.parseSectionHeader()
+			if err != nil {
+				return nil, err
+			}
+			section = name
+		case 0:
+			if parser.offset >= len(parser.data) {
+				return entries, nil
+			}
+			return nil, parser.errorAt(parser.mark(), "unexpected NUL byte")
+		default:
+			entry, err := parser.parseEntry()
+			if err != nil {
+				return nil, err
+			}
+			if section != "" {
+				entry.section = section
+				entry.sectionLine = sectionPosition.line
+				entry.sectionColumn = parser.column(sectionPosition)
+			}
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// parseEntry parses one KEY=value assignment and the rest of its final line.
+func (p *envParser) parseEntry() (envEntry, error) {
+	start := p.lineStart
+	if bytes.HasPrefix(p.data[p.offset:], []byte("export")) && isInlineSpace(p.peekAt(len("export"))) {
+		p.offset += len("export")
+		p.skipInlineSpace()
+	}
+
+	keyPosition := p.mark()
+	if !isEnvKeyStart(p.peek()) {
+		return envEntry{}, p.errorAt(keyPosition, "expected variable name, found %s", p.describe())
+	}
+	for isEnvKeyPart(p.peek()) {
+		p.offset++
+	}
+	key := string(p.data[keyPosition.offset:p.offset])
+
+	p.skipInlineSpace()
+	if p.peek() != '=' {
+		return envEntry{}, p.errorAt(p.mark(), "expected '=' after key %s", key)
+	}
+	p.offset++
+	p.skipInlineSpace()
+
+	entry := envEntry{key: key, line: keyPosition.line, column: p.column(keyPosition), valueStart: p.offset, start: start}
+	var err error
+	switch quote := p.peek(); quote {
+	case '\'', '`':
+		entry.quote = quote
+		entry.value, err = p.parseLiteralValue(quote)
+	case '"':
+		entry.quote = quote
+		entry.value, entry.template, err = p.parseDoubleQuotedValue()
+		entry.expand = true
+	default:
+		entry.value = p.parseUnquotedValue()
+		entry.template = entry.value
+		entry.expand = true
+	}
+	if err != nil {
+		return envEntry{}, err
+	}
+	entry.valueEnd = p.offset
+	if entry.quote == 0 {
+		entry.valueEnd = entry.valueStart + len(entry.value)
+	}
+
+	if entry.quote != 0 {
+		p.skipInlineSpace()
+		switch p.peek() {
+		case '#':
+			p.skipComment()
+		case '\n', '\r', 0:
+		default:
+			return envEntry{}, p.errorAt(p.mark(), "unexpected %s after quoted value for key %s", p.describe(), key)
+		}
+	}
+	if err := p.finishLine(); err != nil {
+		return envEntry{}, err
+	}
+	entry.end = p.offset
+	return entry, nil
+}
+
+// parseIncludeDirective parses an #include or #include? line, skipping any other comment. Like an
+// unquoted value, the path ends at a comment that follows whitespace.
+func (p *envParser) parseIncludeDirective() (envEntry, bool, error) {
+	position := p.mark()
+	rest := p.data[p.offset:]
+	if !bytes.HasPrefix(rest, []byte("#include")) {
+		p.skipComment()
+		return envEntry{}, false, nil
+	}
+	entry := envEntry{line: position.line, column: p.column(position)}
+	width := len("#include")
+	if len(rest) > width && rest[width] == '?' {
+		entry.optional = true
+		width++
+	}
+	switch next := p.peekAt(width); {
+	case isInlineSpace(next):
+	case next == '\n' || next == '\r' || next == 0:
+		p.offset += width
+		return envEntry{}, false, p.errorAt(p.mark(), "expected path after %s", rest[:width])
+	default:
+		p.skipComment()
+		return envEntry{}, false, nil
+	}
+
+	p.offset += width
+	p.skipInlineSpace()
+	start := p.offset
+	p.skipComment()
+	target := p.data[start:p.offset]
+	for index := range target {
+		if target[index] == '#' && (index == 0 || isInlineSpace(target[index-1])) {
+			target = target[:index]
+			break
+		}
+	}
+	entry.include = string(bytes.TrimRight(target, " \t"))
+	if entry.include == "" {
+		return envEntry{}, false, p.errorAt(p.mark(), "expected path after %s", rest[:width])
+	}
+	return entry, true, nil
+}
+
+// parseSectionHeader parses a [name] line; names use letters, digits, '.', '-', and '_'.
+func (p *envParser) parseSectionHeader() (string, error) {
+	open := p.mark()
+	p.offset++
+	start := p.offset
+	for isSectionNamePart(p.peek()) {
+		p.offset++
+	}
+	name := string(p.data[start:p.offset])
+	if p.peek() != ']' {
+		if name == "" {
+			return "", p.errorAt(p.mark(), "expected section name, found %s", p.describe())
+		}
+		return "", p.errorAt(p.mark(), "expected ']' after section name %s, found %s", name, p.describe())
+	}
+	if name == "" {
+		return "", p.errorAt(open, "empty section name")
+	}
+	p.offset++
+	p.skipInlineSpace()
+	switch p.peek() {
+	case '#':
+		p.skipComment()
+	case '\n', '\r', 0:
+	default:
+		return "", p.errorAt(p.mark(), "unexpected %s after section header [%s]", p.describe(), name)
+	}
+	return name, p.finishLine()
+}
+
+// parseUnquotedValue reads to the end of the line, stopping at a comment that follows whitespace.
+func (p *envParser) parseUnquotedValue() string {
+	start := p.offset
+	end := p.offset
+	for p.offset < len(p.data) && p.data[p.offset] != '\n' {
+		current := p.data[p.offset]
+		if current == '#' && p.offset > 0 && isInlineSpace(p.data[p.offset-1]) {
+			p.skipComment()
+			break
+		}
+		p.offset++
+		if !isInlineSpace(current) && current != '\r' {
+			end = p.offset
+		}
+	}
+	return string(p.data[start:end])
+}
+
+// parseLiteralValue reads a single-quoted or backtick-quoted value without interpreting escapes.
+func (p *envParser) parseLiteralValue(quote byte) (string, error) {
+	open := p.mark()
+	p.offset++
+	closing := bytes.IndexByte(p.data[p.offset:], quote)
+	if closing < 0 {
+		return "", p.errorAt(open, "unterminated %s value", quoteName(quote))
+	}
+	value := p.data[p.offset : p.offset+closing]
+	p.advanceTo(p.offset + closing + 1)
+	return string(bytes.ReplaceAll(value, []byte("\r\n"), []byte("\n"))), nil
+}
+
+// parseDoubleQuotedValue reads a double-quoted value, decoding escapes, and returns its interpolation template.
+//
+// The template matches the value except that an escaped \$ becomes $$, so it stays literal when expanded.
+func (p *envParser) parseDoubleQuotedValue() (string, string, error) {
+	open := p.mark()
+	p.offset++
+	var value, template bytes.Buffer
+	for p.offset < len(p.data) {
+		current := p.data[p.offset]
+		switch current {
+		case '"':
+			p.offset++
+			return value.String(), template.String(), nil
+		case '\\':
+			if p.offset+1 >= len(p.data) {
+				return "", "", p.errorAt(open, "unterminated double-quoted value")
+			}
+			decoded, ok := decodeEnvEscape(p.data[p.offset+1])
+			if !ok {
+				// An unknown escape keeps its backslash; the next character is read as usual.
+				value.WriteByte(current)
+				template.WriteByte(current)
+				p.offset++
+				continue
+			}
+			value.WriteByte(decoded)
+			if decoded == '$' {
+				template.WriteByte('$')
+			}
+			template.WriteByte(decoded)
+			p.offset += 2
+		case '\r':
+			if p.peekAt(1) != '\n' {
+				value.WriteByte(current)
+				template.WriteByte(current)
+			}
+			p.offset++
+		case '\n':
+			value.WriteByte(current)
+			template.WriteByte(current)
+			p.advanceTo(p.offset + 1)
+		default:
+			value.WriteByte(current)
+			template.WriteByte(current)
+			p.offset++
+		}
+	}
+	return "", "", p.errorAt(open, "unterminated double-quoted value")
+}
+
+// decodeEnvEscape maps the character after a backslash in a double-quoted value.
+func decodeEnvEscape(escaped byte) (byte, bool) {
+	switch escaped {
+	case 'n':
+		return '\n', true
+	case 'r':
+		return '\r', true
+	case 't':
+		return '\t', true
+	case '"', '\\', '$', '\'':
+		return escaped, true
+	default:
+		return 0, false
+	}
+}
+
+// finishLine consumes one line ending, or confirms the end of the data.
+func (p *envParser) finishLine() error {
+	switch {
+	case p.offset >= len(p.data):
+		return nil
+	case p.data[p.offset] == '\n':
+		p.advanceTo(p.offset + 1)
+		return nil
+	case p.data[p.offset] == '\r' && p.peekAt(1) == '\n':
+		p.advanceTo(p.offset + 2)
+		return nil
+	default:
+		return p.errorAt(p.mark(), "unexpected %s", p.describe())
+	}
+}
+
+// skipComment moves to the end of the current line without consuming the line ending.
+func (p *envParser) skipComment() {
+	for p.offset < len(p.data) && p.data[p.offset] != '\n' {
+		p.offset++
+	}
+	if p.offset > 0 && p.offset < len(p.data) && p.data[p.offset-1] == '\r' {
+		p.offset--
+	}
+}
+
+// skipInlineSpace skips spaces and tabs.
+func (p *envParser) skipInlineSpace() {
+	for isInlineSpace(p.peek()) {
+		p.offset++
+	}
+}
+
+// advanceTo moves to offset, counting every newline crossed.
+func (p *envParser) advanceTo(offset int) {
+	for ; p.offset < offset; p.offset++ {
+		if p.data[p.offset] == '\n' {
+			p.line++
+			p.lineStart = p.offset + 1
+		}
+	}
+}
+
+// peek returns the current byte, or 0 at the end of the data.
+func (p *envParser) peek() byte {
+	return p.peekAt(0)
+}
+
+// peekAt returns the byte delta positions ahead, or 0 past the end of the data.
+func (p *envParser) peekAt(delta int) byte {
+	if p.offset+delta >= len(p.data) {
+		return 0
+	}
+	return p.data[p.offset+delta]
+}
+
+// mark records the current position.
+func (p *envParser) mark() envParserPosition {
+	return envParserPosition{offset: p.offset, line: p.line, lineStart: p.lineStart}
+}
+
+// column converts a position to a 1-based character column.
+func (p *envParser) column(position envParserPosition) int {
+	return utf8.RuneCount(p.data[position.lineStart:position.offset]) + 1
+}
+
+// errorAt builds a *SyntaxError for position.
+func (p *envParser) errorAt(position envParserPosition, format string, args ...any) *SyntaxError {
+	return &SyntaxError{
+		File:    p.file,
+		Line:    position.line,
+		Column:  p.column(position),
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+// describe names the current character for error messages.
+func (p *envParser) describe() string {
+	if p.offset >= len(p.data) {
+		return "end of file"
+	}
+	switch current, _ := utf8.DecodeRune(p.data[p.offset:]); current {
+	case '\n', '\r':
+		return "end of line"
+	default:
+		return fmt.Sprintf("%q", current)
+	}
+}
+
+// quoteName describes a quote character for error messages.
+func quoteName(quote byte) string {
+	if quote == '`' {
+		return "backtick-quoted"
+	}
+	return "single-quoted"
+}
+
+// isInlineSpace reports whether c separates tokens within a line.
+func isInlineSpace(c byte) bool {
+	return c == ' ' || c == '\t'
+}
+
+// isSectionNamePart reports whether c may appear in a section name.
+func isSectionNamePart(c byte) bool {
+	return isEnvKeyPart(c) || c == '-'
+}
+
+// isEnvKeyStart reports whether c may begin a variable name.
+func isEnvKeyStart(c byte) bool {
+	return c == '_' || 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z'
+}
+
+// isEnvKeyPart reports whether c may continue a variable name.
+func isEnvKeyPart(c byte) bool {
+	return isEnvKeyStart(c) || '0' <= c && c <= '9' || c == '.'
+}
@@ -0,0 +1,195 @@
+package env
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+// TestParseSupportedSyntax ensures every documented form decodes to the expected value.
+func TestParseSupportedSyntax(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  map[string]string
+	}{
+		{name: "plain", input: "A=1\nB = two \n", want: map[string]string{"A": "1", "B": "two"}},
+		{name: "export prefix", input: "export A=1\nexport\tB=2\n", want: map[string]string{"A": "1", "B": "2"}},
+		{name: "export as key", input: "export=yes\n", want: map[string]string{"export": "yes"}},
+		{name: "empty values", input: "A=\nB=''\nC=\"\"\n", want: map[string]string{"A": "", "B": "", "C": ""}},
+		{name: "comments", input: "# heading\n  # indented\nA=1 # trailing\nB=x#y\n", want: map[string]string{"A": "1", "B": "x#y"}},
+		{name: "single quotes are literal", input: `A='a\nb $HOME # not a comment'`, want: map[string]string{"A": `a\nb $HOME # not a comment`}},
+		{name: "backticks are literal", input: "A=`say \"hi\" 'there'`", want: map[string]string{"A": `say "hi" 'there'`}},
+		{name: "unknown escapes stay literal", input: `A="C:\dev\bin"` + "\nB=\"\\d+\\\n\\$x\"\n", want: map[string]string{"A": `C:\dev\bin`, "B": "\\d+\\\n$x"}},
+		{name: "double quote escapes", input: `A="tab\there\nnew \"q\" \\ \$ \'"`, want: map[string]string{"A": "tab\there\nnew \"q\" \\ $ '"}},
+		{name: "multi-line values", input: "A=\"line 1\nline 2\"\nB='x\r\ny'\nC=3\n", want: map[string]string{"A": "line 1\nline 2", "B": "x\ny", "C": "3"}},
+		{name: "comment after quote", input: "A='1' # note\nB=\"2\"\t\n", want: map[string]string{"A": "1", "B": "2"}},
+		{name: "crlf", input: "A=1\r\nB=2 # c\r\n\r\nC='3'\r\n", want: map[string]string{"A": "1", "B": "2", "C": "3"}},
+		{name: "byte order mark", input: "\xef\xbb\xbfA=1", want: map[string]string{"A": "1"}},
+		{name: "dotted keys", input: "app.name=demo\n_PRIVATE=1\n", want: map[string]string{"app.name": "demo", "_PRIVATE": "1"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := Parse(strings.NewReader(test.input), ParseOptions{})
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Fatalf("expected %q, got %q", test.want, got)
+			}
+		})
+	}
+}
+
+// TestParseReportsPositionedSyntaxErrors ensures malformed input names the file, line, and column.
+func TestParseReportsPositionedSyntaxErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		line    int
+		column  int
+		message string
+	}{
+		{name: "missing equals", input: "A=1\nPORT 8080\n", line: 2, column: 6, message: "expected '=' after key PORT"},
+		{name: "invalid key", input: "A=1\n  9LIVES=1\n", line: 2, column: 3, message: `expected variable name, found '9'`},
+		{name: "unterminated single quote", input: "A=1\nB='open\nC=2\n", line: 2, column: 3, message: "unterminated single-quoted value"},
+		{name: "unterminated backtick", input: "B=`open", line: 1, column: 3, message: "unterminated backtick-quoted value"},
+		{name: "unterminated double quote", input: "B=\"open\\\"", line: 1, column: 3, message: "unterminated double-quoted value"},
+		{name: "text after quote", input: "A='one' two\n", line: 1, column: 9, message: "unexpected 't' after quoted value for key A"},
+		{name: "multi-byte column", input: "É=1\n", line: 1, column: 1, message: `expected variable name, found 'É'`},
+		{name: "position after multi-line value", input: "A='x\ny'\nB\n", line: 3, column: 2, message: "expected '=' after key B"},
+		{name: "section header", input: "A=1\n[production]\n", line: 2, column: 1, message: `expected variable name, found '['`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(test.input), ParseOptions{Filename: "app.env"})
+			var syntaxErr *SyntaxError
+			if !errors.As(err, &syntaxErr) {
+				t.Fatalf("expected *SyntaxError, got %v", err)
+			}
+			want := SyntaxError{File: "app.env", Line: test.line, Column: test.column, Message: test.message}
+			if *syntaxErr != want {
+				t.Fatalf("expected %+v, got %+v", want, *syntaxErr)
+			}
+		})
+	}
+}
+
+// TestParseLoaderDirectives ensures loader-only [name] headers and #include lines parse with positions and report malformed forms.
+func TestParseLoaderDirectives(t *testing.T) {
+	entries, err := parseEnvDocument([]byte("A=1\n[production] # live\nA=2\n  [k8s-east]\nB=3\n#include? ../shared.env # optional\n"), ParseOptions{
+		DuplicateKeys: DuplicateKeysError,
+		directives:    true,
+	})
+	if err != nil {
+		t.Fatalf("parse sections: %v", err)
+	}
+	got := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.include != "" {
+			got = append(got, fmt.Sprintf("%s:include %s optional=%t@%d:%d", entry.section, entry.include, entry.optional, entry.line, entry.column))
+			continue
+		}
+		got = append(got, fmt.Sprintf("%s:%s=%s@%d:%d", entry.section, entry.key, entry.value, entry.sectionLine, entry.sectionColumn))
+	}
+	want := []string{":A=1@0:0", "production:A=2@2:1", "k8s-east:B=3@4:3", "k8s-east:include ../shared.env optional=true@6:1"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+
+	failures := []struct {
+		input   string
+		column  int
+		message string
+	}{
+		{input: "[]\n", column: 1, message: "empty section name"},
+		{input: "[prod", column: 6, message: "expected ']' after section name prod, found end of file"},
+		{input: "[prod uction]\n", column: 6, message: "expected ']' after section name prod, found ' '"},
+		{input: "[prod] A=1\n", column: 8, message: "unexpected 'A' after section header [prod]"},
+		{input: "#include\n", column: 9, message: "expected path after #include"},
+		{input: "#include?   # shared\n", column: 21, message: "expected path after #include?"},
+	}
+	for _, failure := range failures {
+		_, err := parseEnvDocument([]byte(failure.input), ParseOptions{Filename: ".env", directives: true})
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) || syntaxErr.Line != 1 || syntaxErr.Column != failure.column || syntaxErr.Message != failure.message {
+			t.Fatalf("input %q: expected %d %q, got %v", failure.input, failure.column, failure.message, err)
+		}
+	}
+}
+
+// TestParseDuplicateKeyModes ensures duplicates warn by default, can be rejected, or allowed silently.
+func TestParseDuplicateKeyModes(t *testing.T) {
+	input := "A=1\nB=2\nA=3\n"
+
+	var warnings []error
+	values, err := Parse(strings.NewReader(input), ParseOptions{Filename: ".env", Warn: func(err error) {
+		warnings = append(warnings, err)
+	}})
+	if err != nil || values["A"] != "3" {
+		t.Fatalf("expected last value to win, got %v err=%v", values, err)
+	}
+	if len(warnings) != 1 || warnings[0].Error() != ".env:3:1: duplicate key A (first defined on line 1)" {
+		t.Fatalf("unexpected warnings %v", warnings)
+	}
+
+	_, err = Parse(strings.NewReader(input), ParseOptions{DuplicateKeys: DuplicateKeysError})
+	var syntaxErr *SyntaxError
+	if !errors.As(err, &syntaxErr) || syntaxErr.Line != 3 {
+		t.Fatalf("expected duplicate key error on line 3, got %v", err)
+	}
+
+	warnings = nil
+	values, err = Parse(strings.NewReader(input), ParseOptions{DuplicateKeys: DuplicateKeysAllow, Warn: func(err error) {
+		warnings = append(warnings, err)
+	}})
+	if err != nil || values["A"] != "3" || len(warnings) != 0 {
+		t.Fatalf("expected silent last-wins, got %v err=%v warnings=%v", values, err, warnings)
+	}
+}
+
+// TestParseReturnsReaderErrors ensures read failures are wrapped rather than reported as syntax errors.
+func TestParseReturnsReaderErrors(t *testing.T) {
+	readErr := errors.New("injected read failure")
+	if _, err := Parse(iotest.ErrReader(readErr), ParseOptions{}); !errors.Is(err, readErr) {
+		t.Fatalf("expected read error, got %v", err)
+	}
+}
+
+// TestLoadReportsSyntaxErrorsAndDuplicateWarnings ensures the loader surfaces parser diagnostics.
+func TestLoadReportsSyntaxErrorsAndDuplicateWarnings(t *testing.T) {
+	prepareLoaderTest(t, "ENV_QPASS_DUP")
+	t.Setenv("APP_ENV", Local)
+	_ = os.Unsetenv("ENV_QPASS_DUP")
+	directory := t.TempDir()
+	changeWorkingDirectory(t, directory)
+	var warnings bytes.Buffer
+	envFileWarningWriter = &warnings
+
+	writeEnvFile(t, directory, fileEnv, "ENV_QPASS_DUP=secret-one\nENV_QPASS_DUP=secret-two\n")
+	if err := Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if os.Getenv("ENV_QPASS_DUP") != "secret-two" {
+		t.Fatalf("expected last duplicate to win, got %q", os.Getenv("ENV_QPASS_DUP"))
+	}
+	output := warnings.String()
+	if !strings.Contains(output, "duplicate key ENV_QPASS_DUP (first defined on line 1)") || strings.Contains(output, "secret") {
+		t.Fatalf("expected a value-free duplicate warning, got %q", output)
+	}
+
+	writeEnvFile(t, directory, fileEnv, "ENV_QPASS_DUP=ok\nBROKEN LINE\n")
+	err := Reload()
+	var syntaxErr *SyntaxError
+	if !errors.As(err, &syntaxErr) || syntaxErr.Line != 2 || !strings.HasSuffix(syntaxErr.File, fileEnv) {
+		t.Fatalf("expected positioned syntax error from Reload, got %v", err)
+	}
+}
@@ -1,6 +1,9 @@
 package env
 
-import "runtime"
+import (
+	"runtime"
+	"strconv"
+)
 
 // goos and goarch are internal shims that allow tests to override the
 // detected operating system and architecture.
@@ -135,3 +138,46 @@ func IsUnix() bool {
 func IsContainerOS() bool {
 	return goos == "linux"
 }
+
+// ciProviderMarkers are set by CI providers that do not always set CI.
+var ciProviderMarkers = []string{
+	"GITHUB_ACTIONS",
+	"GITLAB_CI",
+	"BUILDKITE",
+	"CIRCLECI",
+	"JENKINS_URL",
+	"TF_BUILD",
+	"TEAMCITY_VERSION",
+	"CODEBUILD_BUILD_ID",
+	"BITBUCKET_BUILD_NUMBER",
+}
+
+// IsCI reports whether the process is running on a continuous integration runner.
+// @group Runtime
+// @behavior readonly
+//
+// Checks CI, which most providers set, then provider markers such as GITHUB_ACTIONS, GITLAB_CI,
+// and JENKINS_URL. An explicit CI=false or CI=0 always wins.
+//
+// Example:
+//
+//	env.Dump(env.IsCI())
+//	// #bool true  (on CI runners)
+//	// #bool false (on developer machines)
+func IsCI() bool {
+	return isCIWithEnv(getEnv)
+}
+
+// isCIWithEnv detects CI runners while allowing planned loader values to be evaluated.
+func isCIWithEnv(getenv func(string) string) bool {
+	if value := getenv("CI"); value != "" {
+		enabled, err := strconv.ParseBool(value)
+		return err != nil || enabled
+	}
+	for _, marker := range ciProviderMarkers {
+		if getenv(marker) != "" {
+			return true
+		}
+	}
+	return false
+}
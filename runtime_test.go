@@ -145,3 +145,26 @@ func TestIsContainerOS(t *testing.T) {
 		t.Fatalf("expected windows NOT to be container OS")
 	}
 }
+
+// TestIsCIWithEnv ensures CI detection honors the CI flag and provider markers.
+func TestIsCIWithEnv(t *testing.T) {
+	cases := []struct {
+		name string
+		env  map[string]string
+		want bool
+	}{
+		{name: "none", env: map[string]string{}, want: false},
+		{name: "ci true", env: map[string]string{"CI": "true"}, want: true},
+		{name: "ci custom", env: map[string]string{"CI": "woodpecker"}, want: true},
+		{name: "provider marker", env: map[string]string{"JENKINS_URL": "https://ci.example"}, want: true},
+		{name: "explicit false", env: map[string]string{"CI": "false", "GITHUB_ACTIONS": "true"}, want: false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			getenv := func(key string) string { return tc.env[key] }
+			if got := isCIWithEnv(getenv); got != tc.want {
+				t.Fatalf("isCIWithEnv = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
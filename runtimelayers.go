@@ -0,0 +1,175 @@
+package env
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// layerOS labels the .env.<goos> layer in ENV_DEBUG output.
+const layerOS = "os"
+
+// knownGOOS lists GOOS values whose .env.<goos> names belong to the operating system layer.
+var knownGOOS = []string{
+	"aix", "android", "darwin", "dragonfly", "freebsd", "hurd", "illumos", "ios", "js",
+	"linux", "nacl", "netbsd", "openbsd", "plan9", "solaris", "wasip1", "windows", "zos",
+}
+
+// RuntimeDetector reports whether a runtime layer applies.
+//
+// getenv returns the value a key will have once the layers planned so far are applied, so
+// detection sees values set by .env and the APP_ENV layer before the process changes.
+type RuntimeDetector func(getenv func(string) string) bool
+
+// runtimeLayer is one detected layer; file is computed at load time so .env.<goos> follows OS.
+type runtimeLayer struct {
+	name   string
+	file   func() string
+	detect RuntimeDetector
+}
+
+// runtimeLayers holds the built-in layers followed by registered ones, in application order.
+var runtimeLayers = struct {
+	mu     sync.RWMutex
+	layers []runtimeLayer
+}{
+	layers: []runtimeLayer{
+		{
+			name:   layerOS,
+			file:   func() string { return fileEnv + "." + OS() },
+			detect: func(func(string) string) bool { return true },
+		},
+		{
+			name: layerHost,
+			file: func() string { return fileEnvHost },
+			detect: func(getenv func(string) string) bool {
+				return isHostEnvironmentWithEnv(getenv) || IsDockerInDocker()
+			},
+		},
+		{
+			name:   layerDocker,
+			file:   func() string { return envFileDocker },
+			detect: func(func(string) string) bool { return IsDocker() },
+		},
+		{
+			name:   layerKubernetes,
+			file:   func() string { return envFileKube },
+			detect: isKubernetesWithEnv,
+		},
+		{
+			name:   layerCI,
+			file:   func() string { return envFileCI },
+			detect: isCIWithEnv,
+		},
+	},
+}
+
+// RegisterRuntimeLayer adds a .env.<name> layer that Load applies when detect reports true.
+// @group Environment loading
+// @behavior mutates-loader
+//
+// Runtime layers are merged after the APP_ENV layer and before .env.testing, in this order:
+// .env.<goos> such as .env.linux, .env.host, .env.docker, .env.kubernetes, .env.ci, and then
+// registered layers in registration order. Later layers win, and process values still beat every
+// runtime layer. detect runs on every Load and Reload and receives a getenv that sees the layers
+// planned so far. Names use lowercase letters, digits, '-', and '_', and may not reuse a runtime
+// layer, an operating system, or an application environment name or file, or the reserved
+// defaults, override, testing, and key.
+//
+// Example: apply .env.gpu when a GPU is exposed
+//
+//	tmp, _ := os.MkdirTemp("", "envdoc")
+//	defer os.RemoveAll(tmp)
+//	originalDirectory, _ := os.Getwd()
+//	defer os.Chdir(originalDirectory)
+//	_ = os.Chdir(tmp)
+//	_ = os.WriteFile(filepath.Join(tmp, ".env"), []byte("NVIDIA_VISIBLE_DEVICES=all"), 0o644)
+//	_ = os.WriteFile(filepath.Join(tmp, ".env.gpu"), []byte("BATCH_SIZE=64"), 0o644)
+//
+//	_ = env.RegisterRuntimeLayer("gpu", func(getenv func(string) string) bool {
+//		return getenv("NVIDIA_VISIBLE_DEVICES") != ""
+//	})
+//	_ = env.Load()
+//	env.Dump(os.Getenv("BATCH_SIZE"))
+//	// #string "64"
+func RegisterRuntimeLayer(name string, detect RuntimeDetector) error {
+	if !isValidAppEnvName(name) {
+		return fmt.Errorf("register runtime layer %q: invalid name", name)
+	}
+	if detect == nil {
+		return fmt.Errorf("register runtime layer %s: nil detector", name)
+	}
+	if isReservedLayerName(name) || name == Testing || slices.Contains(knownGOOS, name) {
+		return fmt.Errorf("register runtime layer %s: name is reserved", name)
+	}
+	if _, ok := appEnvironments.lookup(name); ok {
+		return fmt.Errorf("register runtime layer %s: name is an application environment", name)
+	}
+	file := fileEnv + "." + name
+	if appEnv, ok := appEnvironments.readingFile(file); ok {
+		return fmt.Errorf("register runtime layer %s: file %s is read by APP_ENV %s", name, file, appEnv)
+	}
+
+	runtimeLayers.mu.Lock()
+	defer runtimeLayers.mu.Unlock()
+	for _, layer := range runtimeLayers.layers {
+		if layer.name == name {
+			return fmt.Errorf("register runtime layer %s: already registered", name)
+		}
+	}
+	runtimeLayers.layers = append(runtimeLayers.layers, runtimeLayer{
+		name:   name,
+		file:   func() string { return file },
+		detect: detect,
+	})
+	return nil
+}
+
+// runtimeLayerReading returns the runtime layer that reads file, counting every .env.<goos> name
+// as the operating system layer's so a registration cannot claim another platform's file.
+func runtimeLayerReading(file string) (string, bool) {
+	if suffix, ok := strings.CutPrefix(file, fileEnv+"."); ok && slices.Contains(knownGOOS, suffix) {
+		return layerOS, true
+	}
+	runtimeLayers.mu.RLock()
+	defer runtimeLayers.mu.RUnlock()
+	for _, layer := range runtimeLayers.layers {
+		if layer.name != layerOS && layer.file() == file {
+			return layer.name, true
+		}
+	}
+	return "", false
+}
+
+// currentRuntimeLayers returns a stable copy so detectors run without holding the registry lock.
+func currentRuntimeLayers() []runtimeLayer {
+	runtimeLayers.mu.RLock()
+	defer runtimeLayers.mu.RUnlock()
+	return append([]runtimeLayer(nil), runtimeLayers.layers...)
+}
+
+// mergeRuntimeLayers applies every runtime layer whose detector matches the planned environment.
+//
+// Each detector sees the values merged by earlier runtime layers, so .env.docker may set a marker
+// that a registered layer later detects. A matching .env section, such as [kubernetes], is merged
+// just before its file.
+func mergeRuntimeLayers(
+	plan *environmentLoadPlan,
+	startDirectory string,
+	previous map[string]loadedEnvironmentValue,
+) error {
+	planned := func(key string) string {
+		return effectiveEnvironmentValue(key, *plan, previous)
+	}
+	for _, layer := range currentRuntimeLayers() {
+		if !layer.detect(planned) {
+			continue
+		}
+		mergeEnvironmentSection(plan, strings.TrimPrefix(layer.file(), fileEnv+"."), previous)
+		if err := mergeEnvironmentLayer(plan, startDirectory, layer.file(), layer.name, previous); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package env
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+// restoreRuntimeLayers isolates runtime layer registrations made by one test.
+func restoreRuntimeLayers(t *testing.T) {
+	t.Helper()
+	layers := currentRuntimeLayers()
+	t.Cleanup(func() {
+		runtimeLayers.mu.Lock()
+		defer runtimeLayers.mu.Unlock()
+		runtimeLayers.layers = layers
+	})
+}
+
+// stubContainerRuntime makes container detection report a Docker container inside Kubernetes or a plain host.
+func stubContainerRuntime(t *testing.T, docker bool) {
+	t.Helper()
+	statFile = func(path string) (os.FileInfo, error) {
+		if docker && path == fileDockerEnv {
+			return nil, nil
+		}
+		return nil, os.ErrNotExist
+	}
+	readFile = func(string) ([]byte, error) { return []byte("0::/user.slice"), nil }
+}
+
+// TestLoadAppliesBuiltInRuntimeLayers ensures detected runtime layers merge in their documented order.
+func TestLoadAppliesBuiltInRuntimeLayers(t *testing.T) {
+	prepareLoaderTest(t, "ENV_QPASS_RUNTIME", "ENV_QPASS_OS", "KUBERNETES_SERVICE_HOST", "CI")
+	t.Cleanup(resetRuntime)
+	t.Setenv("APP_ENV", Local)
+	_ = os.Unsetenv("ENV_QPASS_RUNTIME")
+	_ = os.Unsetenv("ENV_QPASS_OS")
+	_ = os.Unsetenv("KUBERNETES_SERVICE_HOST")
+	t.Setenv("CI", "false")
+	goos = "plan9"
+	stubContainerRuntime(t, true)
+	directory := t.TempDir()
+	writeEnvFile(t, directory, fileEnv, "KUBERNETES_SERVICE_HOST=10.0.0.1\n")
+	writeEnvFile(t, directory, ".env.plan9", "ENV_QPASS_OS=plan9\nENV_QPASS_RUNTIME=os\n")
+	writeEnvFile(t, directory, fileEnvHost, "ENV_QPASS_RUNTIME=host\n")
+	writeEnvFile(t, directory, envFileDocker, "ENV_QPASS_RUNTIME=docker\n")
+	writeEnvFile(t, directory, envFileKube, "ENV_QPASS_RUNTIME=kubernetes\n")
+	writeEnvFile(t, directory, envFileCI, "ENV_QPASS_RUNTIME=ci\n")
+	changeWorkingDirectory(t, directory)
+
+	if err := Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if got := os.Getenv("ENV_QPASS_OS"); got != "plan9" {
+		t.Fatalf("expected the GOOS layer, got %q", got)
+	}
+	if got := os.Getenv("ENV_QPASS_RUNTIME"); got != "kubernetes" {
+		t.Fatalf("expected the planned Kubernetes marker to select the last detected layer, got %q", got)
+	}
+
+	t.Setenv("CI", "true")
+	if err := Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	if got := os.Getenv("ENV_QPASS_RUNTIME"); got != "ci" {
+		t.Fatalf("expected Reload to re-run detection, got %q", got)
+	}
+}
+
+// TestRegisterRuntimeLayerAppliesDetectedLayers ensures registered layers follow the built-ins and see earlier layers.
+func TestRegisterRuntimeLayerAppliesDetectedLayers(t *testing.T) {
+	prepareLoaderTest(t, "ENV_QPASS_GPU", "ENV_QPASS_MARKER", "ENV_QPASS_SKIPPED")
+	restoreRuntimeLayers(t)
+	t.Setenv("APP_ENV", Local)
+	_ = os.Unsetenv("ENV_QPASS_GPU")
+	_ = os.Unsetenv("ENV_QPASS_MARKER")
+	_ = os.Unsetenv("ENV_QPASS_SKIPPED")
+	stubContainerRuntime(t, false)
+	directory := t.TempDir()
+	writeEnvFile(t, directory, fileEnvHost, "ENV_QPASS_MARKER=gpu\n")
+	writeEnvFile(t, directory, ".env.gpu", "ENV_QPASS_GPU=yes\n")
+	writeEnvFile(t, directory, ".env.tpu", "ENV_QPASS_SKIPPED=yes\n")
+	changeWorkingDirectory(t, directory)
+
+	if err := RegisterRuntimeLayer("gpu", func(getenv func(string) string) bool {
+		return getenv("ENV_QPASS_MARKER") == "gpu"
+	}); err != nil {
+		t.Fatalf("RegisterRuntimeLayer: %v", err)
+	}
+	if err := RegisterRuntimeLayer("tpu", func(func(string) string) bool { return false }); err != nil {
+		t.Fatalf("RegisterRuntimeLayer: %v", err)
+	}
+
+	if err := Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if os.Getenv("ENV_QPASS_GPU") != "yes" {
+		t.Fatal("expected the detected layer to see the host layer marker")
+	}
+	if _, present := os.LookupEnv("ENV_QPASS_SKIPPED"); present {
+		t.Fatal("expected an undetected layer to be skipped")
+	}
+}
+
+// TestRegisterRuntimeLayerRejectsConflicts ensures invalid or conflicting names leave the registry unchanged.
+func TestRegisterRuntimeLayerRejectsConflicts(t *testing.T) {
+	restoreRuntimeLayers(t)
+	restoreAppEnvRegistry(t)
+	detect := func(func(string) string) bool { return true }
+	if err := RegisterAppEnv("qa", AppEnvOptions{File: ".env.gpu"}); err != nil {
+		t.Fatalf("RegisterAppEnv: %v", err)
+	}
+
+	cases := map[string]string{
+		"Bad":       "invalid name",
+		"docker":    "already registered",
+		"linux":     "reserved",
+		"override":  "reserved",
+		"key":       "reserved",
+		"staging":   "application environment",
+		"qa":        "application environment",
+		"gpu":       "file .env.gpu is read by APP_ENV qa",
+		"preview-1": "",
+	}
+	for name, want := range cases {
+		err := RegisterRuntimeLayer(name, detect)
+		if want == "" {
+			if err != nil {
+				t.Fatalf("RegisterRuntimeLayer(%q): %v", name, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("RegisterRuntimeLayer(%q) = %v, want %q", name, err, want)
+		}
+	}
+	if err := RegisterRuntimeLayer("nil-detector", nil); err == nil {
+		t.Fatal("expected nil detector to be rejected")
+	}
+	if err := RegisterAppEnv("preview-1", AppEnvOptions{}); err == nil {
+		t.Fatal("expected RegisterAppEnv to reject a runtime layer name")
+	}
+}
@@ -8,8 +8,12 @@ import (
 )
 
 // Scope composes a stable environment variable prefix for related keys.
+//
+// Scopes created by WithPrefix read the process environment; scopes created by
+// Environment.WithPrefix read that isolated environment.
 type Scope struct {
-	prefix string
+	prefix      string
+	environment *Environment
 }
 
 // WithPrefix returns a scope rooted at prefix after minimal normalization.
@@ -56,11 +60,11 @@ func (s Scope) Child(name string) Scope {
 	child := normalizeScopeSegment(name)
 	switch {
 	case s.prefix == "":
-		return Scope{prefix: child}
+		return Scope{prefix: child, environment: s.environment}
 	case child == "":
 		return s
 	default:
-		return Scope{prefix: s.prefix + "_" + child}
+		return Scope{prefix: s.prefix + "_" + child, environment: s.environment}
 	}
 }
 
@@ -134,9 +138,8 @@ func (s Scope) ChildNames(rootKeys []string) []string {
 	prefix := s.prefix + "_"
 	children := map[string]struct{}{}
 
-	for _, entry := range os.Environ() {
-		key, _, ok := strings.Cut(entry, "=")
-		if !ok || !strings.HasPrefix(key, prefix) {
+	for _, key := range s.keys() {
+		if !strings.HasPrefix(key, prefix) {
 			continue
 		}
 
@@ -175,84 +178,107 @@ func (s Scope) ChildNames(rootKeys []string) []string {
 // @group Typed getters
 // @behavior readonly
 func (s Scope) Get(key, fallback string) string {
-	return Get(s.Key(key), fallback)
+	return stringValue(s.getenv(s.Key(key)), fallback)
 }
 
 // GetInt returns the int value for key within the scope.
 // @group Typed getters
 // @behavior readonly
 func (s Scope) GetInt(key, fallback string) int {
-	return GetInt(s.Key(key), fallback)
+	return intValue(s.getenv(s.Key(key)), fallback)
 }
 
 // GetInt64 returns the int64 value for key within the scope.
 // @group Typed getters
 // @behavior readonly
 func (s Scope) GetInt64(key, fallback string) int64 {
-	return GetInt64(s.Key(key), fallback)
+	return int64Value(s.getenv(s.Key(key)), fallback)
 }
 
 // GetUint returns the uint value for key within the scope.
 // @group Typed getters
 // @behavior readonly
 func (s Scope) GetUint(key, fallback string) uint {
-	return GetUint(s.Key(key), fallback)
+	return uintValue(s.getenv(s.Key(key)), fallback)
 }
 
 // GetUint64 returns the uint64 value for key within the scope.
 // @group Typed getters
 // @behavior readonly
 func (s Scope) GetUint64(key, fallback string) uint64 {
-	return GetUint64(s.Key(key), fallback)
+	return uint64Value(s.getenv(s.Key(key)), fallback)
 }
 
 // GetFloat returns the float64 value for key within the scope.
 // @group Typed getters
 // @behavior readonly
 func (s Scope) GetFloat(key, fallback string) float64 {
-	return GetFloat(s.Key(key), fallback)
+	return floatValue(s.getenv(s.Key(key)), fallback)
 }
 
 // GetBool returns the bool value for key within the scope.
 // @group Typed getters
 // @behavior readonly
 func (s Scope) GetBool(key, fallback string) bool {
-	return GetBool(s.Key(key), fallback)
+	return boolValue(s.getenv(s.Key(key)), fallback)
 }
 
 // GetDuration returns the duration value for key within the scope.
 // @group Typed getters
 // @behavior readonly
 func (s Scope) GetDuration(key, fallback string) time.Duration {
-	return GetDuration(s.Key(key), fallback)
+	return durationValue(s.getenv(s.Key(key)), fallback)
 }
 
 // GetEnum returns the enum value for key within the scope.
 // @group Typed getters
 // @behavior readonly
 func (s Scope) GetEnum(key, fallback string, allowed []string) string {
-	return GetEnum(s.Key(key), fallback, allowed)
+	return enumValue(stringValue(s.getenv(s.Key(key)), fallback), fallback, allowed)
 }
 
 // GetSlice returns the string slice value for key within the scope.
 // @group Typed getters
 // @behavior readonly
 func (s Scope) GetSlice(key, fallback string) []string {
-	return GetSlice(s.Key(key), fallback)
+	return sliceValue(stringValue(s.getenv(s.Key(key)), fallback))
 }
 
 // GetMap returns the string map value for key within the scope.
 // @group Typed getters
 // @behavior readonly
 func (s Scope) GetMap(key, fallback string) map[string]string {
-	return GetMap(s.Key(key), fallback)
+	return parseStringMap(stringValue(s.getenv(s.Key(key)), fallback))
 }
 
 // GetMapInt returns the int map value for key within the scope.
 // @group Typed getters
 // @behavior readonly
 func (s Scope) GetMapInt(key, fallback string, defaultValue int) map[string]int {
-	return GetMapInt(s.Key(key), fallback, defaultValue)
+	return mapIntValue(stringValue(s.getenv(s.Key(key)), fallback), defaultValue)
+}
+
+// getenv reads key from the scope's isolated environment or, by default, the process environment.
+func (s Scope) getenv(key string) string {
+	if s.environment != nil {
+		return s.environment.values[key]
+	}
+	return os.Getenv(key)
+}
+
+// keys lists the variable names visible to the scope.
+func (s Scope) keys() []string {
+	if s.environment != nil {
+		return s.environment.Keys()
+	}
+	environ := os.Environ()
+	keys := make([]string, 0, len(environ))
+	for _, entry := range environ {
+		if key, _, ok := strings.Cut(entry, "="); ok {
+			keys = append(keys, key)
+		}
+	}
+	return keys
 }
 
 // normalizeScopeSegment preserves caller-selected case while removing accidental boundary separators.
@@ -0,0 +1,115 @@
+package env
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+)
+
+var (
+	// These are shims that tests override.
+	signalNotify                = signal.Notify
+	signalStop                  = signal.Stop
+	signalLogWriter   io.Writer = os.Stderr
+	signalReloadClock           = time.Now
+)
+
+// SignalReloader reports the outcome of reloads triggered by ReloadOnSignal.
+type SignalReloader struct {
+	mu         sync.Mutex
+	lastReload time.Time
+	lastError  error
+	done       chan struct{}
+}
+
+// ReloadOnSignal reloads env files whenever the process receives one of signals, SIGHUP by default.
+// @group Environment loading
+// @behavior mutates-process-env
+//
+// Each signal triggers one Reload, so reloads are serialized through the loader like any other
+// call. A failed reload keeps the previous configuration, is logged to standard error without
+// values, and is recorded as LastError; the process keeps running. Signal delivery stops when ctx
+// is done. SIGHUP is only the default on Unix; elsewhere, such as Windows and js/wasm, pass the
+// signals to listen for, or ReloadOnSignal waits for ctx without reloading.
+//
+// Example: reload on SIGHUP
+//
+//	ctx, cancel := context.WithCancel(context.Background())
+//	defer cancel()
+//	reloader := env.ReloadOnSignal(ctx)
+//	env.Dump(reloader.LastReload().IsZero(), reloader.LastError() == nil)
+//	// #bool true
+//	// #bool true
+func ReloadOnSignal(ctx context.Context, signals ...os.Signal) *SignalReloader {
+	if len(signals) == 0 {
+		signals = defaultReloadSignals()
+	}
+	received := make(chan os.Signal, 1)
+	// Notify with no signals would relay every signal, so an empty list listens for nothing.
+	if len(signals) > 0 {
+		signalNotify(received, signals...)
+	}
+
+	reloader := &SignalReloader{done: make(chan struct{})}
+	go reloader.run(ctx, received)
+	return reloader
+}
+
+// run handles signals one at a time until ctx is done.
+func (r *SignalReloader) run(ctx context.Context, received chan os.Signal) {
+	defer close(r.done)
+	defer signalStop(received)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case sig := <-received:
+			r.reload(sig)
+		}
+	}
+}
+
+// reload records the outcome of one signal-triggered Reload.
+func (r *SignalReloader) reload(sig os.Signal) {
+	err := Reload()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lastError = err
+	if err != nil {
+		fmt.Fprintf(signalLogWriter, " %s env reload failed · signal [%v] error [%v]\n", debugMark(), sig, err)
+		return
+	}
+	r.lastReload = signalReloadClock()
+}
+
+// LastReload returns when the most recent successful signal-triggered reload finished.
+// @group Environment loading
+// @behavior readonly
+//
+// The zero time means no signal-triggered reload has succeeded yet.
+func (r *SignalReloader) LastReload() time.Time {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.lastReload
+}
+
+// LastError returns the error from the most recent signal-triggered reload, or nil after a success.
+// @group Environment loading
+// @behavior readonly
+func (r *SignalReloader) LastError() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.lastError
+}
+
+// Done returns a channel that closes after signal handling has stopped.
+// @group Environment loading
+// @behavior readonly
+func (r *SignalReloader) Done() <-chan struct{} {
+	return r.done
+}
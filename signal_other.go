@@ -0,0 +1,10 @@
+//go:build !unix
+
+package env
+
+import "os"
+
+// defaultReloadSignals is empty where SIGHUP is not delivered, so ReloadOnSignal needs explicit signals.
+func defaultReloadSignals() []os.Signal {
+	return nil
+}
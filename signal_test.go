@@ -0,0 +1,165 @@
+//go:build unix
+
+package env
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// waitUntil polls a condition with a hard deadline.
+func waitUntil(t *testing.T, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met before deadline")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// TestReloadOnSignalReloadsAndRecordsSuccess ensures a signal refreshes the environment and status.
+func TestReloadOnSignalReloadsAndRecordsSuccess(t *testing.T) {
+	prepareLoaderTest(t, "ENV_QPASS_SIGNAL")
+	t.Setenv("APP_ENV", Local)
+	directory := t.TempDir()
+	writeEnvFile(t, directory, fileEnv, "ENV_QPASS_SIGNAL=first\n")
+	changeWorkingDirectory(t, directory)
+	if err := Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	stub := stubSignals(t)
+	reloadedAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	signalReloadClock = func() time.Time { return reloadedAt }
+
+	ctx, cancel := context.WithCancel(context.Background())
+	reloader := ReloadOnSignal(ctx)
+	if !reflect.DeepEqual(stub.requested, []os.Signal{syscall.SIGHUP}) {
+		t.Fatalf("expected SIGHUP by default, got %v", stub.requested)
+	}
+
+	writeEnvFile(t, directory, fileEnv, "ENV_QPASS_SIGNAL=second\n")
+	stub.channel <- syscall.SIGHUP
+	waitUntil(t, func() bool { return !reloader.LastReload().IsZero() })
+	if got := os.Getenv("ENV_QPASS_SIGNAL"); got != "second" {
+		t.Fatalf("expected signal to reload, got %q", got)
+	}
+	if !reloader.LastReload().Equal(reloadedAt) || reloader.LastError() != nil {
+		t.Fatalf("unexpected status time=%v err=%v", reloader.LastReload(), reloader.LastError())
+	}
+
+	cancel()
+	<-reloader.Done()
+	select {
+	case <-stub.stopped:
+	default:
+		t.Fatal("expected signal delivery to stop after cancellation")
+	}
+}
+
+// TestReloadOnSignalSurvivesFailures ensures failed reloads are logged and recorded without stopping.
+func TestReloadOnSignalSurvivesFailures(t *testing.T) {
+	prepareLoaderTest(t, "ENV_QPASS_SIGNAL")
+	t.Setenv("APP_ENV", Local)
+	directory := t.TempDir()
+	writeEnvFile(t, directory, fileEnv, "ENV_QPASS_SIGNAL=good\n")
+	changeWorkingDirectory(t, directory)
+	if err := Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	stub := stubSignals(t)
+	var logged bytes.Buffer
+	signalLogWriter = &logged
+
+	ctx, cancel := context.WithCancel(context.Background())
+	reloader := ReloadOnSignal(ctx, syscall.SIGTERM)
+	t.Cleanup(func() {
+		cancel()
+		<-reloader.Done()
+	})
+
+	writeEnvFile(t, directory, fileEnv, "ENV_QPASS_SIGNAL='secret-value\n")
+	stub.channel <- syscall.SIGTERM
+	waitUntil(t, func() bool { return reloader.LastError() != nil })
+	if got := os.Getenv("ENV_QPASS_SIGNAL"); got != "good" {
+		t.Fatalf("expected failed reload to keep previous value, got %q", got)
+	}
+	if !reloader.LastReload().IsZero() {
+		t.Fatal("expected no successful reload time after failure")
+	}
+	if output := logged.String(); !strings.Contains(output, "env reload failed") {
+		t.Fatalf("expected logged failure, got %q", output)
+	}
+
+	writeEnvFile(t, directory, fileEnv, "ENV_QPASS_SIGNAL=fixed\n")
+	stub.channel <- syscall.SIGTERM
+	waitUntil(t, func() bool { return reloader.LastError() == nil })
+	if os.Getenv("ENV_QPASS_SIGNAL") != "fixed" || reloader.LastReload().IsZero() {
+		t.Fatal("expected later signal to recover")
+	}
+}
+
+// TestReloadOnSignalRecordsLoaderErrors ensures injected loader failures surface through LastError.
+func TestReloadOnSignalRecordsLoaderErrors(t *testing.T) {
+	prepareLoaderTest(t)
+	stub := stubSignals(t)
+	signalLogWriter = &bytes.Buffer{}
+	workingDirectoryErr := errors.New("injected working directory failure")
+	envFileGetwd = func() (string, error) {
+		return "", workingDirectoryErr
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	reloader := ReloadOnSignal(ctx)
+	t.Cleanup(func() {
+		cancel()
+		<-reloader.Done()
+	})
+	stub.channel <- syscall.SIGHUP
+	waitUntil(t, func() bool { return reloader.LastError() != nil })
+	if !errors.Is(reloader.LastError(), workingDirectoryErr) {
+		t.Fatalf("expected loader error, got %v", reloader.LastError())
+	}
+}
+
+// signalStub captures the channel ReloadOnSignal registers so tests deliver signals deterministically.
+type signalStub struct {
+	channel   chan<- os.Signal
+	requested []os.Signal
+	stopped   chan struct{}
+}
+
+// stubSignals replaces signal registration for one test.
+func stubSignals(t *testing.T) *signalStub {
+	t.Helper()
+	originalNotify := signalNotify
+	originalStop := signalStop
+	originalWriter := signalLogWriter
+	originalClock := signalReloadClock
+	t.Cleanup(func() {
+		signalNotify = originalNotify
+		signalStop = originalStop
+		signalLogWriter = originalWriter
+		signalReloadClock = originalClock
+	})
+
+	stub := &signalStub{stopped: make(chan struct{})}
+	signalNotify = func(c chan<- os.Signal, signals ...os.Signal) {
+		stub.channel = c
+		stub.requested = append(stub.requested, signals...)
+	}
+	signalStop = func(chan<- os.Signal) {
+		close(stub.stopped)
+	}
+	return stub
+}
@@ -0,0 +1,13 @@
+//go:build unix
+
+package env
+
+import (
+	"os"
+	"syscall"
+)
+
+// defaultReloadSignals are the signals ReloadOnSignal listens for when none are given.
+func defaultReloadSignals() []os.Signal {
+	return []os.Signal{syscall.SIGHUP}
+}
@@ -0,0 +1,191 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// ProcessSnapshot records process variables, including whether each was set, so they can be restored exactly.
+type ProcessSnapshot struct {
+	values map[string]environmentSnapshot
+	all    bool
+}
+
+// Snapshot captures the named process variables, or the whole process environment when no keys are given.
+// @group Process environment
+// @behavior readonly
+//
+// A named key that is unset is captured as unset, so Restore removes it again. A snapshot of the
+// whole environment also removes variables that were added after it was taken. Snapshot
+// synchronizes with the loader, so it never observes a partially applied Load.
+//
+// Example: restore after a temporary change
+//
+//	_ = os.Setenv("FEATURE_FLAG", "off")
+//	snapshot := env.Snapshot("FEATURE_FLAG")
+//	_ = os.Setenv("FEATURE_FLAG", "on")
+//	_ = snapshot.Restore()
+//	env.Dump(os.Getenv("FEATURE_FLAG"))
+//	// #string "off"
+func Snapshot(keys ...string) ProcessSnapshot {
+	processEnvironmentLoader.mu.Lock()
+	defer processEnvironmentLoader.mu.Unlock()
+
+	if len(keys) == 0 {
+		return ProcessSnapshot{values: snapshotProcessEnvironment(), all: true}
+	}
+	values := make(map[string]environmentSnapshot, len(keys))
+	for _, key := range keys {
+		value, present := envLookup(key)
+		values[key] = environmentSnapshot{value: value, present: present}
+	}
+	return ProcessSnapshot{values: values}
+}
+
+// Restore transactionally puts every captured variable back, including unset versus empty.
+// @group Process environment
+// @behavior mutates-process-env
+//
+// Variables that already match are not rewritten. If any write fails, every earlier write is
+// rolled back and the error is returned. Restore synchronizes with the loader, and file ownership
+// follows the restored values just as it does for direct process changes.
+func (s ProcessSnapshot) Restore() error {
+	return updateProcessEnvironment(func() (ChangeSet, error) {
+		targets := make(map[string]environmentSnapshot, len(s.values))
+		for key, snapshot := range s.values {
+			targets[key] = snapshot
+		}
+		if s.all {
+			for _, entry := range os.Environ() {
+				key, _, _ := strings.Cut(entry, "=")
+				if _, captured := targets[key]; !captured {
+					targets[key] = environmentSnapshot{}
+				}
+			}
+		}
+
+		changes, err := writeEnvironmentTargets(targets)
+		if err != nil {
+			return nil, fmt.Errorf("restore env snapshot: %w", err)
+		}
+		return changes, nil
+	})
+}
+
+// Lookup returns the captured value for key and whether it was set.
+// @group Process environment
+// @behavior readonly
+func (s ProcessSnapshot) Lookup(key string) (string, bool) {
+	snapshot := s.values[key]
+	return snapshot.value, snapshot.present
+}
+
+// Keys returns the captured variable names in lexical order.
+// @group Process environment
+// @behavior readonly
+func (s ProcessSnapshot) Keys() []string {
+	keys := make([]string, 0, len(s.values))
+	for key := range s.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Diff returns, in lexical order, the keys whose value or presence differs between two snapshots.
+// @group Process environment
+// @behavior readonly
+//
+// A key captured by only one snapshot is compared against unset.
+//
+// Example: find variables changed by a step
+//
+//	_ = os.Setenv("CACHE_TTL", "60")
+//	before := env.Snapshot("CACHE_TTL", "CACHE_DRIVER")
+//	_ = os.Setenv("CACHE_TTL", "120")
+//	after := env.Snapshot("CACHE_TTL", "CACHE_DRIVER")
+//	env.Dump(before.Diff(after))
+//	// #[]string [
+//	//  0 => "CACHE_TTL" #string
+//	// ]
+func (s ProcessSnapshot) Diff(other ProcessSnapshot) []string {
+	keys := map[string]struct{}{}
+	for key := range s.values {
+		keys[key] = struct{}{}
+	}
+	for key := range other.values {
+		keys[key] = struct{}{}
+	}
+
+	diff := []string{}
+	for key := range keys {
+		if !snapshotsEqual(s.values[key], other.values[key]) {
+			diff = append(diff, key)
+		}
+	}
+	sort.Strings(diff)
+	return diff
+}
+
+// updateProcessEnvironment runs update under the loader lock and notifies subscribers after it succeeds.
+func updateProcessEnvironment(update func() (ChangeSet, error)) error {
+	processEnvironmentLoader.mu.Lock()
+	changes, err := update()
+	if err == nil {
+		environmentChanges.enqueue(changes)
+	}
+	processEnvironmentLoader.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	environmentChanges.deliver()
+	return nil
+}
+
+// writeEnvironmentTargets applies targets in key order and rolls every write back when one fails.
+func writeEnvironmentTargets(targets map[string]environmentSnapshot) (ChangeSet, error) {
+	keys := make([]string, 0, len(targets))
+	for key := range targets {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	before := make(map[string]environmentSnapshot, len(keys))
+	for _, key := range keys {
+		value, present := envLookup(key)
+		before[key] = environmentSnapshot{value: value, present: present}
+	}
+
+	changes := ChangeSet{}
+	for _, key := range keys {
+		target := targets[key]
+		if snapshotsEqual(before[key], target) {
+			continue
+		}
+		if err := writeEnvironmentSnapshot(key, target); err != nil {
+			rollbackErr := restoreEnvironmentSnapshots(keys, before)
+			applyErr := fmt.Errorf("apply env value %s: %w", key, err)
+			if rollbackErr != nil {
+				return nil, errors.Join(applyErr, rollbackErr)
+			}
+			return nil, applyErr
+		}
+		changes = append(changes, newChange(key, snapshotChangeKind(before[key], target), before[key], target))
+	}
+	return changes, nil
+}
+
+// snapshotChangeKind classifies a direct write that is not tied to a loader baseline.
+func snapshotChangeKind(before, after environmentSnapshot) ChangeKind {
+	switch {
+	case !after.present:
+		return ChangeRemoved
+	case !before.present:
+		return ChangeAdded
+	default:
+		return ChangeModified
+	}
+}
@@ -0,0 +1,131 @@
+package env
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+)
+
+// TestSnapshotRestoresUnsetAndEmptyValues ensures Restore reproduces presence as well as values.
+func TestSnapshotRestoresUnsetAndEmptyValues(t *testing.T) {
+	prepareLoaderTest(t, "ENV_QPASS_SET", "ENV_QPASS_EMPTY", "ENV_QPASS_UNSET")
+	_ = os.Setenv("ENV_QPASS_SET", "value")
+	_ = os.Setenv("ENV_QPASS_EMPTY", "")
+	_ = os.Unsetenv("ENV_QPASS_UNSET")
+
+	snapshot := Snapshot("ENV_QPASS_SET", "ENV_QPASS_EMPTY", "ENV_QPASS_UNSET")
+	_ = os.Setenv("ENV_QPASS_SET", "changed")
+	_ = os.Unsetenv("ENV_QPASS_EMPTY")
+	_ = os.Setenv("ENV_QPASS_UNSET", "")
+
+	if err := snapshot.Restore(); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if value, present := os.LookupEnv("ENV_QPASS_SET"); !present || value != "value" {
+		t.Fatalf("expected set value restored, got %q present=%v", value, present)
+	}
+	if value, present := os.LookupEnv("ENV_QPASS_EMPTY"); !present || value != "" {
+		t.Fatalf("expected empty value restored, got %q present=%v", value, present)
+	}
+	if _, present := os.LookupEnv("ENV_QPASS_UNSET"); present {
+		t.Fatal("expected unset value to be removed again")
+	}
+	if got := snapshot.Keys(); !reflect.DeepEqual(got, []string{"ENV_QPASS_EMPTY", "ENV_QPASS_SET", "ENV_QPASS_UNSET"}) {
+		t.Fatalf("unexpected keys %v", got)
+	}
+}
+
+// TestSnapshotOfEverythingRemovesAddedKeys ensures a full snapshot unsets variables added after capture.
+func TestSnapshotOfEverythingRemovesAddedKeys(t *testing.T) {
+	prepareLoaderTest(t, "ENV_QPASS_LATE", "ENV_QPASS_KEPT")
+	_ = os.Unsetenv("ENV_QPASS_LATE")
+	_ = os.Setenv("ENV_QPASS_KEPT", "kept")
+
+	snapshot := Snapshot()
+	_ = os.Setenv("ENV_QPASS_LATE", "late")
+	_ = os.Unsetenv("ENV_QPASS_KEPT")
+
+	if err := snapshot.Restore(); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if _, present := os.LookupEnv("ENV_QPASS_LATE"); present {
+		t.Fatal("expected variable added after the snapshot to be removed")
+	}
+	if got := os.Getenv("ENV_QPASS_KEPT"); got != "kept" {
+		t.Fatalf("expected removed variable restored, got %q", got)
+	}
+}
+
+// TestSnapshotRestoreRollsBackOnFailure ensures a failed write leaves the environment unchanged.
+func TestSnapshotRestoreRollsBackOnFailure(t *testing.T) {
+	prepareLoaderTest(t, "ENV_QPASS_A", "ENV_QPASS_B")
+	_ = os.Setenv("ENV_QPASS_A", "old-a")
+	_ = os.Setenv("ENV_QPASS_B", "old-b")
+	snapshot := Snapshot("ENV_QPASS_A", "ENV_QPASS_B")
+	_ = os.Setenv("ENV_QPASS_A", "new-a")
+	_ = os.Setenv("ENV_QPASS_B", "new-b")
+
+	writeErr := errors.New("injected write failure")
+	envSet = func(key, value string) error {
+		if key == "ENV_QPASS_B" && value == "old-b" {
+			return writeErr
+		}
+		return os.Setenv(key, value)
+	}
+
+	if err := snapshot.Restore(); !errors.Is(err, writeErr) {
+		t.Fatalf("expected write error, got %v", err)
+	}
+	if os.Getenv("ENV_QPASS_A") != "new-a" || os.Getenv("ENV_QPASS_B") != "new-b" {
+		t.Fatalf("expected rollback, got A=%q B=%q", os.Getenv("ENV_QPASS_A"), os.Getenv("ENV_QPASS_B"))
+	}
+}
+
+// TestSnapshotRestoreNotifiesSubscribers ensures restored keys reach change subscribers.
+func TestSnapshotRestoreNotifiesSubscribers(t *testing.T) {
+	prepareLoaderTest(t, "ENV_QPASS_NOTIFY")
+	_ = os.Unsetenv("ENV_QPASS_NOTIFY")
+	snapshot := Snapshot("ENV_QPASS_NOTIFY")
+	_ = os.Setenv("ENV_QPASS_NOTIFY", "temporary")
+
+	var received []Change
+	cancel := OnChange("ENV_QPASS_NOTIFY", func(change Change) { received = append(received, change) })
+	defer cancel()
+
+	if err := snapshot.Restore(); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if len(received) != 1 || received[0].Kind != ChangeRemoved {
+		t.Fatalf("expected one removal, got %+v", received)
+	}
+	if err := snapshot.Restore(); err != nil || len(received) != 1 {
+		t.Fatalf("expected an already matching restore to stay quiet, got err=%v changes=%+v", err, received)
+	}
+}
+
+// TestSnapshotDiffComparesPresenceAndValues ensures Diff reports value, presence, and coverage differences.
+func TestSnapshotDiffComparesPresenceAndValues(t *testing.T) {
+	left := ProcessSnapshot{values: map[string]environmentSnapshot{
+		"SAME":      {value: "x", present: true},
+		"CHANGED":   {value: "a", present: true},
+		"EMPTIED":   {value: "", present: true},
+		"ONLY_LEFT": {value: "left", present: true},
+		"UNSET":     {},
+	}}
+	right := ProcessSnapshot{values: map[string]environmentSnapshot{
+		"SAME":    {value: "x", present: true},
+		"CHANGED": {value: "b", present: true},
+		"EMPTIED": {},
+	}}
+
+	if got := left.Diff(right); !reflect.DeepEqual(got, []string{"CHANGED", "EMPTIED", "ONLY_LEFT"}) {
+		t.Fatalf("unexpected diff %v", got)
+	}
+	if got := left.Diff(left); len(got) != 0 {
+		t.Fatalf("expected no diff against itself, got %v", got)
+	}
+	if value, present := left.Lookup("EMPTIED"); !present || value != "" {
+		t.Fatalf("expected empty lookup to be present, got %q present=%v", value, present)
+	}
+}
@@ -0,0 +1,332 @@
+package env
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// structuredEnvFormats maps each LayerOptions.Formats name to the extensions discovery appends to
+// every layer filename, such as .env.production.yaml.
+var structuredEnvFormats = map[string][]string{
+	"yaml": {".yaml", ".yml"},
+	"json": {".json"},
+	"toml": {".toml"},
+}
+
+// structuredEnvDecoders turn a file with a known extension into a tree of maps, slices, and
+// scalar strings.
+var structuredEnvDecoders = map[string]func([]byte) (any, error){
+	".yaml": decodeYAMLEnv,
+	".yml":  decodeYAMLEnv,
+	".json": decodeJSONEnv,
+	".toml": decodeTOMLEnv,
+}
+
+// structuredEnvNames lists the filenames searched for one layer: each configured format first, in
+// order, and then the dotenv file itself so it wins over its structured siblings.
+func structuredEnvNames(name string, formats []string) []string {
+	names := make([]string, 0, len(formats)+1)
+	for _, format := range formats {
+		for _, extension := range structuredEnvFormats[format] {
+			names = append(names, name+extension)
+		}
+	}
+	return append(names, name)
+}
+
+// parseStructuredEnvData decodes a YAML, JSON, or TOML document named path into entries sorted by key.
+//
+// Nested objects flatten into SCREAMING_SNAKE keys joined by underscores, arrays of scalars join
+// with commas for GetSlice, and values are literal: they are never interpolated.
+func parseStructuredEnvData(path string, data []byte, decode func([]byte) (any, error)) ([]envEntry, error) {
+	tree, err := decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("decode env file %s: %w", path, err)
+	}
+	object, ok := tree.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("decode env file %s: top level must be an object", path)
+	}
+
+	values := make(map[string]string)
+	origins := make(map[string]string)
+	if err := flattenStructuredEnv(object, "", "", values, origins); err != nil {
+		return nil, fmt.Errorf("decode env file %s: %w", path, err)
+	}
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	entries := make([]envEntry, 0, len(keys))
+	for _, key := range keys {
+		entries = append(entries, envEntry{key: key, value: values[key]})
+	}
+	return entries, nil
+}
+
+// flattenStructuredEnv adds every leaf of object under prefix; origin is the dotted source path
+// used in errors, and origins records it per key to report two paths that become the same key.
+func flattenStructuredEnv(object map[string]any, prefix, origin string, values, origins map[string]string) error {
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		key := structuredEnvKey(name)
+		if prefix != "" {
+			key = prefix + "_" + key
+		}
+		path := name
+		if origin != "" {
+			path = origin + "." + name
+		}
+
+		switch value := object[name].(type) {
+		case map[string]any:
+			if err := flattenStructuredEnv(value, key, path, values, origins); err != nil {
+				return err
+			}
+			continue
+		case []any:
+			joined, err := joinStructuredEnvArray(path, value)
+			if err != nil {
+				return err
+			}
+			values[key] = joined
+		case string:
+			values[key] = value
+		default:
+			return fmt.Errorf("%s has unsupported value %T", path, value)
+		}
+
+		if !isValidEnvKey(key) {
+			return fmt.Errorf("%s does not form a valid variable name", path)
+		}
+		if other, taken := origins[key]; taken {
+			return fmt.Errorf("%s and %s both become %s", other, path, key)
+		}
+		origins[key] = path
+	}
+	return nil
+}
+
+// structuredEnvKey uppercases name and turns every character other than a letter or digit into '_'.
+func structuredEnvKey(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+}
+
+// joinStructuredEnvArray joins scalar elements with commas; elements that GetSlice could not split
+// back out, such as nested values or strings holding a comma, are errors.
+func joinStructuredEnvArray(path string, elements []any) (string, error) {
+	parts := make([]string, 0, len(elements))
+	for index, element := range elements {
+		part, ok := element.(string)
+		if !ok {
+			return "", fmt.Errorf("%s[%d] must be a scalar", path, index)
+		}
+		if strings.Contains(part, ",") {
+			return "", fmt.Errorf("%s[%d] contains a comma", path, index)
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, ","), nil
+}
+
+// decodeJSONEnv decodes one JSON value, keeping numbers exactly as written.
+func decodeJSONEnv(data []byte) (any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var tree any
+	if err := decoder.Decode(&tree); err != nil {
+		return nil, err
+	}
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		return nil, errors.New("unexpected data after the top-level value")
+	}
+	return structuredEnvTree(tree), nil
+}
+
+// decodeTOMLEnv decodes a TOML document.
+func decodeTOMLEnv(data []byte) (any, error) {
+	var tree map[string]any
+	if _, err := toml.Decode(string(data), &tree); err != nil {
+		return nil, err
+	}
+	return structuredEnvTree(tree), nil
+}
+
+// structuredEnvTree converts decoded JSON and TOML values to maps, slices, and scalar strings.
+func structuredEnvTree(value any) any {
+	switch value := value.(type) {
+	case map[string]any:
+		tree := make(map[string]any, len(value))
+		for name, child := range value {
+			tree[name] = structuredEnvTree(child)
+		}
+		return tree
+	case []map[string]any:
+		tree := make([]any, len(value))
+		for index, child := range value {
+			tree[index] = structuredEnvTree(child)
+		}
+		return tree
+	case []any:
+		tree := make([]any, len(value))
+		for index, child := range value {
+			tree[index] = structuredEnvTree(child)
+		}
+		return tree
+	case nil:
+		return ""
+	case string:
+		return value
+	case bool:
+		return strconv.FormatBool(value)
+	case int64:
+		return strconv.FormatInt(value, 10)
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	case time.Time:
+		return structuredEnvTime(value)
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+// structuredEnvTime formats a TOML date or time in the form it was written; the decoder marks
+// local values with named zones.
+func structuredEnvTime(value time.Time) string {
+	switch value.Location().String() {
+	case "date-local":
+		return value.Format(time.DateOnly)
+	case "time-local":
+		return value.Format("15:04:05.999999999")
+	case "datetime-local":
+		return value.Format("2006-01-02T15:04:05.999999999")
+	default:
+		return value.Format(time.RFC3339Nano)
+	}
+}
+
+// maxYAMLAliasExpansions caps how many aliases one document may expand, so nested anchors cannot
+// multiply into a huge tree the way a billion laughs file does.
+const maxYAMLAliasExpansions = 10000
+
+// decodeYAMLEnv decodes the first YAML document, keeping scalars exactly as written.
+func decodeYAMLEnv(data []byte) (any, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+	if len(document.Content) == 0 {
+		return map[string]any{}, nil
+	}
+	expansion := yamlEnvExpansion{expanding: make(map[*yaml.Node]bool)}
+	return expansion.tree(document.Content[0])
+}
+
+// yamlEnvExpansion tracks the collections being converted, so an alias back into one of them is a
+// cycle, and counts alias expansions against maxYAMLAliasExpansions.
+type yamlEnvExpansion struct {
+	expanding map[*yaml.Node]bool
+	aliases   int
+}
+
+// tree converts a YAML node, resolving aliases and << merge keys. Explicit keys beat merged ones,
+// and an earlier mapping in a << list beats a later one, as the merge key spec requires.
+func (e *yamlEnvExpansion) tree(node *yaml.Node) (any, error) {
+	switch node.Kind {
+	case yaml.AliasNode:
+		e.aliases++
+		if e.aliases > maxYAMLAliasExpansions {
+			return nil, fmt.Errorf("line %d: more than %d alias expansions", node.Line, maxYAMLAliasExpansions)
+		}
+		if e.expanding[node.Alias] {
+			return nil, fmt.Errorf("line %d: alias *%s refers to a node that contains it", node.Line, node.Value)
+		}
+		return e.tree(node.Alias)
+	case yaml.ScalarNode:
+		if node.Tag == "!!null" {
+			return "", nil
+		}
+		return node.Value, nil
+	case yaml.SequenceNode:
+		e.expanding[node] = true
+		defer delete(e.expanding, node)
+		tree := make([]any, 0, len(node.Content))
+		for _, child := range node.Content {
+			value, err := e.tree(child)
+			if err != nil {
+				return nil, err
+			}
+			tree = append(tree, value)
+		}
+		return tree, nil
+	case yaml.MappingNode:
+		e.expanding[node] = true
+		defer delete(e.expanding, node)
+		tree := make(map[string]any, len(node.Content)/2)
+		for index := 0; index+1 < len(node.Content); index += 2 {
+			name, child := node.Content[index], node.Content[index+1]
+			value, err := e.tree(child)
+			if err != nil {
+				return nil, err
+			}
+			if name.Tag == "!!merge" {
+				merged := []any{value}
+				if sequence, ok := value.([]any); ok {
+					merged = sequence
+				}
+				// Keys already set, explicitly or by an earlier source in the list, win.
+				for _, source := range merged {
+					object, ok := source.(map[string]any)
+					if !ok {
+						return nil, fmt.Errorf("line %d: merge key needs a mapping", name.Line)
+					}
+					for key, mergedValue := range object {
+						if _, set := tree[key]; !set {
+							tree[key] = mergedValue
+						}
+					}
+				}
+				continue
+			}
+			if name.Kind != yaml.ScalarNode {
+				return nil, fmt.Errorf("line %d: mapping keys must be scalars", name.Line)
+			}
+			tree[name.Value] = value
+		}
+		return tree, nil
+	default:
+		return nil, fmt.Errorf("line %d: unsupported YAML node", node.Line)
+	}
+}
+
+// structuredEnvDecoder returns the decoder for path's extension, if it names a structured format.
+func structuredEnvDecoder(path string) (func([]byte) (any, error), bool) {
+	decode, ok := structuredEnvDecoders[strings.ToLower(filepath.Ext(path))]
+	return decode, ok
+}
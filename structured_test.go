@@ -0,0 +1,179 @@
+package env
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestParseStructuredEnvDataFlattensFormats ensures YAML, JSON, and TOML flatten to the same SCREAMING_SNAKE values.
+func TestParseStructuredEnvDataFlattensFormats(t *testing.T) {
+	want := map[string]string{
+		"STORAGE_PUBLIC_ROOT": "storage/app/public",
+		"HOSTS":               "a.example,b.example",
+		"PORT":                "8080",
+		"RATIO":               "0.5",
+		"DEBUG":               "true",
+		"EMPTY":               "",
+		"LOG_LEVEL":           "info",
+		"DEFAULTS_ROOT":       "storage/app/public",
+	}
+	documents := map[string]string{
+		"config.yaml": "defaults: &defaults\n  root: storage/app/public\nstorage:\n  public:\n    <<: *defaults\nhosts: [a.example, b.example]\nport: 8080\nratio: 0.5\ndebug: true\nempty: ~\nlog-level: info\n",
+		"config.json": `{"storage": {"public": {"root": "storage/app/public"}}, "hosts": ["a.example", "b.example"], "port": 8080, "ratio": 0.5, "debug": true, "empty": null, "log-level": "info", "defaults": {"root": "storage/app/public"}}`,
+		"config.toml": "hosts = [\"a.example\", \"b.example\"]\nport = 8080\nratio = 0.5\ndebug = true\nempty = \"\"\nlog-level = \"info\"\n\n[defaults]\nroot = \"storage/app/public\"\n\n[storage.public]\nroot = \"storage/app/public\"\n",
+	}
+	for name, document := range documents {
+		t.Run(name, func(t *testing.T) {
+			entries, err := parseEnvFileData(name, []byte(document))
+			if err != nil {
+				t.Fatalf("parse %s: %v", name, err)
+			}
+			got := make(map[string]string, len(entries))
+			for _, entry := range entries {
+				if entry.expand {
+					t.Fatalf("expected %s to be literal", entry.key)
+				}
+				got[entry.key] = entry.value
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("expected %v, got %v", want, got)
+			}
+		})
+	}
+}
+
+// TestParseStructuredEnvDataRejectsAmbiguousValues ensures values that cannot round-trip through env keys or GetSlice fail.
+func TestParseStructuredEnvDataRejectsAmbiguousValues(t *testing.T) {
+	tests := []struct {
+		name     string
+		document string
+		message  string
+	}{
+		{name: "collision.json", document: `{"db_host": "a", "db": {"host": "b"}}`, message: "db.host and db_host both become DB_HOST"},
+		{name: "nested.yaml", document: "hosts:\n  - name: a\n", message: "hosts[0] must be a scalar"},
+		{name: "comma.toml", document: "tags = [\"a,b\"]\n", message: "tags[0] contains a comma"},
+		{name: "array.json", document: `["a"]`, message: "top level must be an object"},
+		{name: "key.yaml", document: "9lives: 1\n", message: "9lives does not form a valid variable name"},
+		{name: "trailing.json", document: `{"a": "1"} {}`, message: "unexpected data"},
+		{name: "syntax.toml", document: "a = \n", message: "decode env file syntax.toml"},
+		{name: "syntax.yaml", document: "a: [\n", message: "decode env file syntax.yaml"},
+		{name: "syntax.json", document: `{"a": }`, message: "decode env file syntax.json"},
+		{name: "merge.yaml", document: "a:\n  <<: plain\n", message: "merge key needs a mapping"},
+		{name: "mapkey.yaml", document: "? [a, b]\n: 1\n", message: "mapping keys must be scalars"},
+		{name: "tables.toml", document: "[[servers]]\nname = \"a\"\n", message: "servers[0] must be a scalar"},
+		{name: "cycle.yaml", document: "a: &x\n  b: *x\n", message: "decode env file cycle.yaml: line 2: alias *x refers to a node that contains it"},
+		{name: "mergecycle.yaml", document: "a: &x\n  <<: *x\n", message: "alias *x refers to a node that contains it"},
+		{name: "laughs.yaml", document: "a: &a [x, x, x, x, x, x, x, x, x, x]\nb: &b [*a, *a, *a, *a, *a, *a, *a, *a, *a, *a]\nc: &c [*b, *b, *b, *b, *b, *b, *b, *b, *b, *b]\nd: &d [*c, *c, *c, *c, *c, *c, *c, *c, *c, *c]\ne: &e [*d, *d, *d, *d, *d, *d, *d, *d, *d, *d]\n", message: "more than 10000 alias expansions"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := parseEnvFileData(test.name, []byte(test.document))
+			if err == nil || !strings.Contains(err.Error(), test.message) {
+				t.Fatalf("expected an error containing %q, got %v", test.message, err)
+			}
+		})
+	}
+}
+
+// TestParseStructuredEnvDataConvertsScalars ensures YAML aliases and merges, empty documents, and TOML dates decode predictably.
+func TestParseStructuredEnvDataConvertsScalars(t *testing.T) {
+	tests := []struct {
+		name     string
+		document string
+		want     map[string]string
+	}{
+		{name: "empty.yaml", document: "", want: map[string]string{}},
+		{
+			name:     "aliases.yml",
+			document: "base: &base\n  host: db\nextra: &extra\n  host: other\n  port: 5432\nprimary:\n  <<: [*base, *extra]\n  host: primary\nsecondary:\n  <<: [*base, *extra]\nlocal:\n  host: localhost\n  <<: *extra\nreplica: *base\n",
+			want: map[string]string{
+				"BASE_HOST": "db", "EXTRA_HOST": "other", "EXTRA_PORT": "5432", "PRIMARY_HOST": "primary", "PRIMARY_PORT": "5432",
+				"SECONDARY_HOST": "db", "SECONDARY_PORT": "5432", "LOCAL_HOST": "localhost", "LOCAL_PORT": "5432", "REPLICA_HOST": "db",
+			},
+		},
+		{
+			name:     "dates.toml",
+			document: "released = 2024-05-01T10:00:00Z\nday = 2024-05-01\nopens = 07:30:00\nstarts = 2024-05-01T07:30:00.5\nhuge = 1e21\n",
+			want: map[string]string{
+				"RELEASED": "2024-05-01T10:00:00Z", "DAY": "2024-05-01", "OPENS": "07:30:00", "STARTS": "2024-05-01T07:30:00.5", "HUGE": "1000000000000000000000",
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			entries, err := parseEnvFileData(test.name, []byte(test.document))
+			if err != nil {
+				t.Fatalf("parse %s: %v", test.name, err)
+			}
+			got := make(map[string]string, len(entries))
+			for _, entry := range entries {
+				got[entry.key] = entry.value
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Fatalf("expected %v, got %v", test.want, got)
+			}
+		})
+	}
+}
+
+// TestLoadDiscoversStructuredFormats ensures configured formats layer per APP_ENV just below their dotenv file.
+func TestLoadDiscoversStructuredFormats(t *testing.T) {
+	prepareLoaderTest(t)
+	directory := t.TempDir()
+	writeEnvFile(t, directory, ".env.yaml", "app_env: production\nstorage:\n  public:\n    root: base\n    url: /storage\ncache:\n  ttl: 60\n")
+	writeEnvFile(t, directory, fileEnv, "CACHE_TTL=30\n")
+	writeEnvFile(t, directory, ".env.production.toml", "[storage.public]\nroot = \"/srv/public\"\n")
+	writeEnvFile(t, directory, ".env.production.json", `{"cache": {"ttl": 90}}`)
+	changeWorkingDirectory(t, directory)
+	if err := SetLayerOptions(LayerOptions{Formats: []string{"yaml", "toml"}}); err != nil {
+		t.Fatalf("SetLayerOptions: %v", err)
+	}
+
+	environment, err := LoadInto(directory, LoadIntoOptions{})
+	if err != nil {
+		t.Fatalf("LoadInto: %v", err)
+	}
+	public := environment.WithPrefix("STORAGE").Child("PUBLIC")
+	if environment.AppEnv() != Production || public.Get("ROOT", "") != "/srv/public" || public.Get("URL", "") != "/storage" {
+		t.Fatalf("unexpected app env %q root %q url %q", environment.AppEnv(), public.Get("ROOT", ""), public.Get("URL", ""))
+	}
+	if got := environment.GetInt("CACHE_TTL", "0"); got != 30 {
+		t.Fatalf("expected .env to beat .env.yaml and unconfigured JSON to be ignored, got %d", got)
+	}
+	want := []string{
+		filepath.Join(directory, ".env.yaml"),
+		filepath.Join(directory, fileEnv),
+		filepath.Join(directory, ".env.production.toml"),
+	}
+	if got := environment.Files(); !reflect.DeepEqual(got[:len(want)], want) {
+		t.Fatalf("expected files %v, got %v", want, got)
+	}
+
+	if err := SetLayerOptions(LayerOptions{Formats: []string{"ini"}}); err == nil {
+		t.Fatal("expected an unknown format to be rejected")
+	}
+}
+
+// TestLoadFilesDecodesStructuredExtensions ensures explicit files use the format their extension names.
+func TestLoadFilesDecodesStructuredExtensions(t *testing.T) {
+	prepareLoaderTest(t, "ENV_QPASS_DB_HOST", "ENV_QPASS_DB_PORTS")
+	t.Setenv("APP_ENV", Local)
+	_ = os.Unsetenv("ENV_QPASS_DB_HOST")
+	_ = os.Unsetenv("ENV_QPASS_DB_PORTS")
+	directory := t.TempDir()
+	writeEnvFile(t, directory, "config.json", `{"env_qpass": {"db": {"host": "db.internal", "ports": [5432, 5433]}}}`)
+	changeWorkingDirectory(t, directory)
+
+	if err := LoadFiles("config.json"); err != nil {
+		t.Fatalf("LoadFiles: %v", err)
+	}
+	if got := os.Getenv("ENV_QPASS_DB_HOST"); got != "db.internal" {
+		t.Fatalf("expected the nested JSON value, got %q", got)
+	}
+	if got := GetSlice("ENV_QPASS_DB_PORTS", ""); !reflect.DeepEqual(got, []string{"5432", "5433"}) {
+		t.Fatalf("expected a GetSlice-compatible array, got %v", got)
+	}
+}
@@ -0,0 +1,198 @@
+package env
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"os"
+	"time"
+)
+
+const (
+	defaultWatchInterval = time.Second
+	defaultWatchDebounce = 250 * time.Millisecond
+)
+
+// watchReadFile is a shim that tests override to observe content hashing.
+var watchReadFile = os.ReadFile
+
+// WatchOptions configures polling and debouncing for Watch.
+type WatchOptions struct {
+	// Interval is the polling period. Zero selects one second.
+	Interval time.Duration
+	// Debounce is the quiet period required after the last detected edit. Zero selects 250ms.
+	Debounce time.Duration
+}
+
+// WatchEvent reports the result of one automatic reload.
+type WatchEvent struct {
+	Changes ChangeSet
+	Err     error
+}
+
+// envFileFingerprint captures enough file identity to detect edits, replacements, creation, and removal.
+type envFileFingerprint struct {
+	info   os.FileInfo
+	exists bool
+	err    string
+	size   int64
+	mod    time.Time
+	digest [sha256.Size]byte
+}
+
+// Watch polls every env file the loader discovered, plus files that could appear earlier in any
+// layer's search path, and reloads after edits settle.
+// @group Environment loading
+// @behavior mutates-process-env
+//
+// Watch runs Load first and returns its error. Polling compares modification times, sizes, file
+// identity, and content hashes, so it needs no platform notification APIs and detects atomic
+// replacements such as Kubernetes ConfigMap updates. Bursts of edits are debounced into one
+// ReloadWithChanges call whose result is sent on the returned channel. Reload failures are reported
+// as events and leave the previous configuration active. The channel closes when ctx is done.
+//
+// Example: reload after an edit
+//
+//	tmp, _ := os.MkdirTemp("", "envdoc")
+//	defer os.RemoveAll(tmp)
+//	originalDirectory, _ := os.Getwd()
+//	defer os.Chdir(originalDirectory)
+//	_ = os.Chdir(tmp)
+//	_ = os.WriteFile(filepath.Join(tmp, ".env"), []byte("SERVICE=api"), 0o644)
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+//	defer cancel()
+//	events, _ := env.Watch(ctx, env.WatchOptions{Interval: 20 * time.Millisecond})
+//	_ = os.WriteFile(filepath.Join(tmp, ".env"), []byte("SERVICE=worker"), 0o644)
+//	event := <-events
+//	env.Dump(event.Changes.Keys(), os.Getenv("SERVICE"))
+//	// #[]string [
+//	//  0 => "SERVICE" #string
+//	// ]
+//	// #string "worker"
+func Watch(ctx context.Context, options WatchOptions) (<-chan WatchEvent, error) {
+	if options.Interval <= 0 {
+		options.Interval = defaultWatchInterval
+	}
+	if options.Debounce <= 0 {
+		options.Debounce = defaultWatchDebounce
+	}
+	if err := Load(); err != nil {
+		return nil, err
+	}
+
+	events := make(chan WatchEvent)
+	last := fingerprintEnvFiles(watchedEnvFiles())
+	go watchEnvFiles(ctx, options, last, events)
+	return events, nil
+}
+
+// watchEnvFiles owns the polling loop so cancellation closes the channel exactly once.
+func watchEnvFiles(
+	ctx context.Context,
+	options WatchOptions,
+	last map[string]envFileFingerprint,
+	events chan<- WatchEvent,
+) {
+	defer close(events)
+	ticker := time.NewTicker(options.Interval)
+	defer ticker.Stop()
+	debounce := time.NewTimer(options.Debounce)
+	debounce.Stop()
+	defer debounce.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			current := fingerprintEnvFiles(watchedEnvFiles())
+			if envFileFingerprintsEqual(last, current) {
+				continue
+			}
+			last = current
+			debounce.Reset(options.Debounce)
+		case <-debounce.C:
+			changes, err := ReloadWithChanges()
+			// A reload may select different layers, so the watch set is refreshed from the loader.
+			last = fingerprintEnvFiles(watchedEnvFiles())
+			select {
+			case events <- WatchEvent{Changes: changes, Err: err}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// watchedEnvFiles copies the candidate paths recorded by the last successful load.
+func watchedEnvFiles() []string {
+	processEnvironmentLoader.mu.Lock()
+	defer processEnvironmentLoader.mu.Unlock()
+	return append([]string(nil), processEnvironmentLoader.watched...)
+}
+
+// fingerprintEnvFiles records the current state of every watched path.
+func fingerprintEnvFiles(paths []string) map[string]envFileFingerprint {
+	fingerprints := make(map[string]envFileFingerprint, len(paths))
+	for _, path := range paths {
+		fingerprints[path] = fingerprintEnvFile(path)
+	}
+	return fingerprints
+}
+
+// fingerprintEnvFile hashes regular files and records other stat failures so they trigger a reload.
+func fingerprintEnvFile(path string) envFileFingerprint {
+	info, err := envFileStat(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return envFileFingerprint{}
+	case err != nil:
+		return envFileFingerprint{err: err.Error()}
+	}
+
+	fingerprint := envFileFingerprint{
+		info:   info,
+		exists: true,
+		size:   info.Size(),
+		mod:    info.ModTime(),
+	}
+	if !info.Mode().IsRegular() {
+		return fingerprint
+	}
+	contents, err := watchReadFile(path)
+	if err != nil {
+		fingerprint.err = err.Error()
+		return fingerprint
+	}
+	fingerprint.digest = sha256.Sum256(contents)
+	return fingerprint
+}
+
+// envFileFingerprintsEqual reports whether two polls observed the same watch set and file states.
+func envFileFingerprintsEqual(left, right map[string]envFileFingerprint) bool {
+	if len(left) != len(right) {
+		return false
+	}
+	for path, before := range left {
+		after, ok := right[path]
+		if !ok || !envFileFingerprintEqual(before, after) {
+			return false
+		}
+	}
+	return true
+}
+
+// envFileFingerprintEqual compares identity as well as content so atomic replacements are noticed.
+func envFileFingerprintEqual(left, right envFileFingerprint) bool {
+	if left.exists != right.exists || left.err != right.err {
+		return false
+	}
+	if !left.exists {
+		return true
+	}
+	return left.size == right.size &&
+		left.mod.Equal(right.mod) &&
+		left.digest == right.digest &&
+		os.SameFile(left.info, right.info)
+}
@@ -0,0 +1,227 @@
+package env
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// startTestWatch runs Watch with fast polling and stops it before loader state is restored.
+func startTestWatch(t *testing.T) <-chan WatchEvent {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	events, err := Watch(ctx, WatchOptions{Interval: 5 * time.Millisecond, Debounce: 20 * time.Millisecond})
+	if err != nil {
+		cancel()
+		t.Fatalf("Watch: %v", err)
+	}
+	t.Cleanup(func() {
+		cancel()
+		for range events {
+		}
+	})
+	return events
+}
+
+// receiveWatchEvent bounds waiting so a missed edit fails instead of hanging the suite.
+func receiveWatchEvent(t *testing.T, events <-chan WatchEvent) WatchEvent {
+	t.Helper()
+	select {
+	case event, ok := <-events:
+		if !ok {
+			t.Fatal("watch channel closed unexpectedly")
+		}
+		return event
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for watch event")
+	}
+	return WatchEvent{}
+}
+
+// TestWatchReloadsAfterEdit ensures an edited file is reloaded and its change set reported.
+func TestWatchReloadsAfterEdit(t *testing.T) {
+	prepareLoaderTest(t, "ENV_QPASS_WATCH")
+	t.Setenv("APP_ENV", Local)
+	_ = os.Unsetenv("ENV_QPASS_WATCH")
+	directory := t.TempDir()
+	writeEnvFile(t, directory, fileEnv, "ENV_QPASS_WATCH=first\n")
+	changeWorkingDirectory(t, directory)
+	events := startTestWatch(t)
+
+	if got := os.Getenv("ENV_QPASS_WATCH"); got != "first" {
+		t.Fatalf("expected Watch to load before polling, got %q", got)
+	}
+	writeEnvFile(t, directory, fileEnv, "ENV_QPASS_WATCH=second\n")
+	event := receiveWatchEvent(t, events)
+	if event.Err != nil {
+		t.Fatalf("unexpected reload error: %v", event.Err)
+	}
+	if got := event.Changes.Keys(ChangeModified); !reflect.DeepEqual(got, []string{"ENV_QPASS_WATCH"}) {
+		t.Fatalf("expected modified key, got %v", got)
+	}
+	if got := os.Getenv("ENV_QPASS_WATCH"); got != "second" {
+		t.Fatalf("expected reloaded value, got %q", got)
+	}
+}
+
+// TestWatchDetectsFilesAppearingInSearchedDirectories ensures newly created layers are picked up.
+func TestWatchDetectsFilesAppearingInSearchedDirectories(t *testing.T) {
+	prepareLoaderTest(t, "ENV_QPASS_APPEARED")
+	t.Setenv("APP_ENV", Local)
+	_ = os.Unsetenv("ENV_QPASS_APPEARED")
+	directory := t.TempDir()
+	changeWorkingDirectory(t, directory)
+	events := startTestWatch(t)
+
+	writeEnvFile(t, directory, envFileLocal, "ENV_QPASS_APPEARED=yes\n")
+	event := receiveWatchEvent(t, events)
+	if got := event.Changes.Keys(ChangeAdded); !reflect.DeepEqual(got, []string{"ENV_QPASS_APPEARED"}) {
+		t.Fatalf("expected added key, got %v (err %v)", got, event.Err)
+	}
+}
+
+// TestWatchReportsReloadErrors ensures failures are delivered without stopping the watcher.
+func TestWatchReportsReloadErrors(t *testing.T) {
+	prepareLoaderTest(t, "ENV_QPASS_WATCH")
+	t.Setenv("APP_ENV", Local)
+	directory := t.TempDir()
+	writeEnvFile(t, directory, fileEnv, "ENV_QPASS_WATCH=good\n")
+	changeWorkingDirectory(t, directory)
+	events := startTestWatch(t)
+
+	writeEnvFile(t, directory, fileEnv, "ENV_QPASS_WATCH='broken\n")
+	event := receiveWatchEvent(t, events)
+	if event.Err == nil {
+		t.Fatal("expected reload error event")
+	}
+	if got := os.Getenv("ENV_QPASS_WATCH"); got != "good" {
+		t.Fatalf("expected failed reload to keep previous value, got %q", got)
+	}
+
+	writeEnvFile(t, directory, fileEnv, "ENV_QPASS_WATCH=fixed\n")
+	event = receiveWatchEvent(t, events)
+	if event.Err != nil || os.Getenv("ENV_QPASS_WATCH") != "fixed" {
+		t.Fatalf("expected watcher to recover, got err=%v value=%q", event.Err, os.Getenv("ENV_QPASS_WATCH"))
+	}
+}
+
+// TestWatchDebouncesBursts ensures rapid successive edits produce a single reload.
+func TestWatchDebouncesBursts(t *testing.T) {
+	prepareLoaderTest(t, "ENV_QPASS_BURST")
+	t.Setenv("APP_ENV", Local)
+	directory := t.TempDir()
+	writeEnvFile(t, directory, fileEnv, "ENV_QPASS_BURST=0\n")
+	changeWorkingDirectory(t, directory)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events, err := Watch(ctx, WatchOptions{Interval: 2 * time.Millisecond, Debounce: 150 * time.Millisecond})
+	if err != nil {
+		cancel()
+		t.Fatalf("Watch: %v", err)
+	}
+	t.Cleanup(func() {
+		cancel()
+		for range events {
+		}
+	})
+
+	for index := 1; index <= 5; index++ {
+		writeEnvFile(t, directory, fileEnv, "ENV_QPASS_BURST="+string(rune('0'+index))+"\n")
+		time.Sleep(10 * time.Millisecond)
+	}
+	event := receiveWatchEvent(t, events)
+	if event.Err != nil || os.Getenv("ENV_QPASS_BURST") != "5" {
+		t.Fatalf("expected one reload of the final edit, got err=%v value=%q", event.Err, os.Getenv("ENV_QPASS_BURST"))
+	}
+	select {
+	case extra := <-events:
+		t.Fatalf("expected burst to be debounced, got extra event %+v", extra)
+	case <-time.After(300 * time.Millisecond):
+	}
+}
+
+// TestWatchReturnsInitialLoadError ensures a watcher is not started from an invalid configuration.
+func TestWatchReturnsInitialLoadError(t *testing.T) {
+	prepareLoaderTest(t)
+	workingDirectoryErr := errors.New("injected working directory failure")
+	envFileGetwd = func() (string, error) {
+		return "", workingDirectoryErr
+	}
+
+	events, err := Watch(context.Background(), WatchOptions{})
+	if !errors.Is(err, workingDirectoryErr) || events != nil {
+		t.Fatalf("expected initial Load error, got events=%v err=%v", events, err)
+	}
+}
+
+// TestWatchClosesChannelOnCancel ensures cancellation releases the polling goroutine.
+func TestWatchClosesChannelOnCancel(t *testing.T) {
+	prepareLoaderTest(t)
+	t.Setenv("APP_ENV", Local)
+	changeWorkingDirectory(t, t.TempDir())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events, err := Watch(ctx, WatchOptions{Interval: time.Millisecond})
+	if err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+	cancel()
+	select {
+	case _, ok := <-events:
+		if ok {
+			t.Fatal("expected no events after cancellation")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("watch channel did not close after cancellation")
+	}
+}
+
+// TestEnvFileFingerprintDetectsReplacement ensures identical content in a replaced file is still noticed.
+func TestEnvFileFingerprintDetectsReplacement(t *testing.T) {
+	prepareLoaderTest(t)
+	directory := t.TempDir()
+	path := filepath.Join(directory, fileEnv)
+	writeEnvFile(t, directory, fileEnv, "A=1\n")
+	before := fingerprintEnvFile(path)
+
+	replacement := filepath.Join(directory, "replacement")
+	writeEnvFile(t, directory, "replacement", "A=1\n")
+	modTime := before.info.ModTime()
+	if err := os.Chtimes(replacement, modTime, modTime); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+	if err := os.Rename(replacement, path); err != nil {
+		t.Fatalf("rename: %v", err)
+	}
+	if envFileFingerprintEqual(before, fingerprintEnvFile(path)) {
+		t.Fatal("expected replaced file to change its fingerprint")
+	}
+
+	var reads atomic.Int32
+	watchReadFile = func(name string) ([]byte, error) {
+		reads.Add(1)
+		return os.ReadFile(name)
+	}
+	if missing := fingerprintEnvFile(filepath.Join(directory, "missing")); missing.exists || reads.Load() != 0 {
+		t.Fatalf("expected missing file to skip hashing, got %+v after %d reads", missing, reads.Load())
+	}
+}
+
+// TestEnvFileCandidatesStopAtDiscoveredFile ensures only paths that could shadow the current file are watched.
+func TestEnvFileCandidatesStopAtDiscoveredFile(t *testing.T) {
+	root := t.TempDir()
+	child := filepath.Join(root, "child")
+	found := filepath.Join(root, fileEnv)
+	want := []string{filepath.Join(child, fileEnv), found}
+	if got := envFileCandidates(child, fileEnv, found); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected candidates %v, got %v", want, got)
+	}
+	if got := envFileCandidates(child, fileEnv, ""); len(got) < 2 || got[0] != want[0] {
+		t.Fatalf("expected unbounded miss to include every searched directory, got %v", got)
+	}
+}